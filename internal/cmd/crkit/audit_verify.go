@@ -0,0 +1,24 @@
+package main
+
+import (
+	"github.com/innoai-tech/infra/pkg/cli"
+	"github.com/innoai-tech/infra/pkg/otel"
+
+	contentapi "github.com/octohelm/crkit/pkg/content/api"
+	"github.com/octohelm/crkit/pkg/registryhttp/audit"
+)
+
+func init() {
+	c := cli.AddTo(App, &AuditVerify{})
+	c.LogFormat = "text"
+}
+
+// AuditVerify 校验审计日志的哈希链是否完整
+type AuditVerify struct {
+	cli.C
+	otel.Otel
+
+	contentapi.NamespaceProvider
+
+	audit.Verifier
+}
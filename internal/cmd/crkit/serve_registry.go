@@ -6,9 +6,15 @@ import (
 	"github.com/innoai-tech/infra/pkg/otel"
 
 	contentapi "github.com/octohelm/crkit/pkg/content/api"
+	"github.com/octohelm/crkit/pkg/content/fs/cacheevictor"
 	"github.com/octohelm/crkit/pkg/content/fs/garbagecollector"
 	"github.com/octohelm/crkit/pkg/content/fs/uploadpurger"
+	"github.com/octohelm/crkit/pkg/content/mirror"
 	"github.com/octohelm/crkit/pkg/registryhttp"
+	"github.com/octohelm/crkit/pkg/registryhttp/accesspolicy"
+	"github.com/octohelm/crkit/pkg/registryhttp/audit"
+	"github.com/octohelm/crkit/pkg/registryhttp/notification"
+	"github.com/octohelm/crkit/pkg/registryhttp/replication"
 )
 
 func init() {
@@ -20,9 +26,17 @@ type Registry struct {
 	otel.Otel
 
 	contentapi.NamespaceProvider
+	accesspolicy.AccessPolicyProvider
+	notification.NotificationProvider
+	replication.ReplicationProvider
+	audit.AuditProvider
 
 	UploadPurger     uploadpurger.UploadPurger
 	GarbageCollector garbagecollector.GarbageCollector
+	CacheEvictor     cacheevictor.CacheEvictor
+	Mirror           mirror.Mirror
+	Notification     notification.Dispatcher
+	Replication      replication.Worker
 
 	registryhttp.Server
 }
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"github.com/innoai-tech/infra/pkg/cli"
+	"github.com/innoai-tech/infra/pkg/otel"
+
+	contentapi "github.com/octohelm/crkit/pkg/content/api"
+	"github.com/octohelm/crkit/pkg/content/mirror"
+)
+
+func init() {
+	c := cli.AddTo(App, &Sync{})
+	c.LogFormat = "text"
+}
+
+// Sync 按配置从上游复制镜像到本地存储
+type Sync struct {
+	cli.C
+	otel.Otel
+
+	contentapi.NamespaceProvider
+
+	mirror.Executor
+}
@@ -1,6 +1,25 @@
 // Code generated by gengo:runtimedoc DO NOT EDIT.
 package main
 
+func (v *AuditVerify) RuntimeDoc(names ...string) ([]string, bool) {
+	if len(names) > 0 {
+		switch names[0] {
+		}
+		if doc, ok := runtimeDoc(&v.Otel, "", names...); ok {
+			return doc, ok
+		}
+		if doc, ok := runtimeDoc(&v.NamespaceProvider, "", names...); ok {
+			return doc, ok
+		}
+		if doc, ok := runtimeDoc(&v.Verifier, "", names...); ok {
+			return doc, ok
+		}
+
+		return nil, false
+	}
+	return []string{}, true
+}
+
 func (v *GC) RuntimeDoc(names ...string) ([]string, bool) {
 	if len(names) > 0 {
 		switch names[0] {
@@ -27,6 +46,14 @@ func (v *Registry) RuntimeDoc(names ...string) ([]string, bool) {
 			return []string{}, true
 		case "GarbageCollector":
 			return []string{}, true
+		case "CacheEvictor":
+			return []string{}, true
+		case "Mirror":
+			return []string{}, true
+		case "Notification":
+			return []string{}, true
+		case "Replication":
+			return []string{}, true
 
 		}
 		if doc, ok := runtimeDoc(&v.Otel, "", names...); ok {
@@ -35,6 +62,18 @@ func (v *Registry) RuntimeDoc(names ...string) ([]string, bool) {
 		if doc, ok := runtimeDoc(&v.NamespaceProvider, "", names...); ok {
 			return doc, ok
 		}
+		if doc, ok := runtimeDoc(&v.AccessPolicyProvider, "", names...); ok {
+			return doc, ok
+		}
+		if doc, ok := runtimeDoc(&v.NotificationProvider, "", names...); ok {
+			return doc, ok
+		}
+		if doc, ok := runtimeDoc(&v.ReplicationProvider, "", names...); ok {
+			return doc, ok
+		}
+		if doc, ok := runtimeDoc(&v.AuditProvider, "", names...); ok {
+			return doc, ok
+		}
 		if doc, ok := runtimeDoc(&v.Server, "", names...); ok {
 			return doc, ok
 		}
@@ -44,6 +83,25 @@ func (v *Registry) RuntimeDoc(names ...string) ([]string, bool) {
 	return []string{}, true
 }
 
+func (v *Sync) RuntimeDoc(names ...string) ([]string, bool) {
+	if len(names) > 0 {
+		switch names[0] {
+		}
+		if doc, ok := runtimeDoc(&v.Otel, "", names...); ok {
+			return doc, ok
+		}
+		if doc, ok := runtimeDoc(&v.NamespaceProvider, "", names...); ok {
+			return doc, ok
+		}
+		if doc, ok := runtimeDoc(&v.Executor, "", names...); ok {
+			return doc, ok
+		}
+
+		return nil, false
+	}
+	return []string{}, true
+}
+
 // nolint:deadcode,unused
 func runtimeDoc(v any, prefix string, names ...string) ([]string, bool) {
 	if c, ok := v.(interface {
@@ -0,0 +1,2 @@
+// +gengo:runtimedoc
+package v1
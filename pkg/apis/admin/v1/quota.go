@@ -0,0 +1,16 @@
+package v1
+
+// QuotaUsage 仓库前缀的存储配额与用量
+type QuotaUsage struct {
+	// Prefix 仓库名前缀
+	Prefix string `json:"prefix"`
+	// Limit 配额上限（字节）
+	Limit int64 `json:"limit"`
+	// Used 已用量（字节）
+	Used int64 `json:"used"`
+}
+
+type QuotaUsageList struct {
+	// Items 按前缀排序的配额列表
+	Items []QuotaUsage `json:"items"`
+}
@@ -0,0 +1,28 @@
+package v1
+
+import (
+	"time"
+)
+
+// ReplicationStatus 下游注册表的推送复制状态
+type ReplicationStatus struct {
+	// Target 目标名称
+	Target string `json:"target"`
+	// Pending 待复制的任务数
+	Pending int `json:"pending"`
+	// Retrying 复制失败等待重试的任务数
+	Retrying int `json:"retrying"`
+	// OldestPendingAt 最早的待复制任务的创建时间
+	OldestPendingAt time.Time `json:"oldestPendingAt,omitzero"`
+	// LastSuccessAt 最近一次复制成功的时间
+	LastSuccessAt time.Time `json:"lastSuccessAt,omitzero"`
+	// LastErrorAt 最近一次复制失败的时间
+	LastErrorAt time.Time `json:"lastErrorAt,omitzero"`
+	// LastError 最近一次复制失败的原因
+	LastError string `json:"lastError,omitzero"`
+}
+
+type ReplicationStatusList struct {
+	// Items 按配置顺序排列的目标状态
+	Items []ReplicationStatus `json:"items"`
+}
@@ -0,0 +1,75 @@
+package v1
+
+import (
+	"maps"
+
+	specv1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// SubjectOf 返回清单声明的 subject，未声明时返回 nil
+func SubjectOf(m Manifest) *Descriptor {
+	switch x := unwrap(m).(type) {
+	case *OciManifest:
+		return x.Subject
+	case *OciIndex:
+		return x.Subject
+	case *DockerManifest:
+		return x.Subject
+	case *DockerManifestList:
+		return x.Subject
+	}
+	return nil
+}
+
+// ReferrerDescriptor 生成清单在 referrers 索引中的 Descriptor
+//
+// 未声明 artifactType 的镜像清单以 config.mediaType 作为 artifactType
+func ReferrerDescriptor(m Manifest, d Descriptor) Descriptor {
+	referrer := Descriptor{
+		MediaType: m.Type(),
+		Digest:    d.Digest,
+		Size:      d.Size,
+	}
+
+	switch x := unwrap(m).(type) {
+	case *OciManifest:
+		referrer.ArtifactType = artifactTypeOfImage((*specv1.Manifest)(x))
+		referrer.Annotations = maps.Clone(x.Annotations)
+	case *DockerManifest:
+		referrer.ArtifactType = artifactTypeOfImage((*specv1.Manifest)(x))
+		referrer.Annotations = maps.Clone(x.Annotations)
+	case *OciIndex:
+		referrer.ArtifactType = x.ArtifactType
+		referrer.Annotations = maps.Clone(x.Annotations)
+	case *DockerManifestList:
+		referrer.ArtifactType = x.ArtifactType
+		referrer.Annotations = maps.Clone(x.Annotations)
+	}
+
+	return referrer
+}
+
+func artifactTypeOfImage(m *specv1.Manifest) string {
+	if m.ArtifactType != "" {
+		return m.ArtifactType
+	}
+	return m.Config.MediaType
+}
+
+func unwrap(m Manifest) Manifest {
+	switch x := m.(type) {
+	case *Payload:
+		return unwrap(x.Manifest)
+	case Payload:
+		return unwrap(x.Manifest)
+	case OciManifest:
+		return &x
+	case OciIndex:
+		return &x
+	case DockerManifest:
+		return &x
+	case DockerManifestList:
+		return &x
+	}
+	return m
+}
@@ -143,9 +143,19 @@ func (err *ErrManifestUnknownRevision) Error() string {
 // ErrManifestUnverified 清单校验失败
 type ErrManifestUnverified struct {
 	statuserror.BadRequest
+
+	// Reason 校验失败的原因
+	Reason error
+}
+
+func (ErrManifestUnverified) ErrCode() string {
+	return "MANIFEST_UNVERIFIED"
 }
 
-func (ErrManifestUnverified) Error() string {
+func (err *ErrManifestUnverified) Error() string {
+	if err.Reason != nil {
+		return fmt.Sprintf("unverified manifest: %s", err.Reason)
+	}
 	return "unverified manifest"
 }
 
@@ -197,3 +207,85 @@ func (ErrBlobUploadUnknown) ErrCode() string {
 func (err *ErrBlobUploadUnknown) Error() string {
 	return "blob upload unknown"
 }
+
+// ErrUnauthorized 未认证
+type ErrUnauthorized struct {
+	statuserror.Unauthorized
+
+	// Name 仓库名称
+	Name string
+	// Action 请求的动作
+	Action string
+}
+
+func (ErrUnauthorized) ErrCode() string {
+	return "UNAUTHORIZED"
+}
+
+func (err *ErrUnauthorized) Error() string {
+	return fmt.Sprintf("authentication required to %s repository name=%s", err.Action, err.Name)
+}
+
+// ErrDenied 访问被拒绝
+type ErrDenied struct {
+	statuserror.Forbidden
+
+	// Name 仓库名称
+	Name string
+	// Action 请求的动作
+	Action string
+	// Subject 请求的用户
+	Subject string
+}
+
+func (ErrDenied) ErrCode() string {
+	return "DENIED"
+}
+
+func (err *ErrDenied) Error() string {
+	return fmt.Sprintf("requested access to %s repository name=%s is denied for %s", err.Action, err.Name, err.Subject)
+}
+
+// ErrTagImmutable 不可变标签已指向其他清单
+type ErrTagImmutable struct {
+	statuserror.Forbidden
+
+	// Name 仓库名称
+	Name string
+	// Tag 标签名
+	Tag string
+	// Digest 标签当前指向的清单摘要
+	Digest digest.Digest
+}
+
+func (ErrTagImmutable) ErrCode() string {
+	return "TAG_INVALID"
+}
+
+func (err *ErrTagImmutable) Error() string {
+	return fmt.Sprintf("tag %s of repository name=%s is immutable, already points to %s", err.Tag, err.Name, err.Digest)
+}
+
+// ErrQuotaExceeded 超出仓库前缀的存储配额
+type ErrQuotaExceeded struct {
+	statuserror.Forbidden
+
+	// Name 仓库名称
+	Name string
+	// Prefix 配额对应的仓库前缀
+	Prefix string
+	// Limit 配额上限（字节）
+	Limit int64
+	// Used 已用量（字节）
+	Used int64
+	// Size 本次新增（字节）
+	Size int64
+}
+
+func (ErrQuotaExceeded) ErrCode() string {
+	return "DENIED"
+}
+
+func (err *ErrQuotaExceeded) Error() string {
+	return fmt.Sprintf("quota exceeded for repository name=%s: prefix %q used %d + %d bytes exceeds limit %d", err.Name, err.Prefix, err.Used, err.Size, err.Limit)
+}
@@ -38,6 +38,9 @@ func (r *Range) UnmarshalText(d []byte) error {
 
 var ErrInvalidRange = errors.New("invalid range")
 
+// ErrRangeNotSatisfiable 范围格式正确但超出内容长度
+var ErrRangeNotSatisfiable = errors.New("range not satisfiable")
+
 // ParseRange 解析 "start-end" 格式的范围字符串
 func ParseRange(s string) (*Range, error) {
 	parts := strings.SplitN(s, "-", 2)
@@ -61,3 +64,61 @@ func ParseRange(s string) (*Range, error) {
 		Length: end - start + 1,
 	}, nil
 }
+
+// ContentRange 返回 Content-Range 头的值，size 为内容总长度
+func (r Range) ContentRange(size int64) string {
+	return fmt.Sprintf("bytes %s/%d", r.String(), size)
+}
+
+// ParseByteRange 解析 HTTP Range 头，仅支持单个范围，size 为内容总长度
+//
+// 支持 "bytes=start-end"、"bytes=start-" 和 "bytes=-suffix" 三种形式；
+// 其他单位、多个范围或格式错误时返回 ErrInvalidRange，按 RFC 9110 应忽略 Range 返回完整内容；
+// 起始位置超出内容长度时返回 ErrRangeNotSatisfiable
+func ParseByteRange(s string, size int64) (*Range, error) {
+	spec, ok := strings.CutPrefix(strings.TrimSpace(s), "bytes=")
+	if !ok || strings.Contains(spec, ",") {
+		return nil, ErrInvalidRange
+	}
+
+	first, last, ok := strings.Cut(strings.TrimSpace(spec), "-")
+	if !ok {
+		return nil, ErrInvalidRange
+	}
+
+	if first == "" {
+		suffix, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || suffix < 0 {
+			return nil, ErrInvalidRange
+		}
+		if suffix == 0 || size == 0 {
+			return nil, ErrRangeNotSatisfiable
+		}
+		if suffix > size {
+			suffix = size
+		}
+		return &Range{Start: size - suffix, Length: suffix}, nil
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return nil, ErrInvalidRange
+	}
+	if start >= size {
+		return nil, ErrRangeNotSatisfiable
+	}
+
+	end := size - 1
+
+	if last != "" {
+		e, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || e < start {
+			return nil, ErrInvalidRange
+		}
+		if e < end {
+			end = e
+		}
+	}
+
+	return &Range{Start: start, Length: end - start + 1}, nil
+}
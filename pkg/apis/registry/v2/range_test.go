@@ -0,0 +1,50 @@
+package v2_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/octohelm/x/testing/v2"
+
+	"github.com/octohelm/crkit/pkg/apis/registry/v2"
+)
+
+func TestParseByteRange(t *testing.T) {
+	parse := func(s string) func() (v2.Range, error) {
+		return func() (v2.Range, error) {
+			r, err := v2.ParseByteRange(s, 100)
+			if err != nil {
+				return v2.Range{}, err
+			}
+			return *r, nil
+		}
+	}
+
+	is := func(s string, target error) func() error {
+		return func() error {
+			_, err := v2.ParseByteRange(s, 100)
+			if !errors.Is(err, target) {
+				return errors.New("unexpected error")
+			}
+			return nil
+		}
+	}
+
+	Then(t, "解析单个字节范围",
+		ExpectMustValue(parse("bytes=10-19"), Equal(v2.Range{Start: 10, Length: 10})),
+		ExpectMustValue(parse("bytes=90-"), Equal(v2.Range{Start: 90, Length: 10})),
+		ExpectMustValue(parse("bytes=-20"), Equal(v2.Range{Start: 80, Length: 20})),
+		ExpectMustValue(parse("bytes=90-200"), Equal(v2.Range{Start: 90, Length: 10})),
+	)
+
+	Then(t, "不支持的范围应被忽略",
+		ExpectDo(is("items=0-5", v2.ErrInvalidRange)),
+		ExpectDo(is("bytes=0-5,10-15", v2.ErrInvalidRange)),
+		ExpectDo(is("bytes=20-10", v2.ErrInvalidRange)),
+	)
+
+	Then(t, "超出内容长度时无法满足",
+		ExpectDo(is("bytes=100-", v2.ErrRangeNotSatisfiable)),
+		ExpectDo(is("bytes=-0", v2.ErrRangeNotSatisfiable)),
+	)
+}
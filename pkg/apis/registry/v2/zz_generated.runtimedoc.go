@@ -86,6 +86,31 @@ func (v *ErrBlobUploadUnknown) RuntimeDoc(names ...string) ([]string, bool) {
 	}, true
 }
 
+func (v *ErrDenied) RuntimeDoc(names ...string) ([]string, bool) {
+	if len(names) > 0 {
+		switch names[0] {
+		case "Name":
+			return []string{
+				"仓库名称",
+			}, true
+		case "Action":
+			return []string{
+				"请求的动作",
+			}, true
+		case "Subject":
+			return []string{
+				"请求的用户",
+			}, true
+
+		}
+
+		return nil, false
+	}
+	return []string{
+		"访问被拒绝",
+	}, true
+}
+
 func (v *ErrManifestBlobUnknown) RuntimeDoc(names ...string) ([]string, bool) {
 	if len(names) > 0 {
 		switch names[0] {
@@ -152,6 +177,11 @@ func (v *ErrManifestUnknownRevision) RuntimeDoc(names ...string) ([]string, bool
 func (v *ErrManifestUnverified) RuntimeDoc(names ...string) ([]string, bool) {
 	if len(names) > 0 {
 		switch names[0] {
+		case "Reason":
+			return []string{
+				"校验失败的原因",
+			}, true
+
 		}
 
 		return nil, false
@@ -177,6 +207,39 @@ func (v *ErrNotImplemented) RuntimeDoc(names ...string) ([]string, bool) {
 	}, true
 }
 
+func (v *ErrQuotaExceeded) RuntimeDoc(names ...string) ([]string, bool) {
+	if len(names) > 0 {
+		switch names[0] {
+		case "Name":
+			return []string{
+				"仓库名称",
+			}, true
+		case "Prefix":
+			return []string{
+				"配额对应的仓库前缀",
+			}, true
+		case "Limit":
+			return []string{
+				"配额上限（字节）",
+			}, true
+		case "Used":
+			return []string{
+				"已用量（字节）",
+			}, true
+		case "Size":
+			return []string{
+				"本次新增（字节）",
+			}, true
+
+		}
+
+		return nil, false
+	}
+	return []string{
+		"超出仓库前缀的存储配额",
+	}, true
+}
+
 func (v *ErrRepositoryNameInvalid) RuntimeDoc(names ...string) ([]string, bool) {
 	if len(names) > 0 {
 		switch names[0] {
@@ -214,6 +277,31 @@ func (v *ErrRepositoryUnknown) RuntimeDoc(names ...string) ([]string, bool) {
 	}, true
 }
 
+func (v *ErrTagImmutable) RuntimeDoc(names ...string) ([]string, bool) {
+	if len(names) > 0 {
+		switch names[0] {
+		case "Name":
+			return []string{
+				"仓库名称",
+			}, true
+		case "Tag":
+			return []string{
+				"标签名",
+			}, true
+		case "Digest":
+			return []string{
+				"标签当前指向的清单摘要",
+			}, true
+
+		}
+
+		return nil, false
+	}
+	return []string{
+		"不可变标签已指向其他清单",
+	}, true
+}
+
 func (v *ErrTagUnknown) RuntimeDoc(names ...string) ([]string, bool) {
 	if len(names) > 0 {
 		switch names[0] {
@@ -235,6 +323,27 @@ func (v *ErrTagUnknown) RuntimeDoc(names ...string) ([]string, bool) {
 	}, true
 }
 
+func (v *ErrUnauthorized) RuntimeDoc(names ...string) ([]string, bool) {
+	if len(names) > 0 {
+		switch names[0] {
+		case "Name":
+			return []string{
+				"仓库名称",
+			}, true
+		case "Action":
+			return []string{
+				"请求的动作",
+			}, true
+
+		}
+
+		return nil, false
+	}
+	return []string{
+		"未认证",
+	}, true
+}
+
 func (*Name) RuntimeDoc(names ...string) ([]string, bool) {
 	return []string{
 		"仓库名称",
@@ -9,6 +9,10 @@ import (
 	"os"
 	"path"
 	"slices"
+	"strings"
+	"time"
+
+	openapistrfmt "k8s.io/kube-openapi/pkg/validation/strfmt"
 
 	"github.com/octohelm/unifs/pkg/filesystem"
 	"github.com/octohelm/unifs/pkg/filesystem/api"
@@ -16,8 +20,11 @@ import (
 	"github.com/octohelm/x/logr"
 
 	"github.com/octohelm/crkit/pkg/content"
+	contentcacheindex "github.com/octohelm/crkit/pkg/content/cacheindex"
 	contentfs "github.com/octohelm/crkit/pkg/content/fs"
+	contentmetrics "github.com/octohelm/crkit/pkg/content/metrics"
 	contentproxy "github.com/octohelm/crkit/pkg/content/proxy"
+	contentquota "github.com/octohelm/crkit/pkg/content/quota"
 	contentremote "github.com/octohelm/crkit/pkg/content/remote"
 	"github.com/octohelm/crkit/pkg/driver"
 	driverfs "github.com/octohelm/crkit/pkg/driver/fs"
@@ -33,9 +40,51 @@ type NamespaceProvider struct {
 
 	// 当声明时，将通过多源指定
 	RemoteRegistriesConfigFile string `flag:",omitzero"`
+	// containerd 格式的 hosts 目录（{dir}/{host}/hosts.toml），作为 RemoteRegistriesConfigFile 的替代
+	RemoteRegistriesHostsDir string `flag:",omitzero"`
+
+	// 远程注册表的凭证来源，用于未声明认证信息的上游与镜像：docker 读取 Docker 配置文件（含 credsStore 与 credHelpers），helper:{name} 使用凭证助手 docker-credential-{name}
+	RemoteCredentials string `flag:",omitzero"`
+	// Docker 配置文件，默认 $DOCKER_CONFIG/config.json，未声明时为 ~/.docker/config.json
+	RemoteDockerConfigFile string `flag:",omitzero"`
+
+	// 离线模式，代理缓存仅从本地缓存提供内容，不访问远程注册表
+	Offline bool `flag:",omitzero"`
+	// 远程注册表连续失败达到该次数后熔断，为 0 时不熔断
+	RemoteFailureThreshold int `flag:",omitzero"`
+	// 熔断持续时间，默认 30s
+	RemoteCooldown openapistrfmt.Duration `flag:",omitzero"`
+
+	// 存储配额配置文件（JSON），声明时按仓库前缀限制存储用量，仅作用于非代理模式
+	QuotaConfigFile string `flag:",omitzero"`
+
+	// 声明时，Blob 下载以 307 重定向到存储的预签名地址，仅作用于非代理模式；存储不支持预签名（如本地文件系统）时仍由服务转发
+	BlobRedirect bool `flag:",omitzero"`
+	// 预签名地址的有效期，默认 20m
+	BlobRedirectExpiresIn openapistrfmt.Duration `flag:",omitzero"`
 
-	driver    driver.Driver     `provide:""`
-	namespace content.Namespace `provide:""`
+	driver     driver.Driver           `provide:""`
+	namespace  content.Namespace       `provide:""`
+	quota      contentquota.Quota      `provide:""`
+	cacheIndex contentcacheindex.Index `provide:""`
+}
+
+func (s *NamespaceProvider) resolveQuota(ctx context.Context) (contentquota.Quota, error) {
+	if s.QuotaConfigFile == "" || s.driver == nil {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(s.QuotaConfigFile)
+	if err != nil {
+		return nil, fmt.Errorf("读取存储配额配置文件失败: %w", err)
+	}
+
+	c := &contentquota.Config{}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("解析存储配额配置文件失败: %w", err)
+	}
+
+	return contentquota.New(ctx, s.driver, contentquota.DefaultFile, c)
 }
 
 func (s *NamespaceProvider) resolveRegistryResolver(ctx context.Context) (contentremote.RegistryResolver, error) {
@@ -57,6 +106,19 @@ func (s *NamespaceProvider) resolveRegistryResolver(ctx context.Context) (conten
 		return hosts, nil
 	}
 
+	if s.RemoteRegistriesHostsDir != "" {
+		hosts, err := contentremote.LoadHostsDir(s.RemoteRegistriesHostsDir)
+		if err != nil {
+			return nil, fmt.Errorf("读取远程注册表 hosts 目录失败: %w", err)
+		}
+
+		logr.FromContext(ctx).
+			WithValues(slog.Any("hosts", slices.Collect(maps.Keys(hosts)))).
+			Info("multi-source mirror proxy")
+
+		return hosts, nil
+	}
+
 	if s.Remote.Endpoint != "" {
 		return s.Remote, nil
 	}
@@ -64,6 +126,32 @@ func (s *NamespaceProvider) resolveRegistryResolver(ctx context.Context) (conten
 	return nil, nil
 }
 
+func (s *NamespaceProvider) resolveCredentials(ctx context.Context) (contentremote.CredentialProvider, error) {
+	switch {
+	case s.RemoteCredentials == "":
+		return nil, nil
+	case s.RemoteCredentials == "docker":
+		filename := s.RemoteDockerConfigFile
+		if filename == "" {
+			filename = contentremote.DefaultDockerConfigFile()
+		}
+
+		c, err := contentremote.LoadDockerConfig(filename)
+		if err != nil {
+			return nil, fmt.Errorf("读取 Docker 配置文件失败: %w", err)
+		}
+
+		logr.FromContext(ctx).WithValues(slog.String("file", filename)).Info("remote credentials from docker config")
+
+		return c, nil
+	default:
+		if name, ok := strings.CutPrefix(s.RemoteCredentials, "helper:"); ok && name != "" {
+			return contentremote.CredentialHelper(name), nil
+		}
+		return nil, fmt.Errorf("不支持的远程注册表凭证来源: %s", s.RemoteCredentials)
+	}
+}
+
 func (s *NamespaceProvider) beforeInit(ctx context.Context) error {
 	if s.Content.Backend.IsZero() {
 		s.Content.Backend = strfmt.Endpoint{
@@ -91,22 +179,42 @@ func (s *NamespaceProvider) afterInit(ctx context.Context) error {
 		}
 	}
 
-	local := contentfs.NewNamespace(s.driver)
-
 	// 解析远程注册表解析器
 	remoteResolver, err := s.resolveRegistryResolver(ctx)
 	if err != nil {
 		return err
 	}
 
+	if s.Offline && (remoteResolver == nil || s.NoCache) {
+		return fmt.Errorf("离线模式仅适用于带本地缓存的代理")
+	}
+
 	if remoteResolver != nil {
+		cooldown := time.Duration(s.RemoteCooldown)
+		if cooldown <= 0 {
+			cooldown = 30 * time.Second
+		}
+
+		credentials, err := s.resolveCredentials(ctx)
+		if err != nil {
+			return err
+		}
+
 		if s.NoCache {
-			remote, err := contentremote.New(ctx, remoteResolver)
+			remoteOptions := make([]contentremote.Option, 0, 2)
+			if s.RemoteFailureThreshold > 0 {
+				remoteOptions = append(remoteOptions, contentremote.WithCircuitBreaker(s.RemoteFailureThreshold, cooldown))
+			}
+			if credentials != nil {
+				remoteOptions = append(remoteOptions, contentremote.WithCredentials(credentials))
+			}
+
+			remote, err := contentremote.New(ctx, remoteResolver, remoteOptions...)
 			if err != nil {
 				return err
 			}
 
-			s.namespace = remote
+			s.namespace = contentmetrics.NewNamespace(remote)
 
 			logr.FromContext(ctx).
 				WithValues(slog.String("resolver", fmt.Sprintf("%T", remoteResolver))).
@@ -115,12 +223,39 @@ func (s *NamespaceProvider) afterInit(ctx context.Context) error {
 			return nil
 		}
 
-		proxy, err := contentproxy.NewProxyFallbackRegistry(ctx, local, remoteResolver)
+		// 本地缓存按需回源，清单引用的 Blob 可能尚未缓存
+		local := contentfs.NewNamespace(s.driver, contentfs.WithSparseManifests())
+
+		// 记录缓存内容的访问时间，供缓存淘汰使用
+		s.cacheIndex = contentcacheindex.New(s.driver, contentcacheindex.DefaultDir, time.Minute)
+
+		proxyOptions := []contentproxy.Option{
+			contentproxy.WithCacheIndex(s.cacheIndex),
+		}
+
+		if s.Offline {
+			proxyOptions = append(proxyOptions, contentproxy.WithOffline())
+		}
+
+		// 本地文件系统存储时，回源的临时文件与内容同盘，避免占用系统临时目录
+		if s.Content.Backend.Scheme == "file" {
+			proxyOptions = append(proxyOptions, contentproxy.WithSpoolDir(path.Join(s.Content.Backend.Hostname, s.Content.Backend.Path, contentproxy.DefaultSpoolDir)))
+		}
+
+		if s.RemoteFailureThreshold > 0 {
+			proxyOptions = append(proxyOptions, contentproxy.WithCircuitBreaker(s.RemoteFailureThreshold, cooldown))
+		}
+
+		if credentials != nil {
+			proxyOptions = append(proxyOptions, contentproxy.WithCredentials(credentials))
+		}
+
+		proxy, err := contentproxy.NewProxyFallbackRegistry(ctx, local, remoteResolver, proxyOptions...)
 		if err != nil {
 			return err
 		}
 
-		s.namespace = proxy
+		s.namespace = contentmetrics.NewNamespace(proxy)
 
 		logr.FromContext(ctx).
 			WithValues(slog.String("resolver", fmt.Sprintf("%T", remoteResolver))).
@@ -129,7 +264,27 @@ func (s *NamespaceProvider) afterInit(ctx context.Context) error {
 		return nil
 	}
 
-	s.namespace = local
+	options := make([]contentfs.Option, 0, 2)
+
+	q, err := s.resolveQuota(ctx)
+	if err != nil {
+		return err
+	}
+
+	if q != nil {
+		s.quota = q
+		options = append(options, contentfs.WithQuota(q))
+	}
+
+	if s.BlobRedirect {
+		expiresIn := time.Duration(s.BlobRedirectExpiresIn)
+		if expiresIn <= 0 {
+			expiresIn = 20 * time.Minute
+		}
+		options = append(options, contentfs.WithPresignedURL(expiresIn))
+	}
+
+	s.namespace = contentmetrics.NewNamespace(contentfs.NewNamespace(s.driver, options...))
 
 	return nil
 }
@@ -5,6 +5,8 @@ import (
 	context "context"
 
 	content "github.com/octohelm/crkit/pkg/content"
+	cacheindex "github.com/octohelm/crkit/pkg/content/cacheindex"
+	contentquota "github.com/octohelm/crkit/pkg/content/quota"
 	pkgdriver "github.com/octohelm/crkit/pkg/driver"
 )
 
@@ -12,6 +14,8 @@ func (p *NamespaceProvider) InjectContext(ctx context.Context) context.Context {
 	ctx = p.Content.InjectContext(ctx)
 	ctx = pkgdriver.DriverInjectContext(ctx, p.driver)
 	ctx = content.NamespaceInjectContext(ctx, p.namespace)
+	ctx = contentquota.QuotaInjectContext(ctx, p.quota)
+	ctx = cacheindex.IndexInjectContext(ctx, p.cacheIndex)
 
 	return ctx
 }
@@ -5,6 +5,7 @@ import (
 	"io"
 	"iter"
 
+	"github.com/distribution/reference"
 	"github.com/opencontainers/go-digest"
 
 	manifestv1 "github.com/octohelm/crkit/pkg/apis/manifest/v1"
@@ -21,6 +22,15 @@ type Provider interface {
 	Open(ctx context.Context, dgst digest.Digest) (io.ReadCloser, error)
 }
 
+type RangeProvider interface {
+	OpenRange(ctx context.Context, dgst digest.Digest, offset int64, length int64) (io.ReadCloser, error)
+}
+
+// PresignedURLProvider 返回 Blob 限时有效的直接下载地址，存储不支持时返回 ErrNotImplemented
+type PresignedURLProvider interface {
+	PresignedURL(ctx context.Context, dgst digest.Digest) (string, error)
+}
+
 type Ingester interface {
 	Writer(ctx context.Context) (BlobWriter, error)
 	Resume(ctx context.Context, id string) (BlobWriter, error)
@@ -30,6 +40,10 @@ type Remover interface {
 	Remove(ctx context.Context, dgst digest.Digest) error
 }
 
+type Mounter interface {
+	Mount(ctx context.Context, from reference.Named, dgst digest.Digest) (*manifestv1.Descriptor, error)
+}
+
 type BlobLister interface {
 	Blob(ctx context.Context) iter.Seq[digest.Digest]
 }
@@ -0,0 +1,242 @@
+package cacheindex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"iter"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+
+	"github.com/octohelm/crkit/pkg/driver"
+)
+
+// DefaultDir 访问记录在存储中的位置
+const DefaultDir = "proxycache"
+
+// +gengo:injectable:provider
+type Index interface {
+	// Record 标记为从远程缓存的内容并刷新访问时间
+	Record(ctx context.Context, e Entry) error
+	// Touch 刷新已标记内容的访问时间，未标记的内容（如本地推送）忽略
+	Touch(ctx context.Context, e Entry) error
+	// Remove 移除标记
+	Remove(ctx context.Context, e Entry) error
+	// Entries 列出所有标记及其访问时间
+	Entries(ctx context.Context) iter.Seq2[Entry, error]
+}
+
+type Kind string
+
+const (
+	KindManifest Kind = "manifest"
+	KindBlob     Kind = "blob"
+	// KindTag 代理同步时创建的标签，Digest 为同步时指向的清单
+	KindTag Kind = "tag"
+)
+
+// Entry 缓存内容
+type Entry struct {
+	Kind Kind
+	// 仓库名，仅清单与标签有效；Blob 在存储中按摘要共享
+	Name string
+	// 标签名，仅标签有效
+	Tag    string
+	Digest digest.Digest
+	// 最近访问时间
+	AccessedAt time.Time
+}
+
+// New 访问记录以文件形式保存在 dir 下，访问时间取文件修改时间
+//
+// 同一内容在 touchInterval 内重复访问时不再写入
+func New(d driver.Driver, dir string, touchInterval time.Duration) Index {
+	return &index{
+		driver:        d,
+		dir:           dir,
+		touchInterval: touchInterval,
+		touchedAt:     map[string]time.Time{},
+	}
+}
+
+type index struct {
+	driver        driver.Driver
+	dir           string
+	touchInterval time.Duration
+
+	mu        sync.Mutex
+	touchedAt map[string]time.Time
+}
+
+// manifests/{name}/{algorithm}/{hex_digest}
+// tags/{name}/{tag}/{algorithm}/{hex_digest}
+// blobs/{algorithm}/{hex_digest}
+func (i *index) path(e Entry) string {
+	switch e.Kind {
+	case KindBlob:
+		return path.Join(i.dir, "blobs", e.Digest.Algorithm().String(), e.Digest.Hex())
+	case KindTag:
+		return path.Join(i.dir, "tags", e.Name, e.Tag, e.Digest.Algorithm().String(), e.Digest.Hex())
+	}
+	return path.Join(i.dir, "manifests", e.Name, e.Digest.Algorithm().String(), e.Digest.Hex())
+}
+
+func (i *index) Record(ctx context.Context, e Entry) error {
+	p := i.path(e)
+
+	if err := i.driver.PutContent(ctx, p, []byte(e.Digest)); err != nil {
+		return err
+	}
+
+	i.touched(p, time.Now())
+
+	return nil
+}
+
+func (i *index) Touch(ctx context.Context, e Entry) error {
+	p := i.path(e)
+	now := time.Now()
+
+	if !i.shouldTouch(p, now) {
+		return nil
+	}
+
+	if _, err := i.driver.Stat(ctx, p); err != nil {
+		if perr, ok := errors.AsType[*os.PathError](err); ok && os.IsNotExist(perr) {
+			return nil
+		}
+		return err
+	}
+
+	return i.driver.PutContent(ctx, p, []byte(e.Digest))
+}
+
+func (i *index) Remove(ctx context.Context, e Entry) error {
+	p := i.path(e)
+
+	i.mu.Lock()
+	delete(i.touchedAt, p)
+	i.mu.Unlock()
+
+	if err := i.driver.Delete(ctx, p); err != nil {
+		if perr, ok := errors.AsType[*os.PathError](err); ok && os.IsNotExist(perr) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// shouldTouch 同时记录本次检查，未标记的内容在间隔内也不再检查
+func (i *index) shouldTouch(p string, now time.Time) bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if t, ok := i.touchedAt[p]; ok && now.Sub(t) < i.touchInterval {
+		return false
+	}
+
+	i.touchedLocked(p, now)
+	return true
+}
+
+func (i *index) touched(p string, now time.Time) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.touchedLocked(p, now)
+}
+
+func (i *index) touchedLocked(p string, now time.Time) {
+	// 避免长时间运行后无限增长
+	if len(i.touchedAt) >= 10000 {
+		for k, t := range i.touchedAt {
+			if now.Sub(t) >= i.touchInterval {
+				delete(i.touchedAt, k)
+			}
+		}
+	}
+
+	i.touchedAt[p] = now
+}
+
+func (i *index) Entries(ctx context.Context) iter.Seq2[Entry, error] {
+	return func(yield func(Entry, error) bool) {
+		err := i.driver.WalkDir(ctx, i.dir, func(pathname string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if pathname == "." || d.IsDir() {
+				return nil
+			}
+
+			e, err := parseEntry(pathname)
+			if err != nil {
+				return err
+			}
+
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			e.AccessedAt = info.ModTime()
+
+			if !yield(*e, nil) {
+				return fs.SkipAll
+			}
+
+			return nil
+		})
+		if err != nil {
+			if perr, ok := errors.AsType[*os.PathError](err); ok && os.IsNotExist(perr) {
+				return
+			}
+			yield(Entry{}, err)
+		}
+	}
+}
+
+func parseEntry(pathname string) (*Entry, error) {
+	parts := strings.Split(pathname, "/")
+	if len(parts) < 3 {
+		return nil, fmt.Errorf("invalid cache index path %s", pathname)
+	}
+
+	e := &Entry{
+		Digest: digest.NewDigestFromHex(parts[len(parts)-2], parts[len(parts)-1]),
+	}
+
+	switch parts[0] {
+	case "blobs":
+		e.Kind = KindBlob
+	case "manifests":
+		e.Kind = KindManifest
+		e.Name = strings.Join(parts[1:len(parts)-2], "/")
+	case "tags":
+		if len(parts) < 5 {
+			return nil, fmt.Errorf("invalid cache index path %s", pathname)
+		}
+		e.Kind = KindTag
+		e.Name = strings.Join(parts[1:len(parts)-3], "/")
+		e.Tag = parts[len(parts)-3]
+	default:
+		return nil, fmt.Errorf("invalid cache index path %s", pathname)
+	}
+
+	if err := e.Digest.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid digest of cache index path %s: %w", pathname, err)
+	}
+
+	if e.Kind != KindBlob && e.Name == "" {
+		return nil, fmt.Errorf("invalid cache index path %s", pathname)
+	}
+
+	return e, nil
+}
@@ -0,0 +1,85 @@
+package cacheindex_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+
+	"github.com/octohelm/unifs/pkg/filesystem/local"
+	. "github.com/octohelm/x/testing/v2"
+
+	"github.com/octohelm/crkit/pkg/content/cacheindex"
+	driverfs "github.com/octohelm/crkit/pkg/driver/fs"
+)
+
+func TestIndex(t *testing.T) {
+	d := driverfs.FromFileSystem(local.NewFS(t.TempDir()))
+	idx := cacheindex.New(d, cacheindex.DefaultDir, 0)
+
+	cached := cacheindex.Entry{Kind: cacheindex.KindManifest, Name: "library/nginx", Digest: digest.FromString("cached")}
+	pushed := cacheindex.Entry{Kind: cacheindex.KindManifest, Name: "library/nginx", Digest: digest.FromString("pushed")}
+	blob := cacheindex.Entry{Kind: cacheindex.KindBlob, Digest: digest.FromString("blob")}
+	tag := cacheindex.Entry{Kind: cacheindex.KindTag, Name: "library/nginx", Tag: "latest", Digest: digest.FromString("cached")}
+
+	entries := func() ([]cacheindex.Entry, error) {
+		list := make([]cacheindex.Entry, 0)
+		for e, err := range idx.Entries(t.Context()) {
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, e)
+		}
+		return list, nil
+	}
+
+	keys := func(list []cacheindex.Entry) []string {
+		keys := make([]string, 0, len(list))
+		for _, e := range list {
+			keys = append(keys, string(e.Kind)+":"+e.Name+":"+e.Tag+"@"+e.Digest.String())
+		}
+		slices.Sort(keys)
+		return keys
+	}
+
+	t.Run("空索引", func(t *testing.T) {
+		Then(t, "无标记",
+			ExpectMustValue(entries, Equal([]cacheindex.Entry{})),
+		)
+	})
+
+	t.Run("仅记录标记过的内容", func(t *testing.T) {
+		Must(t, func() error {
+			if err := idx.Record(t.Context(), cached); err != nil {
+				return err
+			}
+			if err := idx.Record(t.Context(), blob); err != nil {
+				return err
+			}
+			if err := idx.Record(t.Context(), tag); err != nil {
+				return err
+			}
+			return idx.Touch(t.Context(), pushed)
+		})
+
+		Then(t, "本地推送的内容未被标记",
+			ExpectMustValue(func() ([]string, error) {
+				list, err := entries()
+				return keys(list), err
+			}, Equal(keys([]cacheindex.Entry{cached, blob, tag}))),
+		)
+	})
+
+	t.Run("移除标记", func(t *testing.T) {
+		Must(t, func() error {
+			return idx.Remove(t.Context(), cached)
+		})
+
+		Then(t, "仅剩 Blob 与标签",
+			ExpectMustValue(func() ([]string, error) {
+				list, err := entries()
+				return keys(list), err
+			}, Equal(keys([]cacheindex.Entry{blob, tag}))),
+		)
+	})
+}
@@ -3,9 +3,11 @@ package collect
 import (
 	"context"
 	"errors"
+	"slices"
 
 	"github.com/opencontainers/go-digest"
 
+	manifestv1 "github.com/octohelm/crkit/pkg/apis/manifest/v1"
 	"github.com/octohelm/crkit/pkg/apis/registry/v2"
 	"github.com/octohelm/crkit/pkg/content"
 )
@@ -31,6 +33,78 @@ func Catalogs(ctx context.Context, ns content.Namespace) (catalogs []string, err
 	return catalogs, err
 }
 
+// RepositoryNames 返回排在 last 之后的至多 n 个仓库名称，n <= 0 时不限制
+func RepositoryNames(ctx context.Context, ns content.Namespace, last string, n int) (names []string, err error) {
+	if underlying, ok := ns.(content.PersistNamespaceWrapper); ok {
+		ns = underlying.UnwarpPersistNamespace()
+	}
+
+	i, ok := ns.(content.RepositoryNameAfterIterable)
+	if !ok {
+		all, err := Catalogs(ctx, ns)
+		if err != nil {
+			return nil, err
+		}
+		return page(all, last, n), nil
+	}
+
+	for named, e := range i.RepositoryNamesAfter(ctx, last) {
+		if e != nil {
+			err = e
+			return names, err
+		}
+		names = append(names, named.Name())
+		if n > 0 && len(names) >= n {
+			break
+		}
+	}
+
+	return names, err
+}
+
+// Tags 返回排在 last 之后的至多 n 个标签，n <= 0 时不限制
+func Tags(ctx context.Context, tagService content.TagService, last string, n int) (tags []string, err error) {
+	i, ok := tagService.(content.TagAfterIterable)
+	if !ok {
+		all, err := tagService.All(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return page(all, last, n), nil
+	}
+
+	for tag, e := range i.TagsAfter(ctx, last) {
+		if e != nil {
+			err = e
+			return tags, err
+		}
+		tags = append(tags, tag)
+		if n > 0 && len(tags) >= n {
+			break
+		}
+	}
+
+	return tags, err
+}
+
+func page(values []string, last string, n int) []string {
+	values = slices.Sorted(slices.Values(values))
+
+	if last != "" {
+		i, found := slices.BinarySearch(values, last)
+		if found {
+			i++
+		}
+		values = values[i:]
+	}
+
+	if n > 0 && len(values) > n {
+		values = values[:n]
+	}
+
+	return values
+}
+
 func TagRevisions(ctx context.Context, tagService content.TagService, tag string) (digests []digest.Digest, err error) {
 	i, ok := tagService.(content.TagRevisionIterable)
 	if !ok {
@@ -63,6 +137,24 @@ func Manifests(ctx context.Context, manifestService content.ManifestService) (di
 	return digests, err
 }
 
+func Referrers(ctx context.Context, manifestService content.ManifestService, subject digest.Digest, artifactType string) (descriptors []manifestv1.Descriptor, err error) {
+	i, ok := manifestService.(content.ReferrersIterable)
+	if !ok {
+		return nil, &v2.ErrNotImplemented{Reason: errors.New("ReferrersIterable of ManifestService")}
+	}
+	for d, e := range i.Referrers(ctx, subject) {
+		if e != nil {
+			err = e
+			return descriptors, err
+		}
+		if artifactType != "" && d.ArtifactType != artifactType {
+			continue
+		}
+		descriptors = append(descriptors, d)
+	}
+	return descriptors, err
+}
+
 func Layers(ctx context.Context, blobStore content.BlobStore) (digests []digest.Digest, err error) {
 	i, ok := blobStore.(content.LinkedDigestIterable)
 	if !ok {
@@ -17,6 +17,7 @@ import (
 	manifestv1 "github.com/octohelm/crkit/pkg/apis/manifest/v1"
 	"github.com/octohelm/crkit/pkg/apis/registry/v2"
 	"github.com/octohelm/crkit/pkg/content"
+	"github.com/octohelm/crkit/pkg/driver"
 )
 
 type blobStore struct {
@@ -105,6 +106,34 @@ func (bs *blobStore) Open(ctx context.Context, dgst digest.Digest) (io.ReadClose
 	return file, nil
 }
 
+var _ content.RangeProvider = &blobStore{}
+
+func (bs *blobStore) OpenRange(ctx context.Context, dgst digest.Digest, offset int64, length int64) (io.ReadCloser, error) {
+	file, err := bs.workspace.ReadRange(ctx, bs.workspace.layout.BlobDataPath(dgst), offset, length)
+	if err != nil {
+		if perr, ok := errors.AsType[*os.PathError](err); ok {
+			if os.IsNotExist(perr) {
+				return nil, &v2.ErrBlobUnknown{
+					Digest: dgst,
+				}
+			}
+		}
+		return nil, err
+	}
+	return file, nil
+}
+
+var _ content.PresignedURLProvider = &blobStore{}
+
+func (bs *blobStore) PresignedURL(ctx context.Context, dgst digest.Digest) (string, error) {
+	presigner, ok := bs.workspace.Driver.(driver.Presigner)
+	if !ok || bs.workspace.presignExpires <= 0 {
+		return "", &v2.ErrNotImplemented{Reason: errors.New("Presigner of Driver")}
+	}
+
+	return presigner.PresignedURL(ctx, bs.workspace.layout.BlobDataPath(dgst), bs.workspace.presignExpires)
+}
+
 func (bs *blobStore) Writer(ctx context.Context) (content.BlobWriter, error) {
 	id := uuid.New().String()
 	startedAt := time.Now().UTC()
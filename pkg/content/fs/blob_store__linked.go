@@ -9,6 +9,7 @@ import (
 	"iter"
 	"os"
 	"path"
+	"time"
 
 	"github.com/distribution/reference"
 	"github.com/opencontainers/go-digest"
@@ -16,11 +17,14 @@ import (
 	manifestv1 "github.com/octohelm/crkit/pkg/apis/manifest/v1"
 	"github.com/octohelm/crkit/pkg/apis/registry/v2"
 	"github.com/octohelm/crkit/pkg/content"
+	"github.com/octohelm/crkit/pkg/content/quota"
 )
 
 func newLinkedBlobStore(w *workspace, named reference.Named) *linkedBlobStore {
 	return &linkedBlobStore{
 		workspace: w,
+		named:     named,
+		quota:     w.quota,
 		blobStore: &blobStore{
 			workspace: w,
 		},
@@ -77,6 +81,25 @@ func newLinkedBlobStoreForTagService(w *workspace, named reference.Named, tag st
 	}
 }
 
+func newLinkedBlobStoreForReferrers(w *workspace, named reference.Named, subject digest.Digest) *linkedBlobStore {
+	return &linkedBlobStore{
+		workspace: w,
+		blobStore: &blobStore{workspace: w},
+		linkDirFunc: func() string {
+			return w.layout.RepositoryManifestReferrersPath(named, subject)
+		},
+		linkPathFunc: func(dgst digest.Digest) string {
+			return w.layout.RepositoryManifestReferrerLinkPath(named, subject, dgst)
+		},
+		errUnknownFunc: func(dgst digest.Digest) error {
+			return &v2.ErrManifestUnknownRevision{
+				Name:     named.Name(),
+				Revision: dgst,
+			}
+		},
+	}
+}
+
 type linkedBlobStore struct {
 	workspace      *workspace
 	blobStore      *blobStore
@@ -84,6 +107,51 @@ type linkedBlobStore struct {
 
 	linkDirFunc  func() string
 	linkPathFunc func(dgst digest.Digest) string
+
+	// 仅仓库层关联计入配额
+	named reference.Named
+	quota quota.Quota
+}
+
+// link 写入关联，新关联先计入配额
+func (lbs *linkedBlobStore) link(ctx context.Context, d *manifestv1.Descriptor) error {
+	charged, err := lbs.charge(ctx, d.Digest, d.Size)
+	if err != nil {
+		return err
+	}
+
+	return lbs.putLink(ctx, d, charged)
+}
+
+// charge 尚未关联时计入配额，返回是否已计入
+func (lbs *linkedBlobStore) charge(ctx context.Context, dgst digest.Digest, size int64) (bool, error) {
+	if lbs.quota == nil {
+		return false, nil
+	}
+
+	if _, err := lbs.workspace.Stat(ctx, lbs.linkPathFunc(dgst)); err == nil {
+		return false, nil
+	} else if perr, ok := errors.AsType[*os.PathError](err); !ok || !os.IsNotExist(perr) {
+		return false, err
+	}
+
+	if err := lbs.quota.Charge(ctx, lbs.named, dgst, size); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// putLink 写入关联，失败时释放已计入的配额
+func (lbs *linkedBlobStore) putLink(ctx context.Context, d *manifestv1.Descriptor, charged bool) error {
+	if err := lbs.workspace.PutContent(ctx, lbs.linkPathFunc(d.Digest), []byte(d.Digest)); err != nil {
+		if charged {
+			_ = lbs.quota.Release(ctx, lbs.named, d.Digest, d.Size)
+		}
+		return err
+	}
+
+	return nil
 }
 
 var _ content.LinkedDigestIterable = &linkedBlobStore{}
@@ -146,7 +214,20 @@ func (lbs *linkedBlobStore) LinkedDigests(ctx context.Context) iter.Seq2[content
 }
 
 func (lbs *linkedBlobStore) Remove(ctx context.Context, dgst digest.Digest) error {
-	return lbs.workspace.Delete(ctx, lbs.linkPathFunc(dgst))
+	if lbs.quota == nil {
+		return lbs.workspace.Delete(ctx, lbs.linkPathFunc(dgst))
+	}
+
+	d, err := lbs.Info(ctx, dgst)
+	if err != nil {
+		return err
+	}
+
+	if err := lbs.workspace.Delete(ctx, lbs.linkPathFunc(dgst)); err != nil {
+		return err
+	}
+
+	return lbs.quota.Release(ctx, lbs.named, dgst, d.Size)
 }
 
 func (lbs *linkedBlobStore) Info(ctx context.Context, dgst digest.Digest) (*manifestv1.Descriptor, error) {
@@ -181,6 +262,57 @@ func (lbs *linkedBlobStore) Open(ctx context.Context, dgst digest.Digest) (io.Re
 	return lbs.blobStore.Open(ctx, dgst)
 }
 
+var _ content.RangeProvider = &linkedBlobStore{}
+
+func (lbs *linkedBlobStore) OpenRange(ctx context.Context, dgst digest.Digest, offset int64, length int64) (io.ReadCloser, error) {
+	link := lbs.linkPathFunc(dgst)
+
+	_, err := lbs.workspace.Stat(ctx, link)
+	if err != nil {
+		if perr, ok := errors.AsType[*os.PathError](err); ok {
+			if os.IsNotExist(perr) {
+				return nil, lbs.errUnknownFunc(dgst)
+			}
+		}
+		return nil, err
+	}
+
+	return lbs.blobStore.OpenRange(ctx, dgst, offset, length)
+}
+
+var _ content.PresignedURLProvider = &linkedBlobStore{}
+
+func (lbs *linkedBlobStore) PresignedURL(ctx context.Context, dgst digest.Digest) (string, error) {
+	link := lbs.linkPathFunc(dgst)
+
+	_, err := lbs.workspace.Stat(ctx, link)
+	if err != nil {
+		if perr, ok := errors.AsType[*os.PathError](err); ok {
+			if os.IsNotExist(perr) {
+				return "", lbs.errUnknownFunc(dgst)
+			}
+		}
+		return "", err
+	}
+
+	return lbs.blobStore.PresignedURL(ctx, dgst)
+}
+
+var _ content.Mounter = &linkedBlobStore{}
+
+func (lbs *linkedBlobStore) Mount(ctx context.Context, from reference.Named, dgst digest.Digest) (*manifestv1.Descriptor, error) {
+	d, err := newLinkedBlobStore(lbs.workspace, from).Info(ctx, dgst)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := lbs.link(ctx, d); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
 func (lbs *linkedBlobStore) Resume(ctx context.Context, id string) (content.BlobWriter, error) {
 	w, err := lbs.blobStore.Resume(ctx, id)
 	if err != nil {
@@ -211,14 +343,43 @@ type linkedBlobWriter struct {
 	linkedBlobStore *linkedBlobStore
 }
 
+func (w *linkedBlobWriter) StartedAt() time.Time {
+	if s, ok := w.BlobWriter.(interface{ StartedAt() time.Time }); ok {
+		return s.StartedAt()
+	}
+	return time.Time{}
+}
+
 func (w *linkedBlobWriter) Commit(ctx context.Context, expected manifestv1.Descriptor) (*manifestv1.Descriptor, error) {
+	lbs := w.linkedBlobStore
+
+	// 提交前计入配额，超出配额时不落盘 Blob
+	charged := false
+	if expected.Digest != "" {
+		c, err := lbs.charge(ctx, expected.Digest, w.Size(ctx))
+		if err != nil {
+			return nil, err
+		}
+		charged = c
+	}
+
 	d, err := w.BlobWriter.Commit(ctx, expected)
 	if err != nil {
+		if charged {
+			_ = lbs.quota.Release(ctx, lbs.named, expected.Digest, w.Size(ctx))
+		}
 		return nil, err
 	}
 
+	if expected.Digest == "" {
+		charged, err = lbs.charge(ctx, d.Digest, d.Size)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// always put link to fresh mod time
-	if err := w.linkedBlobStore.workspace.PutContent(ctx, w.linkedBlobStore.linkPathFunc(d.Digest), []byte(d.Digest)); err != nil {
+	if err := lbs.putLink(ctx, d, charged); err != nil {
 		return nil, err
 	}
 
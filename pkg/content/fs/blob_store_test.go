@@ -3,6 +3,7 @@ package fs
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -15,6 +16,7 @@ import (
 	. "github.com/octohelm/x/testing/v2"
 
 	manifestv1 "github.com/octohelm/crkit/pkg/apis/manifest/v1"
+	"github.com/octohelm/crkit/pkg/apis/registry/v2"
 	"github.com/octohelm/crkit/pkg/content"
 	"github.com/octohelm/crkit/pkg/content/fs/layout"
 	driverfs "github.com/octohelm/crkit/pkg/driver/fs"
@@ -87,6 +89,24 @@ func TestBlobStore(t *testing.T) {
 					}, Equal(string(text))),
 				)
 			})
+
+			t.Run("get content range", func(t *testing.T) {
+				f := MustValue(t, func() (io.ReadCloser, error) {
+					return blobs.OpenRange(t.Context(), d.Digest, 6, 5)
+				})
+				defer f.Close()
+
+				Then(
+					t, "read content of range",
+					ExpectMustValue(func() (string, error) {
+						data, err := io.ReadAll(f)
+						if err != nil {
+							return "", err
+						}
+						return string(data), nil
+					}, Equal("world")),
+				)
+			})
 		})
 	})
 
@@ -164,4 +184,70 @@ func TestBlobStore(t *testing.T) {
 			})
 		})
 	})
+
+	t.Run("GIVEN linked blob stores of two repositories", func(t *testing.T) {
+		w := newWorkspace(fs, layout.Default)
+
+		staging := newLinkedBlobStore(w, v2.Name("staging/app"))
+		prod := newLinkedBlobStore(w, v2.Name("prod/app"))
+
+		text := []byte("layer")
+
+		d := MustValue(t, func() (*manifestv1.Descriptor, error) {
+			bw, err := staging.Writer(t.Context())
+			if err != nil {
+				return nil, err
+			}
+			defer bw.Close()
+
+			if _, err := bw.Write(text); err != nil {
+				return nil, err
+			}
+			return bw.Commit(t.Context(), manifestv1.Descriptor{})
+		})
+
+		t.Run("mount blob from staging to prod", func(t *testing.T) {
+			Then(
+				t, "success",
+				ExpectMustValue(
+					func() (digest.Digest, error) {
+						mounted, err := prod.Mount(t.Context(), v2.Name("staging/app"), d.Digest)
+						if err != nil {
+							return "", err
+						}
+						return mounted.Digest, nil
+					},
+					Equal(d.Digest),
+				),
+			)
+
+			Then(
+				t, "blob linked in prod",
+				ExpectMustValue(
+					func() (int64, error) {
+						info, err := prod.Info(t.Context(), d.Digest)
+						if err != nil {
+							return 0, err
+						}
+						return info.Size, nil
+					},
+					Equal(int64(len(text))),
+				),
+			)
+		})
+
+		t.Run("mount blob from repository without link", func(t *testing.T) {
+			Then(
+				t, "blob unknown",
+				ExpectMustValue(
+					func() (bool, error) {
+						_, err := staging.Mount(t.Context(), v2.Name("other/app"), d.Digest)
+						_, ok := errors.AsType[*v2.ErrManifestBlobUnknown](err)
+						return ok, nil
+					},
+					Equal(true),
+				),
+			)
+		})
+	})
 }
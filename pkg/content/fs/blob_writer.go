@@ -38,6 +38,10 @@ func (bw *blobWriter) ID() string {
 	return bw.id
 }
 
+func (bw *blobWriter) StartedAt() time.Time {
+	return bw.startedAt
+}
+
 func (bw *blobWriter) Write(p []byte) (n int, err error) {
 	if err := bw.resumeDigestIfNeed(bw.ctx); err != nil {
 		return 0, err
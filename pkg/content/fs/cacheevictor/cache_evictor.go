@@ -0,0 +1,80 @@
+package cacheevictor
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+
+	"github.com/innoai-tech/infra/pkg/agent"
+	"github.com/innoai-tech/infra/pkg/cron"
+	"github.com/octohelm/exp/xiter"
+	"github.com/octohelm/unifs/pkg/units"
+	"github.com/octohelm/x/sync/singleflight"
+
+	"github.com/octohelm/crkit/pkg/content"
+	"github.com/octohelm/crkit/pkg/content/cacheindex"
+	"github.com/octohelm/crkit/pkg/driver"
+)
+
+// +gengo:injectable
+type CacheEvictor struct {
+	agent.Agent
+
+	// 代理缓存的存储上限，为 0 时不淘汰
+	MaxSize units.BinarySize `flags:",omitzero"`
+	Period  cron.Spec        `flags:",omitzero"`
+	// 该时间内访问过的镜像不淘汰
+	ExcludeAccessedIn strfmt.Duration `flags:",omitzero"`
+
+	driver    driver.Driver     `inject:",opt"`
+	namespace content.Namespace `inject:",opt"`
+	index     cacheindex.Index  `inject:",opt"`
+}
+
+func (a *CacheEvictor) Disabled(ctx context.Context) bool {
+	return a.driver == nil || a.namespace == nil || a.index == nil || a.MaxSize <= 0 || a.Period.Schedule() == nil
+}
+
+func (a *CacheEvictor) SetDefaults() {
+	if a.Period.IsZero() {
+		a.Period = "@every 10m"
+	}
+
+	if a.ExcludeAccessedIn == 0 {
+		a.ExcludeAccessedIn = strfmt.Duration(time.Hour)
+	}
+}
+
+func (a *CacheEvictor) afterInit(ctx context.Context) error {
+	if a.Disabled(ctx) {
+		return nil
+	}
+
+	sfg := singleflight.Group[string]{}
+
+	a.Host("Evict Cache", func(ctx context.Context) error {
+		for range xiter.Merge(
+			xiter.Of(time.Now()),
+			a.Period.Times(ctx),
+		) {
+			a.Go(ctx, func(ctx context.Context) error {
+				err, _ := sfg.Do("evict", func() error {
+					defer sfg.Forget("evict")
+
+					return a.Evict(ctx)
+				})
+
+				return err
+			})
+		}
+
+		return nil
+	})
+
+	return nil
+}
+
+func (a *CacheEvictor) Evict(ctx context.Context) error {
+	return Evict(ctx, a.namespace, a.driver, a.index, int64(a.MaxSize), time.Duration(a.ExcludeAccessedIn))
+}
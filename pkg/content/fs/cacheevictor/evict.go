@@ -0,0 +1,353 @@
+package cacheevictor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/distribution/reference"
+	"github.com/opencontainers/go-digest"
+
+	"github.com/octohelm/x/logr"
+
+	manifestv1 "github.com/octohelm/crkit/pkg/apis/manifest/v1"
+	"github.com/octohelm/crkit/pkg/apis/registry/v2"
+	"github.com/octohelm/crkit/pkg/content"
+	"github.com/octohelm/crkit/pkg/content/cacheindex"
+	"github.com/octohelm/crkit/pkg/content/fs/garbagecollector"
+	"github.com/octohelm/crkit/pkg/content/fs/layout"
+	"github.com/octohelm/crkit/pkg/driver"
+)
+
+// Evict 代理缓存的用量超过 maxSize 时，按最近访问时间从旧到新淘汰缓存镜像，直至预计用量不超过 maxSize
+//
+// 用量仅统计 index 中标记的清单与 Blob，Blob 大小取自缓存清单中的描述，本地推送的内容不计入。
+// 仅淘汰 index 中标记的缓存清单，本地推送的内容不受影响；淘汰时先删除 index 中标记为代理创建且指向该清单的标签，再删除清单，
+// 之后执行垃圾回收清理不再被引用的 Blob。仍被本地创建的标签引用的清单不淘汰。共享的 Blob 在仍被引用时不会释放，实际用量可能仍超出，由下一周期继续淘汰。
+// excludeAccessedIn 内访问过的镜像不淘汰，同时作为垃圾回收排除的修改时间
+func Evict(
+	ctx context.Context,
+	namespace content.Namespace, d driver.Driver, index cacheindex.Index,
+	maxSize int64,
+	excludeAccessedIn time.Duration,
+) error {
+	if underlying, ok := namespace.(content.PersistNamespaceWrapper); ok {
+		namespace = underlying.UnwarpPersistNamespace()
+	}
+
+	l := logr.FromContext(ctx)
+
+	e := &evictor{
+		namespace: namespace,
+		driver:    d,
+		index:     index,
+	}
+
+	images, err := e.images(ctx)
+	if err != nil {
+		return err
+	}
+
+	used, err := e.usage(ctx, images)
+	if err != nil {
+		return fmt.Errorf("failed to calculate usage: %w", err)
+	}
+
+	if used <= maxSize {
+		return nil
+	}
+
+	l.WithValues(
+		slog.Int64("used", used),
+		slog.Int64("max", maxSize),
+	).Info("evicting")
+
+	stabled := time.Now().Add(-excludeAccessedIn)
+
+	evicted := 0
+
+	for _, img := range images {
+		if used <= maxSize || img.accessedAt.After(stabled) {
+			break
+		}
+
+		ok, err := e.evict(ctx, img)
+		if err != nil {
+			return fmt.Errorf("failed to evict %s@%s: %w", img.named, img.digest, err)
+		}
+
+		if !ok {
+			l.WithValues(
+				slog.String("name", img.named.String()),
+				slog.String("manifest", string(img.digest)),
+			).Debug("skipped for local tags")
+			continue
+		}
+
+		l.WithValues(
+			slog.String("name", img.named.String()),
+			slog.String("manifest", string(img.digest)),
+			slog.Time("accessedAt", img.accessedAt),
+		).Info("evicted")
+
+		used -= img.size
+		evicted++
+	}
+
+	if evicted == 0 {
+		return nil
+	}
+
+	if err := garbagecollector.MarkAndSweepExcludeModifiedIn(ctx, namespace, d, excludeAccessedIn, false); err != nil {
+		return err
+	}
+
+	return e.pruneBlobEntries(ctx)
+}
+
+// usage 缓存清单与缓存 Blob 的大小之和
+//
+// Blob 大小取自缓存清单的引用描述，未被任何缓存清单引用的 Blob（如直接拉取的 Blob）才读取存储中的大小
+func (e *evictor) usage(ctx context.Context, images []*image) (int64, error) {
+	used := int64(0)
+
+	for _, img := range images {
+		used += img.manifestSize
+	}
+
+	for _, entry := range e.blobEntries {
+		if size, ok := e.blobSizes[entry.Digest]; ok {
+			used += size
+			continue
+		}
+
+		info, err := e.driver.Stat(ctx, layout.Default.BlobDataPath(entry.Digest))
+		if err != nil {
+			if perr, ok := errors.AsType[*os.PathError](err); ok && os.IsNotExist(perr) {
+				continue
+			}
+			return 0, err
+		}
+
+		used += info.Size()
+	}
+
+	return used, nil
+}
+
+type image struct {
+	named      reference.Named
+	digest     digest.Digest
+	accessedAt time.Time
+	// 清单及其直接引用的 Blob 大小之和，用于估算淘汰后释放的用量
+	size int64
+	// 清单自身的大小
+	manifestSize int64
+}
+
+type evictor struct {
+	namespace content.Namespace
+	driver    driver.Driver
+	index     cacheindex.Index
+
+	blobEntries []cacheindex.Entry
+	// 缓存清单引用的内容的大小
+	blobSizes  map[digest.Digest]int64
+	tagEntries map[cachedTag]cacheindex.Entry
+}
+
+type cachedTag struct {
+	name   string
+	tag    string
+	digest digest.Digest
+}
+
+// images 返回缓存镜像，按访问时间从旧到新排列；镜像的访问时间取清单与其 Blob 中最近的访问时间
+func (e *evictor) images(ctx context.Context) ([]*image, error) {
+	blobAccessedAt := map[digest.Digest]time.Time{}
+	manifestEntries := make([]cacheindex.Entry, 0)
+
+	for entry, err := range e.index.Entries(ctx) {
+		if err != nil {
+			return nil, fmt.Errorf("failed to list cache index: %w", err)
+		}
+
+		switch entry.Kind {
+		case cacheindex.KindBlob:
+			blobAccessedAt[entry.Digest] = entry.AccessedAt
+			e.blobEntries = append(e.blobEntries, entry)
+		case cacheindex.KindManifest:
+			manifestEntries = append(manifestEntries, entry)
+		case cacheindex.KindTag:
+			if e.tagEntries == nil {
+				e.tagEntries = map[cachedTag]cacheindex.Entry{}
+			}
+			e.tagEntries[cachedTag{name: entry.Name, tag: entry.Tag, digest: entry.Digest}] = entry
+		}
+	}
+
+	images := make([]*image, 0, len(manifestEntries))
+
+	for _, entry := range manifestEntries {
+		named, err := reference.WithName(entry.Name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid repository name %q of cache index: %w", entry.Name, err)
+		}
+
+		img := &image{
+			named:      named,
+			digest:     entry.Digest,
+			accessedAt: entry.AccessedAt,
+		}
+
+		m, desc, err := e.manifest(ctx, named, entry.Digest)
+		if err != nil {
+			// 清单已被删除时移除标记
+			if _, ok := errors.AsType[*v2.ErrManifestUnknownRevision](err); ok {
+				if err := e.index.Remove(ctx, entry); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			return nil, err
+		}
+
+		img.size = desc.Size
+		img.manifestSize = desc.Size
+
+		for d := range m.References() {
+			img.size += d.Size
+
+			if e.blobSizes == nil {
+				e.blobSizes = map[digest.Digest]int64{}
+			}
+			e.blobSizes[d.Digest] = d.Size
+
+			if accessedAt, ok := blobAccessedAt[d.Digest]; ok && accessedAt.After(img.accessedAt) {
+				img.accessedAt = accessedAt
+			}
+		}
+
+		images = append(images, img)
+	}
+
+	slices.SortFunc(images, func(a, b *image) int {
+		return a.accessedAt.Compare(b.accessedAt)
+	})
+
+	return images, nil
+}
+
+func (e *evictor) manifest(ctx context.Context, named reference.Named, dgst digest.Digest) (manifestv1.Manifest, *manifestv1.Descriptor, error) {
+	repository, err := e.namespace.Repository(ctx, named)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	manifestService, err := repository.Manifests(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	desc, err := manifestService.Info(ctx, dgst)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	m, err := manifestService.Get(ctx, dgst)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return m, desc, nil
+}
+
+// evict 返回是否已淘汰，清单仍被本地创建的标签引用时不淘汰
+func (e *evictor) evict(ctx context.Context, img *image) (bool, error) {
+	repository, err := e.namespace.Repository(ctx, img.named)
+	if err != nil {
+		return false, err
+	}
+
+	tagService, err := repository.Tags(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	manifestService, err := repository.Manifests(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	tags, err := tagService.All(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	name := img.named.Name()
+	cachedTags := make([]string, 0, len(tags))
+
+	for _, tag := range tags {
+		d, err := tagService.Get(ctx, tag)
+		if err != nil {
+			return false, err
+		}
+
+		if d.Digest != img.digest {
+			continue
+		}
+
+		if _, ok := e.tagEntries[cachedTag{name: name, tag: tag, digest: img.digest}]; !ok {
+			return false, nil
+		}
+
+		cachedTags = append(cachedTags, tag)
+	}
+
+	for _, tag := range cachedTags {
+		if err := tagService.Untag(ctx, tag); err != nil {
+			return false, fmt.Errorf("failed to untag %s: %w", tag, err)
+		}
+	}
+
+	if err := manifestService.Delete(ctx, img.digest); err != nil {
+		return false, err
+	}
+
+	// 同时移除已指向其他清单的过期标签标记
+	for k, entry := range e.tagEntries {
+		if k.name == name && k.digest == img.digest {
+			if err := e.index.Remove(ctx, entry); err != nil {
+				return false, err
+			}
+			delete(e.tagEntries, k)
+		}
+	}
+
+	return true, e.index.Remove(ctx, cacheindex.Entry{
+		Kind:   cacheindex.KindManifest,
+		Name:   name,
+		Digest: img.digest,
+	})
+}
+
+// pruneBlobEntries 移除已被垃圾回收的 Blob 的标记
+func (e *evictor) pruneBlobEntries(ctx context.Context) error {
+	for _, entry := range e.blobEntries {
+		if _, err := e.driver.Stat(ctx, layout.Default.BlobDataPath(entry.Digest)); err != nil {
+			if perr, ok := errors.AsType[*os.PathError](err); ok && os.IsNotExist(perr) {
+				if err := e.index.Remove(ctx, entry); err != nil {
+					return err
+				}
+				continue
+			}
+			return err
+		}
+	}
+
+	return nil
+}
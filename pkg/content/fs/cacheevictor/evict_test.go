@@ -0,0 +1,184 @@
+package cacheevictor_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/distribution/reference"
+	"github.com/opencontainers/go-digest"
+
+	"github.com/octohelm/unifs/pkg/filesystem/local"
+	"github.com/octohelm/unifs/pkg/units"
+	. "github.com/octohelm/x/testing/v2"
+
+	manifestv1 "github.com/octohelm/crkit/pkg/apis/manifest/v1"
+	"github.com/octohelm/crkit/pkg/content"
+	"github.com/octohelm/crkit/pkg/content/cacheindex"
+	"github.com/octohelm/crkit/pkg/content/collect"
+	contentfs "github.com/octohelm/crkit/pkg/content/fs"
+	"github.com/octohelm/crkit/pkg/content/fs/cacheevictor"
+	driverfs "github.com/octohelm/crkit/pkg/driver/fs"
+	"github.com/octohelm/crkit/pkg/oci"
+	"github.com/octohelm/crkit/pkg/oci/random"
+	"github.com/octohelm/crkit/pkg/oci/remote"
+)
+
+func TestEvict(t *testing.T) {
+	ctx := t.Context()
+
+	tmp := t.TempDir()
+	d := driverfs.FromFileSystem(local.NewFS(tmp))
+	ns := contentfs.NewNamespace(d, contentfs.WithSparseManifests())
+	idx := cacheindex.New(d, cacheindex.DefaultDir, 0)
+
+	named := MustValue(t, func() (reference.Named, error) {
+		return reference.WithName("library/app")
+	})
+
+	repository := MustValue(t, func() (content.Repository, error) {
+		return ns.Repository(ctx, named)
+	})
+
+	tagService := MustValue(t, func() (content.TagService, error) {
+		return repository.Tags(ctx)
+	})
+
+	push := func(tag string) digest.Digest {
+		img := MustValue(t, func() (oci.Manifest, error) {
+			return random.Image(int64(512*units.KiB), 2)
+		})
+
+		return MustValue(t, func() (digest.Digest, error) {
+			if err := remote.Push(ctx, img, repository, tag); err != nil {
+				return "", err
+			}
+			desc, err := tagService.Get(ctx, tag)
+			if err != nil {
+				return "", err
+			}
+			return desc.Digest, nil
+		})
+	}
+
+	manifestService := MustValue(t, func() (content.ManifestService, error) {
+		return repository.Manifests(ctx)
+	})
+
+	// 模拟代理缓存，访问时间取索引文件的修改时间
+	cache := func(tag string, dgst digest.Digest, accessedAt time.Time) error {
+		m, err := manifestService.Get(ctx, dgst)
+		if err != nil {
+			return err
+		}
+		for d := range m.References() {
+			if err := idx.Record(ctx, cacheindex.Entry{Kind: cacheindex.KindBlob, Digest: d.Digest}); err != nil {
+				return err
+			}
+		}
+		if err := idx.Record(ctx, cacheindex.Entry{Kind: cacheindex.KindTag, Name: named.Name(), Tag: tag, Digest: dgst}); err != nil {
+			return err
+		}
+		if err := idx.Record(ctx, cacheindex.Entry{Kind: cacheindex.KindManifest, Name: named.Name(), Digest: dgst}); err != nil {
+			return err
+		}
+		p := filepath.Join(tmp, cacheindex.DefaultDir, "manifests", named.Name(), dgst.Algorithm().String(), dgst.Hex())
+		return os.Chtimes(p, accessedAt, accessedAt)
+	}
+
+	older := push("older")
+	newer := push("newer")
+	pushed := push("pushed")
+
+	Must(t, func() error {
+		if err := cache("older", older, time.Now().Add(-2*time.Hour)); err != nil {
+			return err
+		}
+		return cache("newer", newer, time.Now().Add(-1*time.Hour))
+	})
+
+	tags := func() ([]string, error) {
+		return tagService.All(ctx)
+	}
+
+	blobs := func() (int, error) {
+		blobs, err := collect.Blobs(ctx, ns)
+		return len(blobs), err
+	}
+
+	// 每个镜像约 1MiB，包含 1 个清单、1 个配置、2 个层
+	Then(t, "初始状态",
+		ExpectMustValue(tags, Equal([]string{"newer", "older", "pushed"})),
+		ExpectMustValue(blobs, Equal(12)),
+	)
+
+	t.Run("缓存用量未超出上限时不淘汰", func(t *testing.T) {
+		// 总用量约 3MiB，其中缓存约 2MiB，本地推送的内容不计入
+		Must(t, func() error {
+			return cacheevictor.Evict(ctx, ns, d, idx, int64(2500*units.KiB), 0)
+		})
+
+		Then(t, "保留所有镜像",
+			ExpectMustValue(tags, Equal([]string{"newer", "older", "pushed"})),
+			ExpectMustValue(blobs, Equal(12)),
+		)
+	})
+
+	t.Run("超出上限时淘汰最久未访问的缓存镜像", func(t *testing.T) {
+		Must(t, func() error {
+			return cacheevictor.Evict(ctx, ns, d, idx, int64(1500*units.KiB), 0)
+		})
+
+		Then(t, "仅淘汰 older，同时移除其标签与 Blob 的标记",
+			ExpectMustValue(tags, Equal([]string{"newer", "pushed"})),
+			ExpectMustValue(blobs, Equal(8)),
+			ExpectMustValue(func() (int, error) {
+				n := 0
+				for _, err := range idx.Entries(ctx) {
+					if err != nil {
+						return 0, err
+					}
+					n++
+				}
+				return n, nil
+			}, Equal(5)),
+		)
+	})
+
+	t.Run("被本地标签引用的缓存镜像不被淘汰", func(t *testing.T) {
+		Must(t, func() error {
+			return tagService.Tag(ctx, "pinned", manifestv1.Descriptor{Digest: newer})
+		})
+
+		Must(t, func() error {
+			return cacheevictor.Evict(ctx, ns, d, idx, 0, 0)
+		})
+
+		Then(t, "保留 newer 及其标签",
+			ExpectMustValue(tags, Equal([]string{"newer", "pinned", "pushed"})),
+			ExpectMustValue(blobs, Equal(8)),
+		)
+	})
+
+	t.Run("本地推送的镜像不被淘汰", func(t *testing.T) {
+		Must(t, func() error {
+			if err := tagService.Untag(ctx, "pinned"); err != nil {
+				return err
+			}
+			return cacheevictor.Evict(ctx, ns, d, idx, 0, 0)
+		})
+
+		Then(t, "仅保留 pushed",
+			ExpectMustValue(tags, Equal([]string{"pushed"})),
+			ExpectMustValue(blobs, Equal(4)),
+			ExpectMustValue(func() (digest.Digest, error) {
+				desc, err := tagService.Get(ctx, "pushed")
+				if err != nil {
+					return "", err
+				}
+				return desc.Digest, nil
+			}, Equal(pushed)),
+		)
+	})
+}
@@ -1,7 +1,16 @@
 package fs
 
 import (
+	"context"
+	"io"
+	"io/fs"
+	"iter"
+	"os"
+	"sync"
+	"time"
+
 	"github.com/octohelm/crkit/pkg/content/fs/layout"
+	"github.com/octohelm/crkit/pkg/content/quota"
 	"github.com/octohelm/crkit/pkg/driver"
 )
 
@@ -16,4 +25,91 @@ type workspace struct {
 	driver.Driver
 
 	layout layout.Layout
+	quota  quota.Quota
+
+	// 预签名下载地址的有效期，为 0 时不提供
+	presignExpires time.Duration
+
+	// 存储不支持条件写入时在进程内串行化 PutContentIfAbsent
+	exclusive sync.Mutex
+}
+
+// PutContentIfAbsent 仅在文件不存在时写入，文件已存在时返回 fs.ErrExist；
+// 存储不支持条件写入时退化为进程内的检查后写入
+func (w *workspace) PutContentIfAbsent(ctx context.Context, pathname string, data []byte) error {
+	if ew, ok := w.Driver.(driver.ExclusiveWriter); ok {
+		return ew.PutContentIfAbsent(ctx, pathname, data)
+	}
+
+	w.exclusive.Lock()
+	defer w.exclusive.Unlock()
+
+	if _, err := w.Stat(ctx, pathname); err == nil {
+		return &os.PathError{Op: "put", Path: pathname, Err: os.ErrExist}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	return w.PutContent(ctx, pathname, data)
+}
+
+// ListDir 列出目录下名称大于 after 的直接子项
+func (w *workspace) ListDir(ctx context.Context, pathname string, after string) iter.Seq2[fs.DirEntry, error] {
+	if lister, ok := w.Driver.(driver.DirLister); ok {
+		return lister.ListDir(ctx, pathname, after)
+	}
+
+	return func(yield func(fs.DirEntry, error) bool) {
+		if err := w.WalkDir(ctx, pathname, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if p == "." {
+				return nil
+			}
+
+			if d.Name() > after {
+				if !yield(d, nil) {
+					return fs.SkipAll
+				}
+			}
+
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}); err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// ReadRange 读取文件从 offset 开始的 length 字节，length < 0 时读取至文件末尾
+func (w *workspace) ReadRange(ctx context.Context, pathname string, offset int64, length int64) (io.ReadCloser, error) {
+	if rr, ok := w.Driver.(driver.RangeReader); ok {
+		return rr.ReadRange(ctx, pathname, offset, length)
+	}
+
+	r, err := w.Reader(ctx, pathname)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := io.CopyN(io.Discard, r, offset); err != nil {
+		_ = r.Close()
+		return nil, err
+	}
+
+	if length < 0 {
+		return r, nil
+	}
+
+	return &struct {
+		io.Reader
+		io.Closer
+	}{
+		Reader: io.LimitReader(r, length),
+		Closer: r,
+	}, nil
 }
@@ -15,6 +15,7 @@ import (
 	manifestv1 "github.com/octohelm/crkit/pkg/apis/manifest/v1"
 	"github.com/octohelm/crkit/pkg/apis/registry/v2"
 	"github.com/octohelm/crkit/pkg/content"
+	"github.com/octohelm/crkit/pkg/content/quota"
 	"github.com/octohelm/crkit/pkg/driver"
 )
 
@@ -48,6 +49,11 @@ func MarkAndSweepExcludeModifiedIn(
 		},
 	}
 
+	// 清理后按保留的关联校正配额用量
+	if q, ok := quota.QuotaFromContext(ctx); ok && !dryRun {
+		c.quota = q
+	}
+
 	return c.MarkAndSweep(ctx, repositoryNameIterable, blobDigestIterable)
 }
 
@@ -59,6 +65,28 @@ type collector struct {
 
 	blobUsed map[digest.Digest]struct{}
 	refUsed  map[string]map[digest.Digest]struct{}
+
+	quota   quota.Quota
+	account quota.AccountFunc
+}
+
+func (c *collector) accountLayer(ctx context.Context, named reference.Named, blobStore content.BlobStore, dgst digest.Digest) error {
+	if c.account == nil {
+		return nil
+	}
+
+	d, err := blobStore.Info(ctx, dgst)
+	if err != nil {
+		// skip for partial cached
+		if _, ok := errors.AsType[*v2.ErrBlobUnknown](err); ok {
+			return nil
+		}
+		return err
+	}
+
+	c.account(named, dgst, d.Size)
+
+	return nil
 }
 
 func (c *collector) mark(named reference.Named, dgst digest.Digest) {
@@ -101,14 +129,29 @@ func (c *collector) MarkAndSweep(pctx context.Context, repositoryNameIterable co
 	ctx, l := logr.FromContext(pctx).Start(pctx, "MarkAndSweep")
 	defer l.End()
 
-	for named, err := range repositoryNameIterable.RepositoryNames(ctx) {
-		if err != nil {
-			return err
+	markAndSweepRepositories := func(ctx context.Context) error {
+		for named, err := range repositoryNameIterable.RepositoryNames(ctx) {
+			if err != nil {
+				return err
+			}
+
+			if err := c.markAndSweepRepository(ctx, named); err != nil {
+				return fmt.Errorf("failed to mark and sweep repository %s: %w", named, err)
+			}
 		}
+		return nil
+	}
 
-		if err := c.markAndSweepRepository(ctx, named); err != nil {
-			return fmt.Errorf("failed to mark and sweep repository %s: %w", named, err)
+	if c.quota != nil {
+		// 扫描与校正同时进行，扫描期间的关联变更由配额记录
+		if err := c.quota.Reconcile(ctx, func(ctx context.Context, account quota.AccountFunc) error {
+			c.account = account
+			return markAndSweepRepositories(ctx)
+		}); err != nil {
+			return err
 		}
+	} else if err := markAndSweepRepositories(ctx); err != nil {
+		return err
 	}
 
 	for d, err := range blobDigestIterable.Digests(ctx) {
@@ -218,6 +261,9 @@ func (c *collector) markAndSweepRepository(ctx context.Context, named reference.
 		).Debug("checking")
 
 		if c.referencedOrRecentlyActivated(named, ld) {
+			if err := c.accountLayer(ctx, named, blobStore, ld.Digest); err != nil {
+				return fmt.Errorf("failed to account layer %s@%s: %w", named, ld.Digest, err)
+			}
 			continue
 		}
 
@@ -258,5 +304,18 @@ func (c *collector) markManifest(ctx context.Context, named reference.Named, man
 		}
 	}
 
+	// referrers (signatures, sboms) follow lifecycle of subject
+	if referrersIterable, ok := manifestService.(content.ReferrersIterable); ok {
+		for d, err := range referrersIterable.Referrers(ctx, manifestDigest) {
+			if err != nil {
+				return err
+			}
+
+			if err := c.markManifest(ctx, named, manifestService, d.Digest); err != nil {
+				return err
+			}
+		}
+	}
+
 	return nil
 }
@@ -12,6 +12,7 @@ import (
 	"github.com/octohelm/x/sync/singleflight"
 
 	"github.com/octohelm/crkit/pkg/content"
+	"github.com/octohelm/crkit/pkg/content/quota"
 	"github.com/octohelm/crkit/pkg/driver"
 )
 
@@ -22,6 +23,7 @@ type Executor struct {
 
 	driver    driver.Driver     `inject:",opt"`
 	namespace content.Namespace `inject:",opt"`
+	quota     quota.Quota       `inject:",opt"`
 }
 
 func (a *Executor) SetDefaults() {
@@ -35,6 +37,10 @@ func (gc *Executor) Run(ctx context.Context) error {
 		return nil
 	}
 
+	if gc.quota != nil {
+		ctx = quota.QuotaInjectContext(ctx, gc.quota)
+	}
+
 	return MarkAndSweepExcludeModifiedIn(
 		ctx,
 		gc.namespace,
@@ -53,6 +59,7 @@ type GarbageCollector struct {
 
 	driver    driver.Driver     `inject:",opt"`
 	namespace content.Namespace `inject:",opt"`
+	quota     quota.Quota       `inject:",opt"`
 }
 
 func (a *GarbageCollector) Disabled(ctx context.Context) bool {
@@ -99,5 +106,9 @@ func (a *GarbageCollector) afterInit(ctx context.Context) error {
 }
 
 func (a *GarbageCollector) MarkAndSweepExcludeModifiedIn(ctx context.Context, hour time.Duration) error {
+	if a.quota != nil {
+		ctx = quota.QuotaInjectContext(ctx, a.quota)
+	}
+
 	return MarkAndSweepExcludeModifiedIn(ctx, a.namespace, a.driver, hour, false)
 }
@@ -14,6 +14,7 @@ import (
 	"github.com/octohelm/x/logr"
 
 	"github.com/octohelm/crkit/pkg/content/fs/layout"
+	"github.com/octohelm/crkit/pkg/content/metrics"
 	"github.com/octohelm/crkit/pkg/driver"
 )
 
@@ -100,8 +101,19 @@ func (v *vacuum) RemoveBlob(ctx context.Context, dgst digest.Digest) error {
 		return fmt.Errorf("invalid digest: %s %w", dgst, err)
 	}
 
+	size := int64(0)
+	if info, err := v.driver.Stat(ctx, v.layout.BlobDataPath(dgst)); err == nil {
+		size = info.Size()
+	}
+
 	// delete blobs/{algorithm}/{hex_digest_prefix_2}/{hex_digest}/data
-	return v.driver.Delete(ctx, path.Dir(v.layout.BlobDataPath(dgst)))
+	if err := v.driver.Delete(ctx, path.Dir(v.layout.BlobDataPath(dgst))); err != nil {
+		return err
+	}
+
+	metrics.RecordGCFreed(ctx, size)
+
+	return nil
 }
 
 func (v *vacuum) RemoveLayer(ctx context.Context, named reference.Named, dgst digest.Digest) error {
@@ -137,6 +149,18 @@ func (v *vacuum) RemoveManifest(ctx context.Context, named reference.Named, dgst
 		}
 	}
 
+	// delete repositories/{named}/_manifests/referrers/{algorithm}/{hex_subject_digest}
+	referrersPath := v.layout.RepositoryManifestReferrersPath(named, dgst)
+	if _, err := v.driver.Stat(ctx, referrersPath); err != nil {
+		if perr, ok := errors.AsType[*os.PathError](err); !ok || !os.IsNotExist(perr) {
+			return err
+		}
+	} else {
+		if err := v.driver.Delete(ctx, referrersPath); err != nil {
+			return err
+		}
+	}
+
 	// delete repositories/{named}/_manifests/revisions/{algorithm}/{hex_digest}
 	return v.driver.Delete(ctx, v.layout.RepositoryManifestRevisionPath(named, dgst))
 }
@@ -5,6 +5,7 @@ import (
 	context "context"
 
 	content "github.com/octohelm/crkit/pkg/content"
+	contentquota "github.com/octohelm/crkit/pkg/content/quota"
 	pkgdriver "github.com/octohelm/crkit/pkg/driver"
 )
 
@@ -15,6 +16,9 @@ func (v *Executor) Init(ctx context.Context) error {
 	if value, ok := content.NamespaceFromContext(ctx); ok {
 		v.namespace = value
 	}
+	if value, ok := contentquota.QuotaFromContext(ctx); ok {
+		v.quota = value
+	}
 
 	return nil
 }
@@ -26,6 +30,9 @@ func (v *GarbageCollector) Init(ctx context.Context) error {
 	if value, ok := content.NamespaceFromContext(ctx); ok {
 		v.namespace = value
 	}
+	if value, ok := contentquota.QuotaFromContext(ctx); ok {
+		v.quota = value
+	}
 	if err := v.Agent.Init(ctx); err != nil {
 		return err
 	}
@@ -84,6 +84,18 @@ func (b Layout) RepositoryManifestRevisionLinkPath(name reference.Named, dgst di
 	return path.Join(b.RepositoryManifestRevisionPath(name, dgst), "link")
 }
 
+// RepositoryManifestReferrersPath
+// repositories/{name}/_manifests/referrers/{algorithm}/{hex_subject_digest}
+func (b Layout) RepositoryManifestReferrersPath(name reference.Named, subject digest.Digest) string {
+	return path.Join(b.RepositoryPath(name), "_manifests", "referrers", subject.Algorithm().String(), subject.Hex())
+}
+
+// RepositoryManifestReferrerLinkPath
+// repositories/{name}/_manifests/referrers/{algorithm}/{hex_subject_digest}/{algorithm}/{hex_digest}/link
+func (b Layout) RepositoryManifestReferrerLinkPath(name reference.Named, subject digest.Digest, dgst digest.Digest) string {
+	return path.Join(b.RepositoryManifestReferrersPath(name, subject), dgst.Algorithm().String(), dgst.Hex(), "link")
+}
+
 // RepositoryManifestTagsPath
 // repositories/{name}/_manifests/tags
 func (b Layout) RepositoryManifestTagsPath(name reference.Named) string {
@@ -2,19 +2,28 @@ package fs
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"iter"
 
+	"github.com/distribution/reference"
 	"github.com/opencontainers/go-digest"
 
 	manifestv1 "github.com/octohelm/crkit/pkg/apis/manifest/v1"
+	"github.com/octohelm/crkit/pkg/apis/registry/v2"
 	"github.com/octohelm/crkit/pkg/content"
 )
 
 var _ content.ManifestService = &manifestService{}
 
 type manifestService struct {
+	workspace *workspace
+	named     reference.Named
 	blobStore *linkedBlobStore
+	layers    *linkedBlobStore
+	// sparse 为 true 时不校验清单引用
+	sparse bool
 }
 
 var _ content.LinkedDigestIterable = &manifestService{}
@@ -23,6 +32,50 @@ func (m *manifestService) LinkedDigests(ctx context.Context) iter.Seq2[content.L
 	return m.blobStore.LinkedDigests(ctx)
 }
 
+var _ content.ReferrersIterable = &manifestService{}
+
+func (m *manifestService) Referrers(ctx context.Context, subject digest.Digest) iter.Seq2[manifestv1.Descriptor, error] {
+	return func(yield func(manifestv1.Descriptor, error) bool) {
+		for ld, err := range newLinkedBlobStoreForReferrers(m.workspace, m.named, subject).LinkedDigests(ctx) {
+			if err != nil {
+				yield(manifestv1.Descriptor{}, err)
+				return
+			}
+
+			d, err := m.referrer(ctx, ld.Digest)
+			if err != nil {
+				// skip referrer which manifest revision removed
+				if _, ok := errors.AsType[*v2.ErrManifestUnknownRevision](err); ok {
+					continue
+				}
+				if !yield(manifestv1.Descriptor{}, err) {
+					return
+				}
+				continue
+			}
+
+			if !yield(*d, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (m *manifestService) referrer(ctx context.Context, dgst digest.Digest) (*manifestv1.Descriptor, error) {
+	info, err := m.Info(ctx, dgst)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := m.Get(ctx, dgst)
+	if err != nil {
+		return nil, err
+	}
+
+	d := manifestv1.ReferrerDescriptor(manifest, *info)
+	return &d, nil
+}
+
 func (m *manifestService) Delete(ctx context.Context, dgst digest.Digest) error {
 	return m.blobStore.Remove(ctx, dgst)
 }
@@ -58,12 +111,18 @@ func (m *manifestService) Get(ctx context.Context, dgst digest.Digest) (manifest
 func (m *manifestService) Put(ctx context.Context, manifest manifestv1.Manifest) (digest.Digest, error) {
 	payload, err := manifestv1.From(manifest)
 	if err != nil {
-		return "", nil
+		return "", err
 	}
 
 	raw, dgst, err := payload.Payload()
 	if err != nil {
-		return "", nil
+		return "", err
+	}
+
+	if !m.sparse {
+		if err := m.verifyReferences(ctx, payload); err != nil {
+			return "", err
+		}
 	}
 
 	w, err := m.blobStore.Writer(ctx)
@@ -83,5 +142,58 @@ func (m *manifestService) Put(ctx context.Context, manifest manifestv1.Manifest)
 		return "", err
 	}
 
+	if subject := manifestv1.SubjectOf(payload); subject != nil {
+		// record referrer of subject
+		if err := m.workspace.PutContent(
+			ctx,
+			m.workspace.layout.RepositoryManifestReferrerLinkPath(m.named, subject.Digest, d.Digest),
+			[]byte(d.Digest),
+		); err != nil {
+			return "", err
+		}
+	}
+
 	return d.Digest, nil
 }
+
+// verifyReferences 校验 Index 引用的子清单已存在，镜像清单引用的 Blob 已关联到仓库
+//
+// 仅存在于 Blob 存储而未关联到仓库的 Blob 同样拒绝，需经授权的挂载（mount）或上传关联；
+// 否则知晓摘要即可越权引用其他仓库的内容
+func (m *manifestService) verifyReferences(ctx context.Context, manifest manifestv1.Manifest) error {
+	switch manifest.Type() {
+	case manifestv1.MediaTypeImageIndex, manifestv1.DockerMediaTypeManifestList:
+		for d := range manifest.References() {
+			if _, err := m.Info(ctx, d.Digest); err != nil {
+				if _, ok := errors.AsType[*v2.ErrManifestUnknownRevision](err); ok {
+					return &v2.ErrManifestBlobUnknown{
+						Name:   m.named.Name(),
+						Digest: d.Digest,
+					}
+				}
+				return err
+			}
+		}
+		return nil
+	}
+
+	for d := range manifest.References() {
+		// skip foreign layer
+		if len(d.URLs) > 0 {
+			continue
+		}
+
+		info, err := m.layers.Info(ctx, d.Digest)
+		if err != nil {
+			return err
+		}
+
+		if d.Size != info.Size {
+			return &v2.ErrManifestUnverified{
+				Reason: fmt.Errorf("size of %s mismatched, declared %d, but got %d", d.Digest, d.Size, info.Size),
+			}
+		}
+	}
+
+	return nil
+}
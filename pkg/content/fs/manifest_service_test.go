@@ -0,0 +1,145 @@
+package fs
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"testing"
+
+	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/octohelm/unifs/pkg/filesystem/local"
+	. "github.com/octohelm/x/testing/v2"
+
+	manifestv1 "github.com/octohelm/crkit/pkg/apis/manifest/v1"
+	"github.com/octohelm/crkit/pkg/apis/registry/v2"
+	"github.com/octohelm/crkit/pkg/content"
+	driverfs "github.com/octohelm/crkit/pkg/driver/fs"
+)
+
+func TestManifestService(t *testing.T) {
+	tmp := t.TempDir()
+	t.Cleanup(func() {
+		_ = os.RemoveAll(tmp)
+	})
+
+	d := driverfs.FromFileSystem(local.NewFS(tmp))
+
+	image := &manifestv1.OciManifest{
+		MediaType: manifestv1.MediaTypeImageManifest,
+		Config:    ocispecv1.DescriptorEmptyJSON,
+		Layers:    []ocispecv1.Descriptor{ocispecv1.DescriptorEmptyJSON},
+	}
+	image.SchemaVersion = 2
+
+	put := func(ctx context.Context, ns content.Namespace, name string, m manifestv1.Manifest) error {
+		repo, err := ns.Repository(ctx, v2.Name(name))
+		if err != nil {
+			return err
+		}
+		manifests, err := repo.Manifests(ctx)
+		if err != nil {
+			return err
+		}
+		_, err = manifests.Put(ctx, m)
+		return err
+	}
+
+	t.Run("GIVEN a namespace", func(t *testing.T) {
+		ns := NewNamespace(d)
+
+		Then(
+			t, "reject manifest which references unknown blob",
+			ExpectDo(
+				func() error {
+					return put(t.Context(), ns, "test/app", image)
+				},
+				ErrorMatch(regexp.MustCompile("unknown manifest")),
+			),
+		)
+
+		t.Run("WHEN blob pushed to another repository", func(t *testing.T) {
+			MustValue(t, func() (*manifestv1.Descriptor, error) {
+				repo, err := ns.Repository(t.Context(), v2.Name("test/base"))
+				if err != nil {
+					return nil, err
+				}
+				blobs, err := repo.Blobs(t.Context())
+				if err != nil {
+					return nil, err
+				}
+				w, err := blobs.Writer(t.Context())
+				if err != nil {
+					return nil, err
+				}
+				defer w.Close()
+
+				if _, err := w.Write(ocispecv1.DescriptorEmptyJSON.Data); err != nil {
+					return nil, err
+				}
+				return w.Commit(t.Context(), ocispecv1.DescriptorEmptyJSON)
+			})
+
+			Then(
+				t, "reject manifest which references blob not linked to the repository",
+				ExpectDo(
+					func() error {
+						return put(t.Context(), ns, "test/app", image)
+					},
+					ErrorMatch(regexp.MustCompile("unknown")),
+				),
+			)
+
+			Then(
+				t, "manifest accepted after blob mounted",
+				ExpectMust(func() error {
+					repo, err := ns.Repository(t.Context(), v2.Name("test/app"))
+					if err != nil {
+						return err
+					}
+					blobs, err := repo.Blobs(t.Context())
+					if err != nil {
+						return err
+					}
+					if _, err := blobs.(content.Mounter).Mount(t.Context(), v2.Name("test/base"), ocispecv1.DescriptorEmptyJSON.Digest); err != nil {
+						return err
+					}
+					return put(t.Context(), ns, "test/app", image)
+				}),
+			)
+
+			index := &manifestv1.OciIndex{
+				MediaType: manifestv1.MediaTypeImageIndex,
+				Manifests: []ocispecv1.Descriptor{
+					{
+						MediaType: manifestv1.MediaTypeImageManifest,
+						Digest:    ocispecv1.DescriptorEmptyJSON.Digest,
+						Size:      ocispecv1.DescriptorEmptyJSON.Size,
+					},
+				},
+			}
+			index.SchemaVersion = 2
+
+			Then(
+				t, "reject index which references unknown manifest",
+				ExpectDo(
+					func() error {
+						return put(t.Context(), ns, "test/app", index)
+					},
+					ErrorMatch(regexp.MustCompile("unknown manifest")),
+				),
+			)
+		})
+	})
+
+	t.Run("GIVEN a namespace with sparse manifests", func(t *testing.T) {
+		ns := NewNamespace(d, WithSparseManifests())
+
+		Then(
+			t, "manifest stored without references",
+			ExpectMust(func() error {
+				return put(t.Context(), ns, "test/sparse", image)
+			}),
+		)
+	})
+}
@@ -2,32 +2,70 @@ package fs
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/fs"
 	"iter"
+	"os"
 	"path"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/distribution/reference"
 	"github.com/opencontainers/go-digest"
 
 	"github.com/octohelm/crkit/pkg/content"
 	"github.com/octohelm/crkit/pkg/content/fs/layout"
+	"github.com/octohelm/crkit/pkg/content/quota"
 	"github.com/octohelm/crkit/pkg/driver"
 )
 
-func NewNamespace(d driver.Driver) content.Namespace {
-	return &namespace{workspace: newWorkspace(d, layout.Default)}
+type Option func(n *namespace)
+
+// WithSparseManifests 允许存储引用不完整的清单
+//
+// 用于按需回源的代理缓存，清单先于其引用的 Blob 写入本地
+func WithSparseManifests() Option {
+	return func(n *namespace) {
+		n.sparseManifests = true
+	}
+}
+
+// WithQuota 仓库关联 Blob 时计入并校验存储配额
+func WithQuota(q quota.Quota) Option {
+	return func(n *namespace) {
+		n.workspace.quota = q
+	}
+}
+
+// WithPresignedURL 存储支持预签名时，为 Blob 提供 expires 内有效的直接下载地址
+func WithPresignedURL(expires time.Duration) Option {
+	return func(n *namespace) {
+		n.workspace.presignExpires = expires
+	}
+}
+
+func NewNamespace(d driver.Driver, options ...Option) content.Namespace {
+	n := &namespace{workspace: newWorkspace(d, layout.Default)}
+
+	for _, opt := range options {
+		opt(n)
+	}
+
+	return n
 }
 
 type namespace struct {
-	workspace *workspace
+	workspace       *workspace
+	sparseManifests bool
 }
 
 func (n *namespace) Repository(ctx context.Context, named reference.Named) (content.Repository, error) {
 	return &repository{
-		named:     named,
-		workspace: n.workspace,
+		named:           named,
+		workspace:       n.workspace,
+		sparseManifests: n.sparseManifests,
 	}, nil
 }
 
@@ -82,3 +120,84 @@ func (n *namespace) RepositoryNames(ctx context.Context) iter.Seq2[reference.Nam
 		}
 	}
 }
+
+var _ content.RepositoryNameAfterIterable = (*namespace)(nil)
+
+// RepositoryNamesAfter 按字节序遍历仓库名称，仅返回排在 last 之后的仓库
+func (n *namespace) RepositoryNamesAfter(ctx context.Context, last string) iter.Seq2[reference.Named, error] {
+	return func(yield func(reference.Named, error) bool) {
+		if _, err := n.walkRepositoryNames(ctx, "", last, yield); err != nil {
+			// skip base dir not exists
+			if perr, ok := errors.AsType[*os.PathError](err); ok {
+				if os.IsNotExist(perr) {
+					return
+				}
+			}
+			yield(nil, err)
+		}
+	}
+}
+
+// walkRepositoryNames 按字节序遍历 name 下的仓库名称
+//
+// 子仓库 {p}/... 排在 {p}/ 处，晚于同级的 {p}-x、{p}.x，因此各子树延后至排到时再遍历；
+// 全部排在 last 之前的子树直接跳过
+func (n *namespace) walkRepositoryNames(ctx context.Context, name string, last string, yield func(reference.Named, error) bool) (bool, error) {
+	dir := path.Join(n.workspace.layout.RepositorysPath(), name)
+
+	prefix := ""
+	if name != "" {
+		prefix = name + "/"
+	}
+
+	// 待遍历的子树 {p}/，按字节序排列
+	pending := make([]string, 0)
+
+	walkPending := func(before string) (bool, error) {
+		for len(pending) > 0 && (before == "" || pending[0] < before) {
+			sub := pending[0]
+			pending = pending[1:]
+
+			next, err := n.walkRepositoryNames(ctx, strings.TrimSuffix(sub, "/"), last, yield)
+			if err != nil || !next {
+				return next, err
+			}
+		}
+		return true, nil
+	}
+
+	for d, err := range n.workspace.ListDir(ctx, dir, "") {
+		if err != nil {
+			return false, err
+		}
+
+		if !d.IsDir() || strings.HasPrefix(d.Name(), "_") {
+			continue
+		}
+
+		current := prefix + d.Name()
+
+		if next, err := walkPending(current); err != nil || !next {
+			return next, err
+		}
+
+		if current > last {
+			if _, err := n.workspace.Stat(ctx, path.Join(dir, d.Name(), "_manifests")); err == nil {
+				named, err := reference.WithName(current)
+				if err != nil {
+					return false, fmt.Errorf("failed to parse repository name %q: %w", current, err)
+				}
+				if !yield(named, nil) {
+					return false, nil
+				}
+			}
+		}
+
+		if sub := current + "/"; sub > last || strings.HasPrefix(last, sub) {
+			i, _ := slices.BinarySearch(pending, sub)
+			pending = slices.Insert(pending, i, sub)
+		}
+	}
+
+	return walkPending("")
+}
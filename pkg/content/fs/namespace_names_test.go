@@ -0,0 +1,49 @@
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/octohelm/unifs/pkg/filesystem/local"
+	. "github.com/octohelm/x/testing/v2"
+
+	"github.com/octohelm/crkit/pkg/content/collect"
+	"github.com/octohelm/crkit/pkg/content/fs/layout"
+	driverfs "github.com/octohelm/crkit/pkg/driver/fs"
+)
+
+func TestRepositoryNamesAfter(t *testing.T) {
+	tmp := t.TempDir()
+
+	// 子仓库与以 - . 延续名称的同级仓库交错
+	names := []string{"a", "a/b", "a/b/c", "a/b-x", "a-c", "a-c/z", "a.d/e", "ab", "b/c"}
+
+	Must(t, func() error {
+		for _, name := range names {
+			if err := os.MkdirAll(filepath.Join(tmp, layout.Default.RepositorysPath(), name, "_manifests"), 0o755); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	ns := NewNamespace(driverfs.FromFileSystem(local.NewFS(tmp)))
+
+	sorted := slices.Sorted(slices.Values(names))
+
+	Then(t, "按字节序返回，与 Catalogs 排序后的结果一致",
+		ExpectMustValue(func() ([]string, error) {
+			return collect.RepositoryNames(t.Context(), ns, "", 0)
+		}, Equal(sorted)),
+	)
+
+	for i, last := range sorted[:len(sorted)-1] {
+		Then(t, "从 "+last+" 之后继续",
+			ExpectMustValue(func() ([]string, error) {
+				return collect.RepositoryNames(t.Context(), ns, last, 0)
+			}, Equal(sorted[i+1:])),
+		)
+	}
+}
@@ -57,11 +57,19 @@ func FuzzNamespaceS3(f *testing.F) {
 			return namespaceS3Image(layerSize, layerCount, seed)
 		})
 
-		testNamespaceS3(t, image)
+		testNamespaceS3(t, image, false)
 	})
 }
 
-func testNamespaceS3(t *testing.T, image oci.Image) {
+func TestNamespaceS3BlobRedirect(t *testing.T) {
+	image := MustValue(t, func() (oci.Image, error) {
+		return namespaceS3Image(1024, 2, 7)
+	})
+
+	testNamespaceS3(t, image, true)
+}
+
+func testNamespaceS3(t *testing.T, image oci.Image, blobRedirect bool) {
 	s3Server := newNamespaceFakeS3Server(t)
 
 	ctx, _ := testingutil.BuildContext(t, func(d *struct {
@@ -70,6 +78,7 @@ func testNamespaceS3(t *testing.T, image oci.Image) {
 	},
 	) {
 		d.Content.Backend = endpointForNamespaceS3Server(t, s3Server, "/"+namespaceS3Bucket+"/content")
+		d.BlobRedirect = blobRedirect
 	})
 
 	s := MustValue(t, func() (*httptest.Server, error) {
@@ -185,6 +194,33 @@ func testNamespaceS3(t *testing.T, image oci.Image) {
 		),
 	)
 
+	if blobRedirect {
+		Then(
+			t, "Blob 下载重定向到 S3 预签名地址",
+			ExpectMustValue(
+				func() (int, error) {
+					c := &http.Client{
+						CheckRedirect: func(req *http.Request, via []*http.Request) error {
+							return http.ErrUseLastResponse
+						},
+					}
+
+					resp, err := c.Get(fmt.Sprintf("%s/v2/%s/blobs/%s", s.URL, remoteRepo.Named().Name(), layer.Digest))
+					if err != nil {
+						return 0, err
+					}
+					defer resp.Body.Close()
+
+					if !strings.HasPrefix(resp.Header.Get("Location"), s3Server.URL) {
+						return 0, fmt.Errorf("unexpected location %q", resp.Header.Get("Location"))
+					}
+					return resp.StatusCode, nil
+				},
+				Equal(http.StatusTemporaryRedirect),
+			),
+		)
+	}
+
 	imagePushed := MustValue(t, func() (oci.Manifest, error) {
 		return remote.Manifest(ctx, remoteRepo, "latest")
 	})
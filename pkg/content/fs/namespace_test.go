@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/distribution/reference"
+	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
 
 	"github.com/innoai-tech/infra/pkg/configuration"
 	"github.com/innoai-tech/infra/pkg/configuration/testingutil"
@@ -18,6 +19,7 @@ import (
 	"github.com/octohelm/unifs/pkg/units"
 	. "github.com/octohelm/x/testing/v2"
 
+	manifestv1 "github.com/octohelm/crkit/pkg/apis/manifest/v1"
 	"github.com/octohelm/crkit/pkg/content"
 	contentapi "github.com/octohelm/crkit/pkg/content/api"
 	"github.com/octohelm/crkit/pkg/content/collect"
@@ -118,6 +120,17 @@ func FuzzNamespace(f *testing.F) {
 					),
 				)
 
+				Then(
+					t, "从当前仓库之后分页列出仓库为空",
+					ExpectMustValue(
+						func() (int, error) {
+							names, err := collect.RepositoryNames(ctx, ns, remoteRepo.Named().Name(), 10)
+							return len(names), err
+						},
+						Equal(0),
+					),
+				)
+
 				Then(
 					t, "验证推送的manifest数量",
 					ExpectMustValue(
@@ -139,6 +152,80 @@ func FuzzNamespace(f *testing.F) {
 					),
 				)
 
+				t.Run("WHEN 推送以镜像为 subject 的签名清单", func(t *testing.T) {
+					subject := MustValue(t, func() (ocispecv1.Descriptor, error) {
+						return image.Descriptor(ctx)
+					})
+
+					manifests := MustValue(t, func() (content.ManifestService, error) {
+						return remoteRepo.Manifests(ctx)
+					})
+
+					signature := &manifestv1.OciManifest{
+						MediaType:    manifestv1.MediaTypeImageManifest,
+						ArtifactType: "application/vnd.example.signature.v1+json",
+						Config:       ocispecv1.DescriptorEmptyJSON,
+						Layers:       []ocispecv1.Descriptor{ocispecv1.DescriptorEmptyJSON},
+						Subject:      &subject,
+					}
+					signature.SchemaVersion = 2
+
+					Then(
+						t, "推送签名清单引用的空 Blob",
+						ExpectDo(
+							func() error {
+								blobs, err := remoteRepo.Blobs(ctx)
+								if err != nil {
+									return err
+								}
+								w, err := blobs.Writer(ctx)
+								if err != nil {
+									return err
+								}
+								defer w.Close()
+
+								if _, err := w.Write(ocispecv1.DescriptorEmptyJSON.Data); err != nil {
+									return err
+								}
+								_, err = w.Commit(ctx, ocispecv1.DescriptorEmptyJSON)
+								return err
+							},
+						),
+					)
+
+					Then(
+						t, "成功推送签名清单",
+						ExpectDo(
+							func() error {
+								_, err := manifests.Put(ctx, signature)
+								return err
+							},
+						),
+					)
+
+					Then(
+						t, "可查询到 subject 的 referrer",
+						ExpectMustValue(
+							func() (int, error) {
+								referrers, err := collect.Referrers(ctx, manifests, subject.Digest, "")
+								return len(referrers), err
+							},
+							Equal(1),
+						),
+					)
+
+					Then(
+						t, "按 artifactType 过滤 referrer",
+						ExpectMustValue(
+							func() (int, error) {
+								referrers, err := collect.Referrers(ctx, manifests, subject.Digest, "application/vnd.example.sbom.v1+json")
+								return len(referrers), err
+							},
+							Equal(0),
+						),
+					)
+				})
+
 				t.Run("WHEN 拉取并重新推送为v1标签", func(t *testing.T) {
 					imagePushed := MustValue(t, func() (oci.Manifest, error) {
 						return remote.Manifest(ctx, remoteRepo, "latest")
@@ -168,6 +255,16 @@ func FuzzNamespace(f *testing.F) {
 						),
 					)
 
+					Then(
+						t, "从 latest 之后分页列出标签",
+						ExpectMustValue(
+							func() ([]string, error) {
+								return collect.Tags(ctx, tags, "latest", 1)
+							},
+							Equal([]string{"v1"}),
+						),
+					)
+
 					t.Run("WHEN 删除 latest 标签", func(t *testing.T) {
 						Then(
 							t, "成功删除标签",
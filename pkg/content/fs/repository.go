@@ -9,8 +9,9 @@ import (
 )
 
 type repository struct {
-	workspace *workspace
-	named     reference.Named
+	workspace       *workspace
+	named           reference.Named
+	sparseManifests bool
 }
 
 func (r *repository) Named() reference.Named {
@@ -22,13 +23,23 @@ func (r *repository) Blobs(ctx context.Context) (content.BlobStore, error) {
 }
 
 func (r *repository) Manifests(ctx context.Context) (content.ManifestService, error) {
-	return &manifestService{blobStore: newLinkedBlobStoreForManifestService(r.workspace, r.named)}, nil
+	return r.manifestService(), nil
 }
 
 func (r *repository) Tags(ctx context.Context) (content.TagService, error) {
 	return &tagService{
 		named:           r.named,
 		workspace:       r.workspace,
-		manifestService: &manifestService{blobStore: newLinkedBlobStoreForManifestService(r.workspace, r.named)},
+		manifestService: r.manifestService(),
 	}, nil
 }
+
+func (r *repository) manifestService() *manifestService {
+	return &manifestService{
+		workspace: r.workspace,
+		named:     r.named,
+		blobStore: newLinkedBlobStoreForManifestService(r.workspace, r.named),
+		layers:    newLinkedBlobStore(r.workspace, r.named),
+		sparse:    r.sparseManifests,
+	}
+}
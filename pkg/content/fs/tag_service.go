@@ -73,6 +73,46 @@ func (t *tagService) Tag(ctx context.Context, tag string, desc manifestv1.Descri
 	return nil
 }
 
+var _ content.ImmutableTagger = &tagService{}
+
+// TagIfAbsent 以条件写入创建标签的当前链接，已存在且指向其他清单时拒绝
+func (t *tagService) TagIfAbsent(ctx context.Context, tag string, desc manifestv1.Descriptor) error {
+	info, err := t.manifestService.Info(ctx, desc.Digest)
+	if err != nil {
+		return err
+	}
+
+	if err := t.workspace.PutContentIfAbsent(
+		ctx,
+		t.workspace.layout.RepositoryManifestTagCurrentLinkPath(t.named, tag),
+		[]byte(info.Digest),
+	); err != nil {
+		if !errors.Is(err, fs.ErrExist) {
+			return err
+		}
+
+		current, err := t.Get(ctx, tag)
+		if err != nil {
+			return err
+		}
+
+		if current.Digest != info.Digest {
+			return &v2.ErrTagImmutable{
+				Name:   t.named.Name(),
+				Tag:    tag,
+				Digest: current.Digest,
+			}
+		}
+	}
+
+	// record revision
+	return t.workspace.PutContent(
+		ctx,
+		t.workspace.layout.RepositoryManifestTagIndexLinkPath(t.named, tag, info.Digest),
+		[]byte(info.Digest),
+	)
+}
+
 func (t *tagService) Untag(ctx context.Context, tag string) error {
 	return t.workspace.Delete(ctx, t.workspace.layout.RepositoryManifestTagPath(t.named, tag))
 }
@@ -97,6 +137,33 @@ func (t *tagService) All(ctx context.Context) ([]string, error) {
 	return tags, nil
 }
 
+var _ content.TagAfterIterable = &tagService{}
+
+func (t *tagService) TagsAfter(ctx context.Context, last string) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		for d, err := range t.workspace.ListDir(ctx, t.workspace.layout.RepositoryManifestTagsPath(t.named), last) {
+			if err != nil {
+				// skip tags dir not exists
+				if perr, ok := errors.AsType[*os.PathError](err); ok {
+					if os.IsNotExist(perr) {
+						return
+					}
+				}
+				yield("", err)
+				return
+			}
+
+			if !d.IsDir() {
+				continue
+			}
+
+			if !yield(d.Name(), nil) {
+				return
+			}
+		}
+	}
+}
+
 var _ content.TagRevisionIterable = &tagService{}
 
 func (t *tagService) TagRevisions(ctx context.Context, tag string) iter.Seq2[content.LinkedDigest, error] {
@@ -0,0 +1,101 @@
+package fs
+
+import (
+	"regexp"
+	"sync"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/octohelm/unifs/pkg/filesystem/local"
+	. "github.com/octohelm/x/testing/v2"
+
+	manifestv1 "github.com/octohelm/crkit/pkg/apis/manifest/v1"
+	"github.com/octohelm/crkit/pkg/apis/registry/v2"
+	"github.com/octohelm/crkit/pkg/content"
+	driverfs "github.com/octohelm/crkit/pkg/driver/fs"
+)
+
+func TestTagIfAbsent(t *testing.T) {
+	ctx := t.Context()
+
+	ns := NewNamespace(driverfs.FromFileSystem(local.NewFS(t.TempDir())), WithSparseManifests())
+
+	repo := MustValue(t, func() (content.Repository, error) {
+		return ns.Repository(ctx, v2.Name("test/app"))
+	})
+
+	digests := make([]digest.Digest, 0, 2)
+
+	for _, version := range []string{"v1", "v2"} {
+		image := &manifestv1.OciManifest{
+			MediaType:   manifestv1.MediaTypeImageManifest,
+			Config:      ocispecv1.DescriptorEmptyJSON,
+			Annotations: map[string]string{"version": version},
+		}
+		image.SchemaVersion = 2
+
+		digests = append(digests, MustValue(t, func() (digest.Digest, error) {
+			manifests, err := repo.Manifests(ctx)
+			if err != nil {
+				return "", err
+			}
+			return manifests.Put(ctx, image)
+		}))
+	}
+
+	tags := MustValue(t, func() (content.TagService, error) {
+		return repo.Tags(ctx)
+	})
+
+	tagger := tags.(content.ImmutableTagger)
+
+	t.Run("并发创建同一标签", func(t *testing.T) {
+		wg := sync.WaitGroup{}
+		errs := make([]error, 10)
+
+		for i := range errs {
+			wg.Go(func() {
+				errs[i] = tagger.TagIfAbsent(ctx, "latest", manifestv1.Descriptor{Digest: digests[i%2]})
+			})
+		}
+		wg.Wait()
+
+		current := MustValue(t, func() (*manifestv1.Descriptor, error) {
+			return tags.Get(ctx, "latest")
+		})
+
+		failed := 0
+		for i, err := range errs {
+			if err != nil {
+				failed++
+				continue
+			}
+			Then(t, "成功写入的请求均指向最终的清单",
+				Expect(digests[i%2], Equal(current.Digest)),
+			)
+		}
+
+		Then(t, "指向其他清单的请求均被拒绝",
+			Expect(failed, Equal(5)),
+		)
+
+		other := digests[0]
+		if other == current.Digest {
+			other = digests[1]
+		}
+
+		Then(t, "重复写入相同清单不受影响",
+			ExpectMust(func() error {
+				return tagger.TagIfAbsent(ctx, "latest", manifestv1.Descriptor{Digest: current.Digest})
+			}),
+			ExpectDo(
+				func() error {
+					return tagger.TagIfAbsent(ctx, "latest", manifestv1.Descriptor{Digest: other})
+				},
+				ErrorMatch(regexp.MustCompile("immutable")),
+			),
+		)
+	})
+}
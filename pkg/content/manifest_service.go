@@ -2,6 +2,7 @@ package content
 
 import (
 	"context"
+	"iter"
 
 	"github.com/opencontainers/go-digest"
 
@@ -14,3 +15,7 @@ type ManifestService interface {
 	Put(ctx context.Context, manifest manifestv1.Manifest) (digest.Digest, error)
 	Delete(ctx context.Context, dgst digest.Digest) error
 }
+
+type ReferrersIterable interface {
+	Referrers(ctx context.Context, subject digest.Digest) iter.Seq2[manifestv1.Descriptor, error]
+}
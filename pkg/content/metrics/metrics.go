@@ -0,0 +1,132 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	UploadStarted   = "started"
+	UploadCommitted = "committed"
+	UploadCancelled = "cancelled"
+)
+
+const (
+	KindBlob     = "blob"
+	KindManifest = "manifest"
+	KindTag      = "tag"
+)
+
+var (
+	meter = otel.Meter("github.com/octohelm/crkit/pkg/content")
+
+	blobServed = must(meter.Int64Counter(
+		"crkit.blob.served",
+		metric.WithUnit("By"),
+		metric.WithDescription("Blob 下载字节数"),
+	))
+	blobIngested = must(meter.Int64Counter(
+		"crkit.blob.ingested",
+		metric.WithUnit("By"),
+		metric.WithDescription("Blob 上传字节数"),
+	))
+	manifestPulls = must(meter.Int64Counter(
+		"crkit.manifest.pulls",
+		metric.WithDescription("清单拉取次数"),
+	))
+	manifestPushes = must(meter.Int64Counter(
+		"crkit.manifest.pushes",
+		metric.WithDescription("清单推送次数"),
+	))
+	uploadSessions = must(meter.Int64Counter(
+		"crkit.upload.sessions",
+		metric.WithDescription("上传会话数，按 state 区分 started / committed / cancelled"),
+	))
+	uploadDuration = must(meter.Float64Histogram(
+		"crkit.upload.duration",
+		metric.WithUnit("s"),
+		metric.WithDescription("上传会话自创建至提交的耗时"),
+	))
+	proxyCacheRequests = must(meter.Int64Counter(
+		"crkit.proxy.cache.requests",
+		metric.WithDescription("代理缓存请求数，按 result 区分 hit / miss"),
+	))
+	remoteRequestDuration = must(meter.Float64Histogram(
+		"crkit.remote.request.duration",
+		metric.WithUnit("s"),
+		metric.WithDescription("远端仓库请求耗时"),
+	))
+	gcFreed = must(meter.Int64Counter(
+		"crkit.gc.freed",
+		metric.WithUnit("By"),
+		metric.WithDescription("垃圾回收释放的字节数"),
+	))
+)
+
+func must[T any](v T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func repository(name string) metric.MeasurementOption {
+	return metric.WithAttributes(attribute.String("repository", name))
+}
+
+func RecordBlobServed(ctx context.Context, name string, n int64) {
+	blobServed.Add(ctx, n, repository(name))
+}
+
+func RecordBlobIngested(ctx context.Context, name string, n int64) {
+	blobIngested.Add(ctx, n, repository(name))
+}
+
+func RecordManifestPull(ctx context.Context, name string) {
+	manifestPulls.Add(ctx, 1, repository(name))
+}
+
+func RecordManifestPush(ctx context.Context, name string) {
+	manifestPushes.Add(ctx, 1, repository(name))
+}
+
+func RecordUploadSession(ctx context.Context, name string, state string) {
+	uploadSessions.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("repository", name),
+		attribute.String("state", state),
+	))
+}
+
+func RecordUploadDuration(ctx context.Context, name string, d time.Duration) {
+	uploadDuration.Record(ctx, d.Seconds(), repository(name))
+}
+
+// RecordProxyCache kind 为 blob / manifest
+func RecordProxyCache(ctx context.Context, kind string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+
+	proxyCacheRequests.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("kind", kind),
+		attribute.String("result", result),
+	))
+}
+
+// RecordRemoteRequest statusCode 为 0 表示请求未得到响应
+func RecordRemoteRequest(ctx context.Context, host string, method string, statusCode int, d time.Duration) {
+	remoteRequestDuration.Record(ctx, d.Seconds(), metric.WithAttributes(
+		attribute.String("server.address", host),
+		attribute.String("http.request.method", method),
+		attribute.Int("http.response.status_code", statusCode),
+	))
+}
+
+func RecordGCFreed(ctx context.Context, n int64) {
+	gcFreed.Add(ctx, n)
+}
@@ -0,0 +1,254 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"iter"
+	"time"
+
+	"github.com/distribution/reference"
+	"github.com/opencontainers/go-digest"
+
+	manifestv1 "github.com/octohelm/crkit/pkg/apis/manifest/v1"
+	"github.com/octohelm/crkit/pkg/apis/registry/v2"
+	"github.com/octohelm/crkit/pkg/content"
+)
+
+// NewNamespace 包装 Namespace，记录清单拉取与推送、Blob 下载与上传及上传会话
+//
+// 枚举类操作通过 UnwarpPersistNamespace 直接访问底层 Namespace，不计入指标
+func NewNamespace(ns content.Namespace) content.Namespace {
+	return &namespace{underlying: ns}
+}
+
+type namespace struct {
+	underlying content.Namespace
+}
+
+var _ content.PersistNamespaceWrapper = &namespace{}
+
+func (n *namespace) UnwarpPersistNamespace() content.Namespace {
+	if w, ok := n.underlying.(content.PersistNamespaceWrapper); ok {
+		return w.UnwarpPersistNamespace()
+	}
+	return n.underlying
+}
+
+func (n *namespace) Repository(ctx context.Context, named reference.Named) (content.Repository, error) {
+	repo, err := n.underlying.Repository(ctx, named)
+	if err != nil {
+		return nil, err
+	}
+	return &repository{Repository: repo}, nil
+}
+
+type repository struct {
+	content.Repository
+}
+
+func (r *repository) Manifests(ctx context.Context) (content.ManifestService, error) {
+	ms, err := r.Repository.Manifests(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &manifestService{ManifestService: ms, name: r.Named().Name()}, nil
+}
+
+func (r *repository) Blobs(ctx context.Context) (content.BlobStore, error) {
+	bs, err := r.Repository.Blobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &blobStore{BlobStore: bs, name: r.Named().Name()}, nil
+}
+
+type manifestService struct {
+	content.ManifestService
+
+	name string
+}
+
+func (ms *manifestService) Get(ctx context.Context, dgst digest.Digest) (manifestv1.Manifest, error) {
+	m, err := ms.ManifestService.Get(ctx, dgst)
+	if err != nil {
+		return nil, err
+	}
+	RecordManifestPull(ctx, ms.name)
+	return m, nil
+}
+
+func (ms *manifestService) Put(ctx context.Context, m manifestv1.Manifest) (digest.Digest, error) {
+	dgst, err := ms.ManifestService.Put(ctx, m)
+	if err != nil {
+		return "", err
+	}
+	RecordManifestPush(ctx, ms.name)
+	return dgst, nil
+}
+
+var _ content.ReferrersIterable = &manifestService{}
+
+func (ms *manifestService) Referrers(ctx context.Context, subject digest.Digest) iter.Seq2[manifestv1.Descriptor, error] {
+	if i, ok := ms.ManifestService.(content.ReferrersIterable); ok {
+		return i.Referrers(ctx, subject)
+	}
+
+	return func(yield func(manifestv1.Descriptor, error) bool) {
+		yield(manifestv1.Descriptor{}, &v2.ErrNotImplemented{Reason: errors.New("ReferrersIterable of ManifestService")})
+	}
+}
+
+var _ content.LinkedDigestIterable = &manifestService{}
+
+func (ms *manifestService) LinkedDigests(ctx context.Context) iter.Seq2[content.LinkedDigest, error] {
+	if i, ok := ms.ManifestService.(content.LinkedDigestIterable); ok {
+		return i.LinkedDigests(ctx)
+	}
+
+	return func(yield func(content.LinkedDigest, error) bool) {
+		yield(content.LinkedDigest{}, &v2.ErrNotImplemented{Reason: errors.New("LinkedDigestIterable of ManifestService")})
+	}
+}
+
+type blobStore struct {
+	content.BlobStore
+
+	name string
+}
+
+func (bs *blobStore) Open(ctx context.Context, dgst digest.Digest) (io.ReadCloser, error) {
+	r, err := bs.BlobStore.Open(ctx, dgst)
+	if err != nil {
+		return nil, err
+	}
+	return &countingReader{ReadCloser: r, ctx: ctx, name: bs.name}, nil
+}
+
+var _ content.RangeProvider = &blobStore{}
+
+func (bs *blobStore) OpenRange(ctx context.Context, dgst digest.Digest, offset int64, length int64) (io.ReadCloser, error) {
+	rp, ok := bs.BlobStore.(content.RangeProvider)
+	if !ok {
+		return nil, &v2.ErrNotImplemented{Reason: errors.New("RangeProvider of BlobStore")}
+	}
+
+	r, err := rp.OpenRange(ctx, dgst, offset, length)
+	if err != nil {
+		return nil, err
+	}
+	return &countingReader{ReadCloser: r, ctx: ctx, name: bs.name}, nil
+}
+
+var _ content.PresignedURLProvider = &blobStore{}
+
+func (bs *blobStore) PresignedURL(ctx context.Context, dgst digest.Digest) (string, error) {
+	if p, ok := bs.BlobStore.(content.PresignedURLProvider); ok {
+		return p.PresignedURL(ctx, dgst)
+	}
+
+	return "", &v2.ErrNotImplemented{Reason: errors.New("PresignedURLProvider of BlobStore")}
+}
+
+var _ content.Mounter = &blobStore{}
+
+func (bs *blobStore) Mount(ctx context.Context, from reference.Named, dgst digest.Digest) (*manifestv1.Descriptor, error) {
+	if mounter, ok := bs.BlobStore.(content.Mounter); ok {
+		return mounter.Mount(ctx, from, dgst)
+	}
+
+	return nil, &v2.ErrManifestBlobUnknown{
+		Name:   from.Name(),
+		Digest: dgst,
+	}
+}
+
+var _ content.LinkedDigestIterable = &blobStore{}
+
+func (bs *blobStore) LinkedDigests(ctx context.Context) iter.Seq2[content.LinkedDigest, error] {
+	if i, ok := bs.BlobStore.(content.LinkedDigestIterable); ok {
+		return i.LinkedDigests(ctx)
+	}
+
+	return func(yield func(content.LinkedDigest, error) bool) {
+		yield(content.LinkedDigest{}, &v2.ErrNotImplemented{Reason: errors.New("LinkedDigestIterable of BlobStore")})
+	}
+}
+
+func (bs *blobStore) Writer(ctx context.Context) (content.BlobWriter, error) {
+	w, err := bs.BlobStore.Writer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	RecordUploadSession(ctx, bs.name, UploadStarted)
+
+	return &blobWriter{BlobWriter: w, name: bs.name, startedAt: time.Now()}, nil
+}
+
+func (bs *blobStore) Resume(ctx context.Context, id string) (content.BlobWriter, error) {
+	w, err := bs.BlobStore.Resume(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	bw := &blobWriter{BlobWriter: w, name: bs.name, startedAt: time.Now()}
+	// 续传时以上传会话的创建时间计算耗时
+	if s, ok := w.(interface{ StartedAt() time.Time }); ok && !s.StartedAt().IsZero() {
+		bw.startedAt = s.StartedAt()
+	}
+
+	return bw, nil
+}
+
+type blobWriter struct {
+	content.BlobWriter
+
+	name      string
+	startedAt time.Time
+}
+
+func (bw *blobWriter) Commit(ctx context.Context, expected manifestv1.Descriptor) (*manifestv1.Descriptor, error) {
+	d, err := bw.BlobWriter.Commit(ctx, expected)
+	if err != nil {
+		return nil, err
+	}
+
+	RecordBlobIngested(ctx, bw.name, d.Size)
+	RecordUploadSession(ctx, bw.name, UploadCommitted)
+	RecordUploadDuration(ctx, bw.name, time.Since(bw.startedAt))
+
+	return d, nil
+}
+
+func (bw *blobWriter) Cancel(ctx context.Context) error {
+	if err := bw.BlobWriter.Cancel(ctx); err != nil {
+		return err
+	}
+
+	RecordUploadSession(ctx, bw.name, UploadCancelled)
+
+	return nil
+}
+
+type countingReader struct {
+	io.ReadCloser
+
+	ctx  context.Context
+	name string
+	n    int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+func (r *countingReader) Close() error {
+	if r.n > 0 {
+		RecordBlobServed(r.ctx, r.name, r.n)
+		r.n = 0
+	}
+	return r.ReadCloser.Close()
+}
@@ -0,0 +1,90 @@
+package metrics_test
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/octohelm/unifs/pkg/filesystem/local"
+	. "github.com/octohelm/x/testing/v2"
+
+	manifestv1 "github.com/octohelm/crkit/pkg/apis/manifest/v1"
+	"github.com/octohelm/crkit/pkg/apis/registry/v2"
+	contentfs "github.com/octohelm/crkit/pkg/content/fs"
+	"github.com/octohelm/crkit/pkg/content/metrics"
+	driverfs "github.com/octohelm/crkit/pkg/driver/fs"
+)
+
+func TestNamespace(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+
+	tmp := t.TempDir()
+	t.Cleanup(func() {
+		_ = os.RemoveAll(tmp)
+	})
+
+	ns := metrics.NewNamespace(contentfs.NewNamespace(driverfs.FromFileSystem(local.NewFS(tmp))))
+
+	data := []byte("hello")
+
+	MustValue(t, func() (*manifestv1.Descriptor, error) {
+		repo, err := ns.Repository(t.Context(), v2.Name("test/app"))
+		if err != nil {
+			return nil, err
+		}
+		blobs, err := repo.Blobs(t.Context())
+		if err != nil {
+			return nil, err
+		}
+		w, err := blobs.Writer(t.Context())
+		if err != nil {
+			return nil, err
+		}
+		defer w.Close()
+
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+		d, err := w.Commit(t.Context(), manifestv1.Descriptor{Digest: digest.FromBytes(data)})
+		if err != nil {
+			return nil, err
+		}
+
+		r, err := blobs.Open(t.Context(), d.Digest)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.Copy(io.Discard, r); err != nil {
+			return nil, err
+		}
+		return d, r.Close()
+	})
+
+	rm := metricdata.ResourceMetrics{}
+	Must(t, func() error {
+		return reader.Collect(t.Context(), &rm)
+	})
+
+	sums := map[string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if sum, ok := m.Data.(metricdata.Sum[int64]); ok {
+				for _, p := range sum.DataPoints {
+					sums[m.Name] += p.Value
+				}
+			}
+		}
+	}
+
+	Then(t, "记录上传与下载",
+		Expect(sums["crkit.blob.ingested"], Equal(int64(len(data)))),
+		Expect(sums["crkit.blob.served"], Equal(int64(len(data)))),
+		Expect(sums["crkit.upload.sessions"], Equal(int64(2))),
+	)
+}
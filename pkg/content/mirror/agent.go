@@ -0,0 +1,125 @@
+package mirror
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/innoai-tech/infra/pkg/agent"
+	"github.com/innoai-tech/infra/pkg/cron"
+	"github.com/octohelm/exp/xiter"
+	"github.com/octohelm/x/sync/singleflight"
+
+	"github.com/octohelm/crkit/pkg/content"
+)
+
+// LoadConfig 读取 JSON 格式的同步配置文件
+func LoadConfig(file string) (*Config, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("读取同步配置文件失败: %w", err)
+	}
+
+	c := &Config{}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("解析同步配置文件失败: %w", err)
+	}
+
+	return c, nil
+}
+
+// +gengo:injectable
+type Executor struct {
+	// 同步配置文件（JSON）
+	ConfigFile string `flags:",omitzero"`
+
+	namespace content.Namespace `inject:",opt"`
+}
+
+func (e *Executor) Run(ctx context.Context) error {
+	if e.namespace == nil {
+		return errors.New("镜像同步需要本地存储，不支持 NoCache 模式")
+	}
+
+	c, err := LoadConfig(e.ConfigFile)
+	if err != nil {
+		return err
+	}
+
+	summary, err := Sync(ctx, e.namespace, c)
+	if summary != nil {
+		data, _ := json.MarshalIndent(summary, "", "  ")
+		_, _ = fmt.Fprintln(os.Stdout, string(data))
+	}
+
+	return err
+}
+
+// +gengo:injectable
+type Mirror struct {
+	agent.Agent
+
+	// 同步配置文件（JSON），声明时按周期同步
+	ConfigFile string    `flags:",omitzero"`
+	Period     cron.Spec `flags:",omitzero"`
+
+	namespace content.Namespace `inject:",opt"`
+}
+
+func (a *Mirror) Disabled(ctx context.Context) bool {
+	return a.namespace == nil || a.ConfigFile == "" || a.Period.Schedule() == nil
+}
+
+func (a *Mirror) SetDefaults() {
+	if a.Period.IsZero() {
+		a.Period = "@hourly"
+	}
+}
+
+func (a *Mirror) afterInit(ctx context.Context) error {
+	if a.Disabled(ctx) {
+		return nil
+	}
+
+	// 启动时校验配置
+	if _, err := LoadConfig(a.ConfigFile); err != nil {
+		return err
+	}
+
+	sfg := singleflight.Group[string]{}
+
+	a.Host("Mirror", func(ctx context.Context) error {
+		for range xiter.Merge(
+			xiter.Of(time.Now()),
+			a.Period.Times(ctx),
+		) {
+			a.Go(ctx, func(ctx context.Context) error {
+				err, _ := sfg.Do("sync", func() error {
+					defer sfg.Forget("sync")
+
+					return a.Sync(ctx)
+				})
+
+				return err
+			})
+		}
+
+		return nil
+	})
+
+	return nil
+}
+
+// Sync 每次重新读取配置文件，修改配置无需重启
+func (a *Mirror) Sync(ctx context.Context) error {
+	c, err := LoadConfig(a.ConfigFile)
+	if err != nil {
+		return err
+	}
+
+	_, err = Sync(ctx, a.namespace, c)
+	return err
+}
@@ -0,0 +1,150 @@
+package mirror
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/containerd/platforms"
+	"github.com/distribution/reference"
+	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	contentremote "github.com/octohelm/crkit/pkg/content/remote"
+)
+
+// Config 镜像同步配置
+type Config struct {
+	// 源注册表的地址与凭证，按源仓库的域名匹配；未声明的域名以 https://{domain} 访问
+	Registries contentremote.RegistryHosts `json:"registries,omitzero"`
+	// 需要同步的仓库
+	Repositories []Repository `json:"repositories"`
+}
+
+// Repository 单个源仓库的同步规则
+//
+// 标签依次经 tags、semverRange 筛选后，按版本从新到旧保留 latest 个
+type Repository struct {
+	// 源仓库，如 docker.io/library/nginx
+	Source string `json:"source"`
+	// 本地仓库名，默认为源仓库去除域名后的路径
+	Target string `json:"target,omitzero"`
+	// 标签正则
+	Tags string `json:"tags,omitzero"`
+	// 标签的 semver 范围，如 >=1.2 <2；声明时忽略非 semver 标签
+	SemverRange string `json:"semverRange,omitzero"`
+	// 仅同步最新的 N 个标签，semver 标签按版本排序并优先于其他标签，其他标签按名称倒序
+	Latest int `json:"latest,omitzero"`
+	// 仅同步指定平台，如 linux/amd64；为空时同步所有平台
+	//
+	// 多平台镜像按平台筛选后重新生成索引，摘要与源不同
+	Platforms []string `json:"platforms,omitzero"`
+}
+
+func (r *Repository) resolve() (*rule, error) {
+	source, err := reference.ParseNormalizedNamed(r.Source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid source %q: %w", r.Source, err)
+	}
+
+	target, err := reference.WithName(cmp.Or(r.Target, reference.Path(source)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid target %q: %w", r.Target, err)
+	}
+
+	rl := &rule{
+		source: source,
+		target: target,
+		latest: r.Latest,
+	}
+
+	if r.Tags != "" {
+		rl.tags, err = regexp.Compile(r.Tags)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tags pattern %q: %w", r.Tags, err)
+		}
+	}
+
+	if r.SemverRange != "" {
+		rl.semverRange, err = semver.NewConstraint(r.SemverRange)
+		if err != nil {
+			return nil, fmt.Errorf("invalid semver range %q: %w", r.SemverRange, err)
+		}
+	}
+
+	if len(r.Platforms) > 0 {
+		ps := make([]ocispecv1.Platform, 0, len(r.Platforms))
+		for _, p := range r.Platforms {
+			pl, err := platforms.Parse(p)
+			if err != nil {
+				return nil, fmt.Errorf("invalid platform %q: %w", p, err)
+			}
+			ps = append(ps, pl)
+		}
+		rl.platforms = platforms.Any(ps...)
+	}
+
+	return rl, nil
+}
+
+type rule struct {
+	source      reference.Named
+	target      reference.Named
+	tags        *regexp.Regexp
+	semverRange *semver.Constraints
+	latest      int
+	platforms   platforms.MatchComparer
+}
+
+// filter 返回需要同步的标签
+func (rl *rule) filter(tags []string) []string {
+	type candidate struct {
+		tag     string
+		version *semver.Version
+	}
+
+	candidates := make([]candidate, 0, len(tags))
+
+	for _, tag := range tags {
+		if rl.tags != nil && !rl.tags.MatchString(tag) {
+			continue
+		}
+
+		c := candidate{tag: tag}
+		if v, err := semver.NewVersion(tag); err == nil {
+			c.version = v
+		}
+
+		if rl.semverRange != nil && (c.version == nil || !rl.semverRange.Check(c.version)) {
+			continue
+		}
+
+		candidates = append(candidates, c)
+	}
+
+	slices.SortFunc(candidates, func(a, b candidate) int {
+		switch {
+		case a.version != nil && b.version != nil:
+			if c := b.version.Compare(a.version); c != 0 {
+				return c
+			}
+		case a.version != nil:
+			return -1
+		case b.version != nil:
+			return 1
+		}
+		return cmp.Compare(b.tag, a.tag)
+	})
+
+	if rl.latest > 0 && len(candidates) > rl.latest {
+		candidates = candidates[:rl.latest]
+	}
+
+	filtered := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		filtered = append(filtered, c.tag)
+	}
+
+	return filtered
+}
@@ -0,0 +1,295 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/opencontainers/go-digest"
+
+	"github.com/octohelm/x/logr"
+
+	manifestv1 "github.com/octohelm/crkit/pkg/apis/manifest/v1"
+	"github.com/octohelm/crkit/pkg/content"
+	contentremote "github.com/octohelm/crkit/pkg/content/remote"
+	"github.com/octohelm/crkit/pkg/oci"
+	"github.com/octohelm/crkit/pkg/oci/empty"
+	"github.com/octohelm/crkit/pkg/oci/mutate"
+	ociremote "github.com/octohelm/crkit/pkg/oci/remote"
+)
+
+// Summary 同步结果
+type Summary struct {
+	Repositories []*RepositorySummary `json:"repositories"`
+}
+
+// RepositorySummary 单个仓库的同步结果
+type RepositorySummary struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	// 新复制或更新指向的标签
+	Copied []string `json:"copied,omitzero"`
+	// 本地已是最新的标签
+	UpToDate []string `json:"upToDate,omitzero"`
+	// 失败的标签（或仓库）及原因
+	Failed map[string]string `json:"failed,omitzero"`
+	// 复制的清单数
+	Manifests int `json:"manifests"`
+	// 复制的 Blob 数
+	Blobs int `json:"blobs"`
+	// 复制的 Blob 字节数
+	Bytes int64 `json:"bytes"`
+
+	// 清单与 Blob 并发写入
+	mu sync.Mutex
+}
+
+func (s *RepositorySummary) written(manifests int, blobs int, bytes int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Manifests += manifests
+	s.Blobs += blobs
+	s.Bytes += bytes
+}
+
+func (s *RepositorySummary) fail(key string, err error) {
+	if s.Failed == nil {
+		s.Failed = map[string]string{}
+	}
+	s.Failed[key] = err.Error()
+}
+
+// Sync 从源注册表复制本地缺失的清单与 Blob 至 local，并将标签指向源的最新版本
+//
+// 单个标签失败不影响其他标签，所有失败汇总为返回的错误
+func Sync(ctx context.Context, local content.Namespace, c *Config) (*Summary, error) {
+	if underlying, ok := local.(content.PersistNamespaceWrapper); ok {
+		local = underlying.UnwarpPersistNamespace()
+	}
+
+	rules := make([]*rule, 0, len(c.Repositories))
+	for _, r := range c.Repositories {
+		rl, err := r.resolve()
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rl)
+	}
+
+	hosts := c.Registries
+	if hosts == nil {
+		hosts = contentremote.RegistryHosts{}
+	}
+
+	source, err := contentremote.New(ctx, hosts)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &Summary{}
+	errs := make([]error, 0)
+
+	for _, rl := range rules {
+		s := &RepositorySummary{
+			Source: rl.source.String(),
+			Target: rl.target.String(),
+		}
+		summary.Repositories = append(summary.Repositories, s)
+
+		if err := (&syncer{rule: rl, summary: s}).sync(ctx, source, local); err != nil {
+			s.fail("", err)
+		}
+
+		for key, reason := range s.Failed {
+			errs = append(errs, fmt.Errorf("sync %s:%s failed: %s", rl.source, key, reason))
+		}
+
+		logr.FromContext(ctx).WithValues(
+			slog.String("source", s.Source),
+			slog.String("target", s.Target),
+			slog.Int("copied", len(s.Copied)),
+			slog.Int("upToDate", len(s.UpToDate)),
+			slog.Int("failed", len(s.Failed)),
+			slog.Int("manifests", s.Manifests),
+			slog.Int("blobs", s.Blobs),
+			slog.Int64("bytes", s.Bytes),
+		).Info("synced")
+	}
+
+	return summary, errors.Join(errs...)
+}
+
+type syncer struct {
+	rule    *rule
+	summary *RepositorySummary
+}
+
+func (s *syncer) sync(ctx context.Context, source content.Namespace, local content.Namespace) error {
+	sourceRepo, err := source.Repository(ctx, s.rule.source)
+	if err != nil {
+		return err
+	}
+
+	localRepo, err := local.Repository(ctx, s.rule.target)
+	if err != nil {
+		return err
+	}
+
+	sourceTags, err := sourceRepo.Tags(ctx)
+	if err != nil {
+		return err
+	}
+
+	localTags, err := localRepo.Tags(ctx)
+	if err != nil {
+		return err
+	}
+
+	all, err := sourceTags.All(ctx)
+	if err != nil {
+		return fmt.Errorf("list tags failed: %w", err)
+	}
+
+	target := &countingRepository{Repository: localRepo, summary: s.summary}
+
+	for _, tag := range s.rule.filter(all) {
+		copied, err := s.syncTag(ctx, sourceRepo, localTags, target, tag)
+		if err != nil {
+			s.summary.fail(tag, err)
+			continue
+		}
+
+		if copied {
+			s.summary.Copied = append(s.summary.Copied, tag)
+		} else {
+			s.summary.UpToDate = append(s.summary.UpToDate, tag)
+		}
+	}
+
+	return nil
+}
+
+func (s *syncer) syncTag(ctx context.Context, sourceRepo content.Repository, localTags content.TagService, target content.Repository, tag string) (bool, error) {
+	m, err := ociremote.Manifest(ctx, sourceRepo, tag)
+	if err != nil {
+		return false, err
+	}
+
+	if idx, ok := m.(oci.Index); ok && s.rule.platforms != nil {
+		m, err = s.selectPlatforms(ctx, idx)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	d, err := m.Descriptor(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if current, err := localTags.Get(ctx, tag); err == nil && current.Digest == d.Digest {
+		return false, nil
+	}
+
+	if err := ociremote.Push(ctx, m, target, tag); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// selectPlatforms 以匹配平台的子清单重新生成索引
+func (s *syncer) selectPlatforms(ctx context.Context, idx oci.Index) (oci.Manifest, error) {
+	matched := make([]oci.Manifest, 0)
+
+	for child, err := range idx.Manifests(ctx) {
+		if err != nil {
+			return nil, err
+		}
+
+		d, err := child.Descriptor(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if d.Platform != nil && s.rule.platforms.Match(*d.Platform) {
+			matched = append(matched, child)
+		}
+	}
+
+	if len(matched) == 0 {
+		return nil, errors.New("no manifest matched platforms")
+	}
+
+	return mutate.AppendManifests(empty.Index, matched...)
+}
+
+// countingRepository 统计实际写入的清单与 Blob
+type countingRepository struct {
+	content.Repository
+
+	summary *RepositorySummary
+}
+
+func (r *countingRepository) Manifests(ctx context.Context) (content.ManifestService, error) {
+	ms, err := r.Repository.Manifests(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &countingManifestService{ManifestService: ms, summary: r.summary}, nil
+}
+
+func (r *countingRepository) Blobs(ctx context.Context) (content.BlobStore, error) {
+	bs, err := r.Repository.Blobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &countingBlobStore{BlobStore: bs, summary: r.summary}, nil
+}
+
+type countingManifestService struct {
+	content.ManifestService
+
+	summary *RepositorySummary
+}
+
+func (ms *countingManifestService) Put(ctx context.Context, m manifestv1.Manifest) (digest.Digest, error) {
+	dgst, err := ms.ManifestService.Put(ctx, m)
+	if err != nil {
+		return "", err
+	}
+	ms.summary.written(1, 0, 0)
+	return dgst, nil
+}
+
+type countingBlobStore struct {
+	content.BlobStore
+
+	summary *RepositorySummary
+}
+
+func (bs *countingBlobStore) Writer(ctx context.Context) (content.BlobWriter, error) {
+	w, err := bs.BlobStore.Writer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &countingBlobWriter{BlobWriter: w, summary: bs.summary}, nil
+}
+
+type countingBlobWriter struct {
+	content.BlobWriter
+
+	summary *RepositorySummary
+}
+
+func (w *countingBlobWriter) Commit(ctx context.Context, expected manifestv1.Descriptor) (*manifestv1.Descriptor, error) {
+	d, err := w.BlobWriter.Commit(ctx, expected)
+	if err != nil {
+		return nil, err
+	}
+	w.summary.written(0, 1, d.Size)
+	return d, nil
+}
@@ -0,0 +1,156 @@
+package mirror_test
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/distribution/reference"
+
+	"github.com/octohelm/unifs/pkg/filesystem/local"
+	"github.com/octohelm/unifs/pkg/units"
+	. "github.com/octohelm/x/testing/v2"
+
+	"github.com/octohelm/crkit/pkg/content"
+	contentfs "github.com/octohelm/crkit/pkg/content/fs"
+	"github.com/octohelm/crkit/pkg/content/mirror"
+	contentremote "github.com/octohelm/crkit/pkg/content/remote"
+	contenttestutil "github.com/octohelm/crkit/pkg/content/testutil"
+	driverfs "github.com/octohelm/crkit/pkg/driver/fs"
+	"github.com/octohelm/crkit/pkg/oci"
+	"github.com/octohelm/crkit/pkg/oci/empty"
+	"github.com/octohelm/crkit/pkg/oci/mutate"
+	"github.com/octohelm/crkit/pkg/oci/random"
+	ociremote "github.com/octohelm/crkit/pkg/oci/remote"
+)
+
+func TestSync(t *testing.T) {
+	ctx := t.Context()
+
+	upstream := httptest.NewServer(contenttestutil.NewRegistry(t))
+	t.Cleanup(upstream.Close)
+
+	host := MustValue(t, func() (string, error) {
+		u, err := url.Parse(upstream.URL)
+		if err != nil {
+			return "", err
+		}
+		return u.Host, nil
+	})
+
+	registries := contentremote.RegistryHosts{
+		host: {Server: upstream.URL},
+	}
+
+	source := MustValue(t, func() (content.Repository, error) {
+		ns, err := contentremote.New(ctx, registries)
+		if err != nil {
+			return nil, err
+		}
+		named, err := reference.WithName(host + "/library/app")
+		if err != nil {
+			return nil, err
+		}
+		return ns.Repository(ctx, named)
+	})
+
+	image := func(platform string) oci.Image {
+		return MustValue(t, func() (oci.Image, error) {
+			img, err := random.Image(int64(10*units.KiB), 1)
+			if err != nil {
+				return nil, err
+			}
+			return mutate.WithPlatform(img, platform)
+		})
+	}
+
+	Must(t, func() error {
+		for _, tag := range []string{"1.0.0", "1.1.0", "2.0.0", "dev"} {
+			if err := ociremote.Push(ctx, image(""), source, tag); err != nil {
+				return err
+			}
+		}
+
+		idx, err := mutate.AppendManifests(empty.Index, image("linux/amd64"), image("linux/arm64"))
+		if err != nil {
+			return err
+		}
+		return ociremote.Push(ctx, idx, source, "multi")
+	})
+
+	local := contentfs.NewNamespace(driverfs.FromFileSystem(local.NewFS(t.TempDir())))
+
+	localRepo := MustValue(t, func() (content.Repository, error) {
+		named, err := reference.WithName("mirror/app")
+		if err != nil {
+			return nil, err
+		}
+		return local.Repository(ctx, named)
+	})
+
+	c := &mirror.Config{
+		Registries: registries,
+		Repositories: []mirror.Repository{
+			{
+				Source:      host + "/library/app",
+				Target:      "mirror/app",
+				Tags:        `^\d`,
+				SemverRange: ">=1.0 <2",
+				Latest:      1,
+			},
+			{
+				Source:    host + "/library/app",
+				Target:    "mirror/app",
+				Tags:      `^multi$`,
+				Platforms: []string{"linux/arm64"},
+			},
+		},
+	}
+
+	t.Run("按规则复制缺失的标签", func(t *testing.T) {
+		summary := MustValue(t, func() (*mirror.Summary, error) {
+			return mirror.Sync(ctx, local, c)
+		})
+
+		Then(t, "仅复制匹配的标签",
+			Expect(summary.Repositories[0].Copied, Equal([]string{"1.1.0"})),
+			Expect(summary.Repositories[0].Manifests, Equal(1)),
+			Expect(summary.Repositories[0].Blobs, Equal(2)),
+			Expect(summary.Repositories[1].Copied, Equal([]string{"multi"})),
+			ExpectMustValue(func() ([]string, error) {
+				tags, err := localRepo.Tags(ctx)
+				if err != nil {
+					return nil, err
+				}
+				return tags.All(ctx)
+			}, Equal([]string{"1.1.0", "multi"})),
+		)
+
+		Then(t, "多平台镜像仅保留匹配的平台",
+			ExpectMustValue(func() (int, error) {
+				m, err := ociremote.Manifest(ctx, localRepo, "multi")
+				if err != nil {
+					return 0, err
+				}
+				idx, err := m.(oci.Index).Value(ctx)
+				if err != nil {
+					return 0, err
+				}
+				return len(idx.Manifests), nil
+			}, Equal(1)),
+		)
+	})
+
+	t.Run("再次同步时跳过已是最新的标签", func(t *testing.T) {
+		summary := MustValue(t, func() (*mirror.Summary, error) {
+			return mirror.Sync(ctx, local, c)
+		})
+
+		Then(t, "无新复制内容",
+			Expect(len(summary.Repositories[0].Copied), Equal(0)),
+			Expect(summary.Repositories[0].UpToDate, Equal([]string{"1.1.0"})),
+			Expect(summary.Repositories[1].UpToDate, Equal([]string{"multi"})),
+			Expect(summary.Repositories[0].Bytes, Equal(int64(0))),
+		)
+	})
+}
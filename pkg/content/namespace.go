@@ -16,6 +16,10 @@ type RepositoryNameIterable interface {
 	RepositoryNames(ctx context.Context) iter.Seq2[reference.Named, error]
 }
 
+type RepositoryNameAfterIterable interface {
+	RepositoryNamesAfter(ctx context.Context, last string) iter.Seq2[reference.Named, error]
+}
+
 type PersistNamespaceWrapper interface {
 	UnwarpPersistNamespace() Namespace
 }
@@ -0,0 +1,364 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/distribution/reference"
+	"github.com/opencontainers/go-digest"
+
+	"github.com/octohelm/x/logr"
+
+	manifestv1 "github.com/octohelm/crkit/pkg/apis/manifest/v1"
+	"github.com/octohelm/crkit/pkg/content"
+	"github.com/octohelm/crkit/pkg/content/cacheindex"
+)
+
+// DefaultFillIdleTimeout 回源持续未收到数据的时限，超出后放弃回源，避免停滞的上游阻塞所有读取方
+const DefaultFillIdleTimeout = time.Minute
+
+// ErrFillStalled 回源超出时限未收到数据
+var ErrFillStalled = errors.New("blob fill stalled")
+
+// blobFills 按摘要合并同一 Blob 的并发回源
+//
+// 首个未命中的请求发起回源，后台协程将远端内容同时写入本地 BlobWriter 与临时文件；
+// 所有读取方（包括首个请求）从临时文件跟随读取已写入的部分，读取方提前断开不影响回源。
+// 内容按摘要寻址，其他仓库的读取方同样合并到该次回源，提交后再关联到各自的仓库
+type blobFills struct {
+	// 回源完成后标记为缓存内容，可为空
+	index cacheindex.Index
+	// 临时文件目录，为空时为系统临时目录
+	dir string
+	// 回源持续未收到数据的时限，为 0 时为 DefaultFillIdleTimeout
+	idleTimeout time.Duration
+
+	mu    sync.Mutex
+	fills map[digest.Digest]*blobFill
+}
+
+func (g *blobFills) open(ctx context.Context, named reference.Named, dgst digest.Digest, local content.BlobStore, remote content.BlobStore) (io.ReadCloser, error) {
+	g.mu.Lock()
+
+	f, ok := g.fills[dgst]
+	if ok {
+		f.acquire()
+		f.join(named, local)
+	} else {
+		f = &blobFill{
+			named:   named,
+			started: make(chan struct{}),
+			changed: make(chan struct{}),
+			// 回源协程与首个读取方
+			refs: 2,
+		}
+
+		if g.fills == nil {
+			g.fills = map[digest.Digest]*blobFill{}
+		}
+		g.fills[dgst] = f
+
+		idleTimeout := g.idleTimeout
+		if idleTimeout <= 0 {
+			idleTimeout = DefaultFillIdleTimeout
+		}
+
+		// 回源不随首个请求取消，持续未收到数据时取消
+		fillCtx, cancel := context.WithCancelCause(context.WithoutCancel(ctx))
+
+		f.idleTimeout = idleTimeout
+		f.idle = time.AfterFunc(idleTimeout, func() {
+			cancel(fmt.Errorf("%w: no data received in %s", ErrFillStalled, idleTimeout))
+		})
+
+		go func() {
+			defer cancel(nil)
+
+			err := f.run(fillCtx, g.dir, dgst, local, remote)
+			f.idle.Stop()
+
+			if err != nil && fillCtx.Err() != nil {
+				err = context.Cause(fillCtx)
+			}
+
+			g.mu.Lock()
+			delete(g.fills, dgst)
+			joined := f.joined
+			g.mu.Unlock()
+
+			if err != nil && f.startErr == nil {
+				logr.FromContext(fillCtx).Error(fmt.Errorf("fill blob %s to local failed: %w", dgst, err))
+			}
+
+			if err == nil {
+				for name, store := range joined {
+					if err := f.link(fillCtx, store, dgst); err != nil {
+						logr.FromContext(fillCtx).WithValues(slog.String("name", name)).Error(fmt.Errorf("link blob %s failed: %w", dgst, err))
+					}
+				}
+
+				recordCached(fillCtx, g.index, cacheindex.Entry{Kind: cacheindex.KindBlob, Digest: dgst})
+			}
+
+			f.finish(err)
+		}()
+	}
+
+	g.mu.Unlock()
+
+	select {
+	case <-f.started:
+	case <-ctx.Done():
+		f.release()
+		return nil, ctx.Err()
+	}
+
+	if f.startErr != nil {
+		f.release()
+		return nil, f.startErr
+	}
+
+	return &blobFillReader{ctx: ctx, fill: f}, nil
+}
+
+type blobFill struct {
+	// 写入并关联 Blob 的仓库
+	named reference.Named
+	// 合并到本次回源的其他仓库，由 blobFills 的锁保护
+	joined map[string]content.BlobStore
+
+	// 远端打开且本地写入就绪后关闭，失败时 startErr 非空
+	started  chan struct{}
+	startErr error
+
+	spool *os.File
+
+	// 收到数据时重置，超时后取消回源
+	idle        *time.Timer
+	idleTimeout time.Duration
+
+	mu      sync.Mutex
+	written int64
+	done    bool
+	err     error
+	refs    int
+	// 每次写入或结束时关闭并替换，用于唤醒等待的读取方
+	changed chan struct{}
+}
+
+func (f *blobFill) join(named reference.Named, local content.BlobStore) {
+	name := named.Name()
+	if name == f.named.Name() {
+		return
+	}
+
+	if f.joined == nil {
+		f.joined = map[string]content.BlobStore{}
+	}
+	f.joined[name] = local
+}
+
+// link 将已提交的 Blob 关联到合并进来的仓库
+func (f *blobFill) link(ctx context.Context, local content.BlobStore, dgst digest.Digest) error {
+	mounter, ok := local.(content.Mounter)
+	if !ok {
+		return nil
+	}
+
+	_, err := mounter.Mount(ctx, f.named, dgst)
+	return err
+}
+
+func (f *blobFill) run(ctx context.Context, dir string, dgst digest.Digest, local content.BlobStore, remote content.BlobStore) error {
+	blob, bw, err := f.start(ctx, dir, dgst, local, remote)
+	f.startErr = err
+	close(f.started)
+	if err != nil {
+		return err
+	}
+
+	return f.fill(ctx, dgst, blob, bw)
+}
+
+func (f *blobFill) start(ctx context.Context, dir string, dgst digest.Digest, local content.BlobStore, remote content.BlobStore) (io.ReadCloser, content.BlobWriter, error) {
+	blob, err := remote.Open(ctx, dgst)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bw, err := local.Writer(ctx)
+	if err != nil {
+		_ = blob.Close()
+		return nil, nil, err
+	}
+
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			_ = blob.Close()
+			_ = bw.Cancel(ctx)
+			return nil, nil, err
+		}
+	}
+
+	spool, err := os.CreateTemp(dir, "crkit-blob-fill-*")
+	if err != nil {
+		_ = blob.Close()
+		_ = bw.Cancel(ctx)
+		return nil, nil, err
+	}
+	f.spool = spool
+
+	return blob, bw, nil
+}
+
+func (f *blobFill) fill(ctx context.Context, dgst digest.Digest, blob io.ReadCloser, bw content.BlobWriter) (err error) {
+	defer func() {
+		_ = blob.Close()
+
+		if err != nil {
+			_ = bw.Cancel(ctx)
+		}
+	}()
+
+	buf := make([]byte, 32*1024)
+
+	for {
+		n, rerr := blob.Read(buf)
+		if n > 0 {
+			if _, err := bw.Write(buf[:n]); err != nil {
+				return err
+			}
+			if _, err := f.spool.WriteAt(buf[:n], f.size()); err != nil {
+				return err
+			}
+			f.wrote(int64(n))
+		}
+
+		if rerr != nil {
+			if errors.Is(rerr, io.EOF) {
+				break
+			}
+			return rerr
+		}
+	}
+
+	// 远端内容已读完，提交不受时限约束
+	f.idle.Stop()
+
+	// 提交时校验摘要，读取方在提交完成前不会读到 EOF
+	if _, err := bw.Commit(ctx, manifestv1.Descriptor{Digest: dgst}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (f *blobFill) size() int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.written
+}
+
+func (f *blobFill) wrote(n int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.written += n
+	f.idle.Reset(f.idleTimeout)
+	f.broadcast()
+}
+
+func (f *blobFill) finish(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.done = true
+	f.err = err
+	f.broadcast()
+	f.releaseLocked()
+}
+
+func (f *blobFill) broadcast() {
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+func (f *blobFill) acquire() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.refs++
+}
+
+func (f *blobFill) release() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.releaseLocked()
+}
+
+func (f *blobFill) releaseLocked() {
+	f.refs--
+
+	if f.refs == 0 && f.spool != nil {
+		_ = f.spool.Close()
+		_ = os.Remove(f.spool.Name())
+	}
+}
+
+// state 返回已写入的字节数，尚未结束时同时返回下次变化的通知
+func (f *blobFill) state() (int64, bool, error, <-chan struct{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.written, f.done, f.err, f.changed
+}
+
+type blobFillReader struct {
+	ctx    context.Context
+	fill   *blobFill
+	offset int64
+
+	closeOnce sync.Once
+}
+
+func (r *blobFillReader) Read(p []byte) (int, error) {
+	for {
+		written, done, err, changed := r.fill.state()
+
+		if err != nil {
+			return 0, err
+		}
+
+		if r.offset < written {
+			n, err := r.fill.spool.ReadAt(p[:min(int64(len(p)), written-r.offset)], r.offset)
+			r.offset += int64(n)
+			if err != nil && !errors.Is(err, io.EOF) {
+				return n, err
+			}
+			return n, nil
+		}
+
+		if done {
+			return 0, io.EOF
+		}
+
+		select {
+		case <-r.ctx.Done():
+			return 0, r.ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+func (r *blobFillReader) Close() error {
+	r.closeOnce.Do(r.fill.release)
+	return nil
+}
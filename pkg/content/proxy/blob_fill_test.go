@@ -0,0 +1,250 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/distribution/reference"
+	"github.com/opencontainers/go-digest"
+
+	"github.com/octohelm/unifs/pkg/filesystem/local"
+	"github.com/octohelm/x/cmp"
+	. "github.com/octohelm/x/testing/v2"
+
+	"github.com/octohelm/crkit/pkg/content"
+	contentfs "github.com/octohelm/crkit/pkg/content/fs"
+	driverfs "github.com/octohelm/crkit/pkg/driver/fs"
+)
+
+func TestBlobFills(t *testing.T) {
+	named := func(name string) reference.Named {
+		return MustValue(t, func() (reference.Named, error) {
+			return reference.WithName(name)
+		})
+	}
+
+	blobs := func(t *testing.T, ns content.Namespace, name string) content.BlobStore {
+		return MustValue(t, func() (content.BlobStore, error) {
+			repo, err := ns.Repository(t.Context(), named(name))
+			if err != nil {
+				return nil, err
+			}
+			return repo.Blobs(t.Context())
+		})
+	}
+
+	newLocal := func(t *testing.T) content.BlobStore {
+		return blobs(t, contentfs.NewNamespace(driverfs.FromFileSystem(local.NewFS(t.TempDir()))), "test/app")
+	}
+
+	data := bytes.Repeat([]byte("0123456789"), 10*1024)
+	dgst := digest.FromBytes(data)
+
+	t.Run("并发未命中仅回源一次", func(t *testing.T) {
+		localStore := newLocal(t)
+		remote := &gatedBlobStore{data: data, gate: make(chan struct{})}
+		fills := &blobFills{}
+
+		first := MustValue(t, func() (io.ReadCloser, error) {
+			return fills.open(t.Context(), named("test/app"), dgst, localStore, remote)
+		})
+
+		// 首个读取方读到部分内容后断开，不影响回源
+		head := make([]byte, 10)
+		Must(t, func() error {
+			_, err := io.ReadFull(first, head)
+			return err
+		})
+		_ = first.Close()
+
+		results := make([][]byte, 5)
+		errs := make([]error, 5)
+
+		wg := &sync.WaitGroup{}
+		for i := range results {
+			r := MustValue(t, func() (io.ReadCloser, error) {
+				return fills.open(t.Context(), named("test/app"), dgst, localStore, remote)
+			})
+
+			wg.Go(func() {
+				defer r.Close()
+				results[i], errs[i] = io.ReadAll(r)
+			})
+		}
+
+		close(remote.gate)
+		wg.Wait()
+
+		Then(t, "所有读取方得到完整内容",
+			Expect(remote.opened.Load(), Equal(int32(1))),
+			Expect(errors.Join(errs...), Be(cmp.Nil[error]())),
+			Expect(digest.FromBytes(results[0]), Equal(dgst)),
+			Expect(digest.FromBytes(results[4]), Equal(dgst)),
+		)
+
+		Then(t, "内容已缓存至本地",
+			ExpectMustValue(func() (int64, error) {
+				d, err := localStore.Info(t.Context(), dgst)
+				if err != nil {
+					return 0, err
+				}
+				return d.Size, nil
+			}, Equal(int64(len(data)))),
+		)
+	})
+
+	t.Run("不同仓库的并发未命中合并回源并各自关联", func(t *testing.T) {
+		ns := contentfs.NewNamespace(driverfs.FromFileSystem(local.NewFS(t.TempDir())))
+		remote := &gatedBlobStore{data: data, gate: make(chan struct{})}
+		spoolDir := t.TempDir()
+		fills := &blobFills{dir: spoolDir}
+
+		names := []string{"test/app", "test/other", "mirror/app"}
+		readers := make([]io.ReadCloser, len(names))
+		for i, name := range names {
+			readers[i] = MustValue(t, func() (io.ReadCloser, error) {
+				return fills.open(t.Context(), named(name), dgst, blobs(t, ns, name), remote)
+			})
+		}
+
+		close(remote.gate)
+
+		for _, r := range readers {
+			Must(t, func() error {
+				_, err := io.ReadAll(r)
+				return err
+			})
+			_ = r.Close()
+		}
+
+		Then(t, "仅回源一次",
+			Expect(remote.opened.Load(), Equal(int32(1))),
+		)
+
+		for _, name := range names {
+			Then(t, "Blob 关联到 "+name,
+				ExpectMustValue(func() (int64, error) {
+					d, err := blobs(t, ns, name).Info(t.Context(), dgst)
+					if err != nil {
+						return 0, err
+					}
+					return d.Size, nil
+				}, Equal(int64(len(data)))),
+			)
+		}
+
+		Then(t, "临时文件位于指定目录且已清理",
+			ExpectMustValue(func() (int, error) {
+				entries, err := os.ReadDir(spoolDir)
+				return len(entries), err
+			}, Equal(0)),
+		)
+	})
+
+	t.Run("回源失败时所有读取方收到错误", func(t *testing.T) {
+		localStore := newLocal(t)
+		remote := &gatedBlobStore{data: data, gate: make(chan struct{}), err: errors.New("connection reset")}
+		fills := &blobFills{}
+
+		readers := make([]io.ReadCloser, 3)
+		for i := range readers {
+			readers[i] = MustValue(t, func() (io.ReadCloser, error) {
+				return fills.open(t.Context(), named("test/app"), dgst, localStore, remote)
+			})
+		}
+
+		close(remote.gate)
+
+		errs := make([]error, len(readers))
+		for i, r := range readers {
+			_, errs[i] = io.ReadAll(r)
+			_ = r.Close()
+		}
+
+		_, infoErr := localStore.Info(t.Context(), dgst)
+
+		Then(t, "读取失败且未缓存",
+			Expect(errs[0].Error(), Equal("connection reset")),
+			Expect(errs[2].Error(), Equal("connection reset")),
+			Expect(infoErr == nil, Equal(false)),
+		)
+	})
+
+	t.Run("上游停滞时放弃回源", func(t *testing.T) {
+		localStore := newLocal(t)
+		remote := &gatedBlobStore{data: data, gate: make(chan struct{})}
+		fills := &blobFills{idleTimeout: 50 * time.Millisecond}
+
+		r := MustValue(t, func() (io.ReadCloser, error) {
+			return fills.open(t.Context(), named("test/app"), dgst, localStore, remote)
+		})
+		defer r.Close()
+
+		_, err := io.ReadAll(r)
+
+		Then(t, "读取方收到错误，回源结束后移除",
+			Expect(errors.Is(err, ErrFillStalled), Equal(true)),
+			ExpectMustValue(func() (int, error) {
+				fills.mu.Lock()
+				defer fills.mu.Unlock()
+				return len(fills.fills), nil
+			}, Equal(0)),
+		)
+	})
+}
+
+// gatedBlobStore 远端 Blob，前半部分立即返回，其余在 gate 关闭后返回，err 非空时以其代替后半部分
+type gatedBlobStore struct {
+	content.BlobStore
+
+	data   []byte
+	gate   chan struct{}
+	err    error
+	opened atomic.Int32
+}
+
+func (s *gatedBlobStore) Open(ctx context.Context, dgst digest.Digest) (io.ReadCloser, error) {
+	s.opened.Add(1)
+
+	half := len(s.data) / 2
+
+	var tail io.Reader = bytes.NewReader(s.data[half:])
+	if s.err != nil {
+		tail = &errReader{err: s.err}
+	}
+
+	return io.NopCloser(io.MultiReader(
+		bytes.NewReader(s.data[:half]),
+		&gatedReader{ctx: ctx, gate: s.gate, r: tail},
+	)), nil
+}
+
+type gatedReader struct {
+	ctx  context.Context
+	gate chan struct{}
+	r    io.Reader
+}
+
+func (r *gatedReader) Read(p []byte) (int, error) {
+	select {
+	case <-r.gate:
+	case <-r.ctx.Done():
+		return 0, r.ctx.Err()
+	}
+	return r.r.Read(p)
+}
+
+type errReader struct {
+	err error
+}
+
+func (r *errReader) Read(p []byte) (int, error) {
+	return 0, r.err
+}
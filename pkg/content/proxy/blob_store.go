@@ -13,6 +13,8 @@ import (
 	manifestv1 "github.com/octohelm/crkit/pkg/apis/manifest/v1"
 	"github.com/octohelm/crkit/pkg/apis/registry/v2"
 	"github.com/octohelm/crkit/pkg/content"
+	"github.com/octohelm/crkit/pkg/content/cacheindex"
+	"github.com/octohelm/crkit/pkg/content/metrics"
 )
 
 type proxyBlobStore struct {
@@ -20,6 +22,8 @@ type proxyBlobStore struct {
 
 	localStore  content.BlobStore
 	remoteStore content.BlobStore
+	blobFills   *blobFills
+	cacheIndex  cacheindex.Index
 }
 
 var _ content.BlobStore = &proxyBlobStore{}
@@ -32,6 +36,20 @@ func (pbs *proxyBlobStore) Writer(ctx context.Context) (content.BlobWriter, erro
 	return pbs.remoteStore.Writer(ctx)
 }
 
+var _ content.Mounter = &proxyBlobStore{}
+
+func (pbs *proxyBlobStore) Mount(ctx context.Context, from reference.Named, dgst digest.Digest) (*manifestv1.Descriptor, error) {
+	// writes go to remote, so mount should be done by remote too
+	if mounter, ok := pbs.remoteStore.(content.Mounter); ok {
+		return mounter.Mount(ctx, from, dgst)
+	}
+
+	return nil, &v2.ErrManifestBlobUnknown{
+		Name:   from.Name(),
+		Digest: dgst,
+	}
+}
+
 func (pbs *proxyBlobStore) Remove(ctx context.Context, dgst digest.Digest) error {
 	return pbs.localStore.Remove(ctx, dgst)
 }
@@ -39,38 +57,37 @@ func (pbs *proxyBlobStore) Remove(ctx context.Context, dgst digest.Digest) error
 func (pbs *proxyBlobStore) Open(ctx context.Context, dgst digest.Digest) (io.ReadCloser, error) {
 	blob, err := pbs.localStore.Open(ctx, dgst)
 	if err == nil {
+		metrics.RecordProxyCache(ctx, metrics.KindBlob, true)
+		touchCached(ctx, pbs.cacheIndex, cacheindex.Entry{Kind: cacheindex.KindBlob, Digest: dgst})
 		return blob, nil
 	}
 
-	blob, err = pbs.remoteStore.Open(ctx, dgst)
-	if err != nil {
-		return nil, err
-	}
+	metrics.RecordProxyCache(ctx, metrics.KindBlob, false)
+
+	// 同一 Blob 的并发未命中合并为一次回源，读取方跟随读取已缓存的部分
+	return pbs.blobFills.open(ctx, pbs.repositoryName, dgst, pbs.localStore, pbs.remoteStore)
+}
 
-	bw, err := pbs.localStore.Writer(ctx)
-	if err != nil {
-		return nil, err
+var _ content.RangeProvider = &proxyBlobStore{}
+
+// OpenRange 本地存在时读取本地，否则直接透传至远端，不缓存部分内容
+func (pbs *proxyBlobStore) OpenRange(ctx context.Context, dgst digest.Digest, offset int64, length int64) (io.ReadCloser, error) {
+	if local, ok := pbs.localStore.(content.RangeProvider); ok {
+		if _, err := pbs.localStore.Info(ctx, dgst); err == nil {
+			metrics.RecordProxyCache(ctx, metrics.KindBlob, true)
+			touchCached(ctx, pbs.cacheIndex, cacheindex.Entry{Kind: cacheindex.KindBlob, Digest: dgst})
+			return local.OpenRange(ctx, dgst, offset, length)
+		}
 	}
 
-	rsc := &struct {
-		io.Reader
-		io.Closer
-	}{
-		Reader: io.TeeReader(blob, bw),
-		Closer: closerFunc(func() error {
-			defer func() {
-				err = blob.Close()
-			}()
-
-			_, err = bw.Commit(ctx, manifestv1.Descriptor{
-				Digest: dgst,
-			})
-
-			return err
-		}),
+	metrics.RecordProxyCache(ctx, metrics.KindBlob, false)
+
+	remote, ok := pbs.remoteStore.(content.RangeProvider)
+	if !ok {
+		return nil, &v2.ErrNotImplemented{Reason: errors.New("RangeProvider of remote BlobStore")}
 	}
 
-	return rsc, nil
+	return remote.OpenRange(ctx, dgst, offset, length)
 }
 
 func (pbs *proxyBlobStore) Info(ctx context.Context, dgst digest.Digest) (*manifestv1.Descriptor, error) {
@@ -85,15 +102,3 @@ func (pbs *proxyBlobStore) Info(ctx context.Context, dgst digest.Digest) (*manif
 
 	return pbs.remoteStore.Info(ctx, dgst)
 }
-
-func closerFunc(close func() error) io.Closer {
-	return &closer{close}
-}
-
-type closer struct {
-	close func() error
-}
-
-func (c *closer) Close() error {
-	return c.close()
-}
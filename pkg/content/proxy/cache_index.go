@@ -0,0 +1,39 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/octohelm/x/logr"
+
+	"github.com/octohelm/crkit/pkg/content/cacheindex"
+)
+
+// WithCacheIndex 记录从远程缓存的清单与 Blob 及其访问时间，供缓存淘汰使用
+func WithCacheIndex(idx cacheindex.Index) Option {
+	return func(n *namespace) {
+		n.cacheIndex = idx
+	}
+}
+
+// recordCached 标记为从远程缓存的内容，失败不影响请求
+func recordCached(ctx context.Context, idx cacheindex.Index, e cacheindex.Entry) {
+	if idx == nil {
+		return
+	}
+
+	if err := idx.Record(ctx, e); err != nil {
+		logr.FromContext(ctx).Warn(fmt.Errorf("record cached %s %s failed: %w", e.Kind, e.Digest, err))
+	}
+}
+
+// touchCached 刷新本地命中内容的访问时间，失败不影响请求
+func touchCached(ctx context.Context, idx cacheindex.Index, e cacheindex.Entry) {
+	if idx == nil {
+		return
+	}
+
+	if err := idx.Touch(ctx, e); err != nil {
+		logr.FromContext(ctx).Warn(fmt.Errorf("touch cached %s %s failed: %w", e.Kind, e.Digest, err))
+	}
+}
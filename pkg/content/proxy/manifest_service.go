@@ -3,6 +3,7 @@ package proxy
 import (
 	"context"
 	"fmt"
+	"iter"
 
 	"github.com/distribution/reference"
 	"github.com/opencontainers/go-digest"
@@ -11,12 +12,15 @@ import (
 
 	manifestv1 "github.com/octohelm/crkit/pkg/apis/manifest/v1"
 	"github.com/octohelm/crkit/pkg/content"
+	"github.com/octohelm/crkit/pkg/content/cacheindex"
+	"github.com/octohelm/crkit/pkg/content/metrics"
 )
 
 type proxyManifestService struct {
 	repositoryName  reference.Named
 	localManifests  content.ManifestService
 	remoteManifests content.ManifestService
+	cacheIndex      cacheindex.Index
 }
 
 var _ content.ManifestService = &proxyManifestService{}
@@ -35,6 +39,7 @@ func (pms *proxyManifestService) Info(ctx context.Context, dgst digest.Digest) (
 
 func (pms *proxyManifestService) Get(ctx context.Context, dgst digest.Digest) (manifestv1.Manifest, error) {
 	manifest, err := pms.localManifests.Get(ctx, dgst)
+	metrics.RecordProxyCache(ctx, metrics.KindManifest, err == nil)
 	if err != nil {
 		manifest, err = pms.remoteManifests.Get(ctx, dgst)
 		if err != nil {
@@ -42,14 +47,72 @@ func (pms *proxyManifestService) Get(ctx context.Context, dgst digest.Digest) (m
 		}
 
 		go func() {
-			if _, err := pms.localManifests.Put(context.WithoutCancel(ctx), manifest); err != nil {
+			ctx := context.WithoutCancel(ctx)
+
+			stored, err := pms.localManifests.Put(ctx, manifest)
+			if err != nil {
 				logr.FromContext(ctx).Error(fmt.Errorf("store manifest to local failed: %w", err))
+				return
 			}
+
+			recordCached(ctx, pms.cacheIndex, pms.cacheEntry(stored))
 		}()
+
+		return manifest, nil
+	}
+
+	touchCached(ctx, pms.cacheIndex, pms.cacheEntry(dgst))
+
+	return manifest, nil
+}
+
+func (pms *proxyManifestService) cacheEntry(dgst digest.Digest) cacheindex.Entry {
+	return cacheindex.Entry{
+		Kind:   cacheindex.KindManifest,
+		Name:   pms.repositoryName.Name(),
+		Digest: dgst,
 	}
-	return manifest, err
 }
 
 func (pms *proxyManifestService) Put(ctx context.Context, manifest manifestv1.Manifest) (digest.Digest, error) {
 	return pms.localManifests.Put(ctx, manifest)
 }
+
+var _ content.ReferrersIterable = &proxyManifestService{}
+
+func (pms *proxyManifestService) Referrers(ctx context.Context, subject digest.Digest) iter.Seq2[manifestv1.Descriptor, error] {
+	return func(yield func(manifestv1.Descriptor, error) bool) {
+		if remote, ok := pms.remoteManifests.(content.ReferrersIterable); ok {
+			referrers := make([]manifestv1.Descriptor, 0)
+			failed := false
+
+			for d, err := range remote.Referrers(ctx, subject) {
+				if err != nil {
+					failed = true
+					break
+				}
+				referrers = append(referrers, d)
+			}
+
+			if !failed {
+				for _, d := range referrers {
+					if !yield(d, nil) {
+						return
+					}
+				}
+				return
+			}
+		}
+
+		local, ok := pms.localManifests.(content.ReferrersIterable)
+		if !ok {
+			return
+		}
+
+		for d, err := range local.Referrers(ctx, subject) {
+			if !yield(d, err) {
+				return
+			}
+		}
+	}
+}
@@ -2,32 +2,90 @@ package proxy
 
 import (
 	"context"
+	"time"
 
 	"github.com/distribution/reference"
 
 	"github.com/octohelm/crkit/pkg/content"
+	"github.com/octohelm/crkit/pkg/content/cacheindex"
 	"github.com/octohelm/crkit/pkg/content/remote"
 )
 
+type Option func(n *namespace)
+
+// WithOffline 仅从本地缓存提供内容，不访问远程注册表
+func WithOffline() Option {
+	return func(n *namespace) {
+		n.offline = true
+	}
+}
+
+// WithCircuitBreaker 远程注册表连续失败 threshold 次后熔断 cooldown，期间标签回退本地缓存
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(n *namespace) {
+		n.remoteOptions = append(n.remoteOptions, remote.WithCircuitBreaker(threshold, cooldown))
+	}
+}
+
+// DefaultSpoolDir 本地存储根目录下回源临时文件的目录
+const DefaultSpoolDir = "proxyspool"
+
+// WithSpoolDir 回源时供并发读取方跟随读取的临时文件目录，未声明时为系统临时目录
+func WithSpoolDir(dir string) Option {
+	return func(n *namespace) {
+		n.blobFills.dir = dir
+	}
+}
+
+// WithCredentials 未声明认证信息的上游通过 p 获取认证信息
+func WithCredentials(p remote.CredentialProvider) Option {
+	return func(n *namespace) {
+		n.remoteOptions = append(n.remoteOptions, remote.WithCredentials(p))
+	}
+}
+
 // namespace fetches content from a remote registry and caches it locally
 type namespace struct {
 	local  content.Namespace // provides local registry functionality
 	remote content.Namespace
+
+	resolver      remote.RegistryResolver
+	remoteOptions []remote.Option
+	offline       bool
+	cacheIndex    cacheindex.Index
+
+	blobFills    *blobFills
+	tagFreshness *tagFreshness
 }
 
-func NewProxyFallbackRegistry(ctx context.Context, registry content.Namespace, rr remote.RegistryResolver) (content.Namespace, error) {
-	r, err := remote.New(ctx, rr)
+func NewProxyFallbackRegistry(ctx context.Context, registry content.Namespace, rr remote.RegistryResolver, options ...Option) (content.Namespace, error) {
+	n := &namespace{
+		local:        registry,
+		resolver:     rr,
+		blobFills:    &blobFills{},
+		tagFreshness: &tagFreshness{},
+	}
+
+	for _, opt := range options {
+		opt(n)
+	}
+
+	n.blobFills.index = n.cacheIndex
+
+	r, err := remote.New(ctx, rr, n.remoteOptions...)
 	if err != nil {
 		return nil, err
 	}
+	n.remote = r
 
-	return &namespace{
-		local:  registry,
-		remote: r,
-	}, nil
+	return n, nil
 }
 
 func (n *namespace) Repository(ctx context.Context, name reference.Named) (content.Repository, error) {
+	if n.offline {
+		return n.local.Repository(ctx, name)
+	}
+
 	localRepo, err := n.local.Repository(ctx, name)
 	if err != nil {
 		return nil, err
@@ -38,10 +96,19 @@ func (n *namespace) Repository(ctx context.Context, name reference.Named) (conte
 		return nil, err
 	}
 
+	tagTTL := time.Duration(0)
+	if _, rh, err := n.resolver.Resolve(ctx, name); err == nil && rh != nil {
+		tagTTL = time.Duration(rh.TagTTL)
+	}
+
 	return &repository{
-		name:       name,
-		localRepo:  localRepo,
-		remoteRepo: remoteRepo,
+		name:         name,
+		localRepo:    localRepo,
+		remoteRepo:   remoteRepo,
+		blobFills:    n.blobFills,
+		cacheIndex:   n.cacheIndex,
+		tagTTL:       tagTTL,
+		tagFreshness: n.tagFreshness,
 	}, nil
 }
 
@@ -55,6 +122,11 @@ type repository struct {
 	name       reference.Named
 	localRepo  content.Repository
 	remoteRepo content.Repository
+	blobFills  *blobFills
+	cacheIndex cacheindex.Index
+
+	tagTTL       time.Duration
+	tagFreshness *tagFreshness
 }
 
 func (pr *repository) Named() reference.Named {
@@ -75,6 +147,7 @@ func (pr *repository) Manifests(ctx context.Context) (content.ManifestService, e
 		repositoryName:  pr.name,
 		localManifests:  l,
 		remoteManifests: r,
+		cacheIndex:      pr.cacheIndex,
 	}, nil
 }
 
@@ -91,6 +164,8 @@ func (pr *repository) Blobs(ctx context.Context) (content.BlobStore, error) {
 		repositoryName: pr.name,
 		localStore:     l,
 		remoteStore:    r,
+		blobFills:      pr.blobFills,
+		cacheIndex:     pr.cacheIndex,
 	}, nil
 }
 
@@ -116,6 +191,10 @@ func (pr *repository) Tags(ctx context.Context) (content.TagService, error) {
 	}
 
 	return &proxyTagService{
+		name:                  pr.name,
+		ttl:                   pr.tagTTL,
+		freshness:             pr.tagFreshness,
+		cacheIndex:            pr.cacheIndex,
 		localTagService:       localTagService,
 		localManifestService:  localManifestService,
 		remoteTagService:      remoteTagService,
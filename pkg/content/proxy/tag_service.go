@@ -3,14 +3,28 @@ package proxy
 import (
 	"context"
 	"fmt"
+	"iter"
+	"sync"
+	"time"
+
+	"github.com/distribution/reference"
 
 	"github.com/octohelm/x/logr"
 
 	manifestv1 "github.com/octohelm/crkit/pkg/apis/manifest/v1"
 	"github.com/octohelm/crkit/pkg/content"
+	"github.com/octohelm/crkit/pkg/content/cacheindex"
+	"github.com/octohelm/crkit/pkg/content/collect"
+	"github.com/octohelm/crkit/pkg/content/metrics"
 )
 
 type proxyTagService struct {
+	name      reference.Named
+	ttl       time.Duration
+	freshness *tagFreshness
+
+	cacheIndex cacheindex.Index
+
 	localTagService      content.TagService
 	localManifestService content.ManifestService
 
@@ -29,19 +43,47 @@ func (pt *proxyTagService) syncToLocalManifest(ctx context.Context, tag string,
 	if err != nil {
 		return err
 	}
+
+	recordCached(ctx, pt.cacheIndex, cacheindex.Entry{
+		Kind:   cacheindex.KindManifest,
+		Name:   pt.name.Name(),
+		Digest: dgst,
+	})
 	if err := pt.localTagService.Tag(ctx, tag, manifestv1.Descriptor{Digest: dgst}); err != nil {
 		return err
 	}
+
+	// 标记为代理创建的标签，缓存淘汰时仅删除此类标签
+	recordCached(ctx, pt.cacheIndex, cacheindex.Entry{
+		Kind:   cacheindex.KindTag,
+		Name:   pt.name.Name(),
+		Tag:    tag,
+		Digest: dgst,
+	})
 	return nil
 }
 
+// Get 本地标签在 TTL 内同步过时直接使用，否则先访问远程，远程失败时回退本地
 func (pt *proxyTagService) Get(ctx context.Context, tag string) (*manifestv1.Descriptor, error) {
+	key := pt.name.Name() + ":" + tag
+
+	if pt.ttl > 0 && pt.freshness.fresh(key, pt.ttl, time.Now()) {
+		if local, err := pt.localTagService.Get(ctx, tag); err == nil {
+			metrics.RecordProxyCache(ctx, metrics.KindTag, true)
+			return local, nil
+		}
+	}
+
 	remote, err := pt.remoteTagService.Get(ctx, tag)
 	if err == nil {
+		metrics.RecordProxyCache(ctx, metrics.KindTag, false)
+
 		go func() {
 			if err := pt.syncToLocalManifest(context.WithoutCancel(ctx), tag, remote); err != nil {
 				logr.FromContext(ctx).Error(fmt.Errorf("store tagged manifest to local failed: %w", err))
+				return
 			}
+			pt.freshness.touch(key, time.Now())
 		}()
 		return remote, nil
 	}
@@ -53,7 +95,40 @@ func (pt *proxyTagService) Get(ctx context.Context, tag string) (*manifestv1.Des
 }
 
 func (pt *proxyTagService) Tag(ctx context.Context, tag string, desc manifestv1.Descriptor) error {
-	return pt.localTagService.Tag(ctx, tag, desc)
+	if err := pt.localTagService.Tag(ctx, tag, desc); err != nil {
+		return err
+	}
+	pt.forgetCachedTag(ctx, tag, desc)
+	return nil
+}
+
+// forgetCachedTag 本地推送的标签即使与代理同步的相同，也不再视为代理创建
+func (pt *proxyTagService) forgetCachedTag(ctx context.Context, tag string, desc manifestv1.Descriptor) {
+	if pt.cacheIndex == nil {
+		return
+	}
+
+	if err := pt.cacheIndex.Remove(ctx, cacheindex.Entry{
+		Kind:   cacheindex.KindTag,
+		Name:   pt.name.Name(),
+		Tag:    tag,
+		Digest: desc.Digest,
+	}); err != nil {
+		logr.FromContext(ctx).Warn(fmt.Errorf("forget cached tag %s failed: %w", tag, err))
+	}
+}
+
+var _ content.ImmutableTagger = &proxyTagService{}
+
+func (pt *proxyTagService) TagIfAbsent(ctx context.Context, tag string, desc manifestv1.Descriptor) error {
+	if i, ok := pt.localTagService.(content.ImmutableTagger); ok {
+		if err := i.TagIfAbsent(ctx, tag, desc); err != nil {
+			return err
+		}
+		pt.forgetCachedTag(ctx, tag, desc)
+		return nil
+	}
+	return pt.Tag(ctx, tag, desc)
 }
 
 func (pt *proxyTagService) Untag(ctx context.Context, tag string) error {
@@ -63,3 +138,58 @@ func (pt *proxyTagService) Untag(ctx context.Context, tag string) error {
 func (pt *proxyTagService) All(ctx context.Context) ([]string, error) {
 	return pt.localTagService.All(ctx)
 }
+
+var _ content.TagAfterIterable = &proxyTagService{}
+
+func (pt *proxyTagService) TagsAfter(ctx context.Context, last string) iter.Seq2[string, error] {
+	if i, ok := pt.localTagService.(content.TagAfterIterable); ok {
+		return i.TagsAfter(ctx, last)
+	}
+
+	return func(yield func(string, error) bool) {
+		tags, err := collect.Tags(ctx, pt.localTagService, last, 0)
+		if err != nil {
+			yield("", err)
+			return
+		}
+
+		for _, tag := range tags {
+			if !yield(tag, nil) {
+				return
+			}
+		}
+	}
+}
+
+// tagFreshness 记录标签最近一次从远程同步到本地的时间
+type tagFreshness struct {
+	mu       sync.Mutex
+	syncedAt map[string]time.Time
+}
+
+func (f *tagFreshness) touch(key string, now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.syncedAt == nil {
+		f.syncedAt = map[string]time.Time{}
+	}
+	f.syncedAt[key] = now
+}
+
+func (f *tagFreshness) fresh(key string, ttl time.Duration, now time.Time) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	syncedAt, ok := f.syncedAt[key]
+	if !ok {
+		return false
+	}
+
+	if now.Sub(syncedAt) >= ttl {
+		delete(f.syncedAt, key)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,108 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/distribution/reference"
+	"github.com/opencontainers/go-digest"
+
+	. "github.com/octohelm/x/testing/v2"
+
+	manifestv1 "github.com/octohelm/crkit/pkg/apis/manifest/v1"
+	"github.com/octohelm/crkit/pkg/content"
+)
+
+func TestProxyTagService(t *testing.T) {
+	named := MustValue(t, func() (reference.Named, error) {
+		return reference.WithName("library/app")
+	})
+
+	local := &fixedTagService{desc: &manifestv1.Descriptor{Digest: digest.FromString("local")}}
+	remote := &fixedTagService{desc: &manifestv1.Descriptor{Digest: digest.FromString("remote")}}
+
+	pt := &proxyTagService{
+		name:                  named,
+		ttl:                   time.Minute,
+		freshness:             &tagFreshness{},
+		localTagService:       local,
+		remoteTagService:      remote,
+		remoteManifestService: &failedManifestService{},
+	}
+
+	t.Run("未同步过的标签访问远程", func(t *testing.T) {
+		d := MustValue(t, func() (*manifestv1.Descriptor, error) {
+			return pt.Get(t.Context(), "latest")
+		})
+
+		Then(t, "返回远程标签",
+			Expect(d.Digest, Equal(remote.desc.Digest)),
+			Expect(remote.requested.Load(), Equal(int32(1))),
+		)
+	})
+
+	t.Run("TTL 内使用本地缓存", func(t *testing.T) {
+		pt.freshness.touch("library/app:latest", time.Now())
+
+		d := MustValue(t, func() (*manifestv1.Descriptor, error) {
+			return pt.Get(t.Context(), "latest")
+		})
+
+		Then(t, "不访问远程",
+			Expect(d.Digest, Equal(local.desc.Digest)),
+			Expect(remote.requested.Load(), Equal(int32(1))),
+		)
+	})
+
+	t.Run("TTL 过期后重新访问远程", func(t *testing.T) {
+		pt.freshness.touch("library/app:latest", time.Now().Add(-2*time.Minute))
+
+		d := MustValue(t, func() (*manifestv1.Descriptor, error) {
+			return pt.Get(t.Context(), "latest")
+		})
+
+		Then(t, "返回远程标签",
+			Expect(d.Digest, Equal(remote.desc.Digest)),
+			Expect(remote.requested.Load(), Equal(int32(2))),
+		)
+	})
+
+	t.Run("远程失败时回退本地", func(t *testing.T) {
+		remote.err = errors.New("upstream unavailable")
+
+		d := MustValue(t, func() (*manifestv1.Descriptor, error) {
+			return pt.Get(t.Context(), "latest")
+		})
+
+		Then(t, "返回本地标签",
+			Expect(d.Digest, Equal(local.desc.Digest)),
+		)
+	})
+}
+
+type fixedTagService struct {
+	content.TagService
+
+	desc      *manifestv1.Descriptor
+	err       error
+	requested atomic.Int32
+}
+
+func (s *fixedTagService) Get(ctx context.Context, tag string) (*manifestv1.Descriptor, error) {
+	s.requested.Add(1)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.desc, nil
+}
+
+type failedManifestService struct {
+	content.ManifestService
+}
+
+func (failedManifestService) Get(ctx context.Context, dgst digest.Digest) (manifestv1.Manifest, error) {
+	return nil, errors.New("not found")
+}
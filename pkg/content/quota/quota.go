@@ -0,0 +1,297 @@
+package quota
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/distribution/reference"
+	"github.com/opencontainers/go-digest"
+
+	"github.com/octohelm/crkit/pkg/apis/registry/v2"
+	"github.com/octohelm/crkit/pkg/driver"
+)
+
+// DefaultFile 用量在存储中的位置
+const DefaultFile = "quota/usage.json"
+
+// +gengo:injectable:provider
+type Quota interface {
+	// Charge 仓库新关联 size 字节的 Blob 前调用，任一匹配前缀超出配额时返回 ErrQuotaExceeded 且不计入用量
+	Charge(ctx context.Context, named reference.Named, dgst digest.Digest, size int64) error
+	// Release 仓库解除 Blob 关联后调用
+	Release(ctx context.Context, named reference.Named, dgst digest.Digest, size int64) error
+	// Reconcile 以 scan 扫描到的各仓库实际关联的 Blob 校正用量
+	//
+	// 扫描期间的 Charge / Release 按仓库与摘要记录，提交时覆盖扫描结果，避免与扫描并发的变更丢失
+	Reconcile(ctx context.Context, scan func(ctx context.Context, account AccountFunc) error) error
+	// Usages 列出各前缀的配额与用量
+	Usages(ctx context.Context) ([]Usage, error)
+}
+
+// AccountFunc 计入扫描到的仓库关联
+type AccountFunc func(named reference.Named, dgst digest.Digest, size int64)
+
+// Config 存储配额配置
+type Config struct {
+	Rules []Rule `json:"rules"`
+}
+
+// Rule 仓库前缀的存储配额
+//
+// 前缀 team-a 匹配仓库 team-a 与 team-a/*，空前缀匹配所有仓库；一个仓库可同时受多条规则约束
+type Rule struct {
+	// 仓库名前缀
+	Prefix string `json:"prefix"`
+	// 上限（字节）
+	Limit int64 `json:"limit"`
+}
+
+func (r *Rule) matches(name string) bool {
+	return r.Prefix == "" || name == r.Prefix || strings.HasPrefix(name, r.Prefix+"/")
+}
+
+// Usage 前缀的配额与用量
+type Usage struct {
+	Prefix string `json:"prefix"`
+	Limit  int64  `json:"limit"`
+	Used   int64  `json:"used"`
+}
+
+// New 从 file 恢复已记录的用量，之后每次变更写回
+//
+// 用量按仓库关联的 Blob 大小累计，同一 Blob 关联到多个仓库时分别计入
+func New(ctx context.Context, d driver.Driver, file string, c *Config) (Quota, error) {
+	for _, r := range c.Rules {
+		if r.Limit <= 0 {
+			return nil, fmt.Errorf("invalid limit %d of quota prefix %q", r.Limit, r.Prefix)
+		}
+	}
+
+	q := &quota{
+		driver: d,
+		file:   file,
+		rules:  c.Rules,
+		used:   map[string]int64{},
+	}
+
+	data, err := d.GetContent(ctx, file)
+	if err != nil {
+		if perr, ok := errors.AsType[*os.PathError](err); !ok || !os.IsNotExist(perr) {
+			return nil, err
+		}
+		return q, nil
+	}
+
+	s := &state{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("invalid quota usage file %s: %w", file, err)
+	}
+
+	for _, r := range q.rules {
+		q.used[r.Prefix] = s.Used[r.Prefix]
+	}
+
+	return q, nil
+}
+
+type state struct {
+	Used map[string]int64 `json:"used"`
+}
+
+type quota struct {
+	driver driver.Driver
+	file   string
+	rules  []Rule
+
+	mu   sync.Mutex
+	used map[string]int64
+	// 进行中的校正，记录扫描期间各仓库关联的变更
+	reconciling *reconciliation
+}
+
+// reconciliation 仓库名到摘要与大小的关联，变更中大小为 -1 表示已解除关联
+type reconciliation struct {
+	scanned map[string]map[digest.Digest]int64
+	changed map[string]map[digest.Digest]int64
+}
+
+func setLink(links map[string]map[digest.Digest]int64, name string, dgst digest.Digest, size int64) {
+	if links[name] == nil {
+		links[name] = map[digest.Digest]int64{}
+	}
+	links[name][dgst] = size
+}
+
+func (q *quota) Charge(ctx context.Context, named reference.Named, dgst digest.Digest, size int64) error {
+	name := named.Name()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	matched := make([]string, 0, len(q.rules))
+
+	for _, r := range q.rules {
+		if !r.matches(name) {
+			continue
+		}
+
+		if used := q.used[r.Prefix]; used+size > r.Limit {
+			return &v2.ErrQuotaExceeded{
+				Name:   name,
+				Prefix: r.Prefix,
+				Limit:  r.Limit,
+				Used:   used,
+				Size:   size,
+			}
+		}
+
+		matched = append(matched, r.Prefix)
+	}
+
+	if q.reconciling != nil {
+		setLink(q.reconciling.changed, name, dgst, size)
+	}
+
+	if len(matched) == 0 || size == 0 {
+		return nil
+	}
+
+	for _, prefix := range matched {
+		q.used[prefix] += size
+	}
+
+	return q.save(ctx)
+}
+
+func (q *quota) Release(ctx context.Context, named reference.Named, dgst digest.Digest, size int64) error {
+	name := named.Name()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.reconciling != nil {
+		setLink(q.reconciling.changed, name, dgst, -1)
+	}
+
+	changed := false
+
+	for _, r := range q.rules {
+		if !r.matches(name) {
+			continue
+		}
+
+		q.used[r.Prefix] = max(q.used[r.Prefix]-size, 0)
+		changed = true
+	}
+
+	if !changed || size == 0 {
+		return nil
+	}
+
+	return q.save(ctx)
+}
+
+func (q *quota) Reconcile(ctx context.Context, scan func(ctx context.Context, account AccountFunc) error) error {
+	rc := &reconciliation{
+		scanned: map[string]map[digest.Digest]int64{},
+		changed: map[string]map[digest.Digest]int64{},
+	}
+
+	q.mu.Lock()
+	if q.reconciling != nil {
+		q.mu.Unlock()
+		return errors.New("quota reconciliation is already in progress")
+	}
+	q.reconciling = rc
+	q.mu.Unlock()
+
+	defer func() {
+		q.mu.Lock()
+		q.reconciling = nil
+		q.mu.Unlock()
+	}()
+
+	scanMu := sync.Mutex{}
+
+	if err := scan(ctx, func(named reference.Named, dgst digest.Digest, size int64) {
+		scanMu.Lock()
+		defer scanMu.Unlock()
+
+		setLink(rc.scanned, named.Name(), dgst, size)
+	}); err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	// 扫描期间变更过的关联以最后一次变更为准
+	for name, links := range rc.changed {
+		for dgst, size := range links {
+			if size < 0 {
+				delete(rc.scanned[name], dgst)
+				continue
+			}
+			setLink(rc.scanned, name, dgst, size)
+		}
+	}
+
+	used := make(map[string]int64, len(q.rules))
+
+	for _, r := range q.rules {
+		used[r.Prefix] = 0
+
+		for name, links := range rc.scanned {
+			if !r.matches(name) {
+				continue
+			}
+			for _, size := range links {
+				used[r.Prefix] += size
+			}
+		}
+	}
+
+	q.used = used
+
+	return q.save(ctx)
+}
+
+func (q *quota) Usages(ctx context.Context) ([]Usage, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	usages := make([]Usage, 0, len(q.rules))
+
+	for _, r := range q.rules {
+		usages = append(usages, Usage{
+			Prefix: r.Prefix,
+			Limit:  r.Limit,
+			Used:   q.used[r.Prefix],
+		})
+	}
+
+	slices.SortFunc(usages, func(a, b Usage) int {
+		return strings.Compare(a.Prefix, b.Prefix)
+	})
+
+	return usages, nil
+}
+
+func (q *quota) save(ctx context.Context) error {
+	data, err := json.Marshal(&state{Used: q.used})
+	if err != nil {
+		return err
+	}
+
+	if err := q.driver.PutContent(ctx, q.file, data); err != nil {
+		return fmt.Errorf("failed to save quota usage: %w", err)
+	}
+
+	return nil
+}
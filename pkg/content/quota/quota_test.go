@@ -0,0 +1,168 @@
+package quota_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/distribution/reference"
+	"github.com/opencontainers/go-digest"
+
+	"github.com/octohelm/unifs/pkg/filesystem/local"
+	. "github.com/octohelm/x/testing/v2"
+
+	manifestv1 "github.com/octohelm/crkit/pkg/apis/manifest/v1"
+	"github.com/octohelm/crkit/pkg/apis/registry/v2"
+	"github.com/octohelm/crkit/pkg/content"
+	contentfs "github.com/octohelm/crkit/pkg/content/fs"
+	"github.com/octohelm/crkit/pkg/content/quota"
+	driverfs "github.com/octohelm/crkit/pkg/driver/fs"
+)
+
+func TestQuota(t *testing.T) {
+	d := driverfs.FromFileSystem(local.NewFS(t.TempDir()))
+
+	c := &quota.Config{
+		Rules: []quota.Rule{
+			{Prefix: "team-a", Limit: 10},
+			{Prefix: "team-b", Limit: 100},
+		},
+	}
+
+	q := MustValue(t, func() (quota.Quota, error) {
+		return quota.New(t.Context(), d, quota.DefaultFile, c)
+	})
+
+	ns := contentfs.NewNamespace(d, contentfs.WithQuota(q))
+
+	named := func(name string) reference.Named {
+		return MustValue(t, func() (reference.Named, error) {
+			return reference.WithName(name)
+		})
+	}
+
+	blobs := func(name string) content.BlobStore {
+		return MustValue(t, func() (content.BlobStore, error) {
+			repo, err := ns.Repository(t.Context(), named(name))
+			if err != nil {
+				return nil, err
+			}
+			return repo.Blobs(t.Context())
+		})
+	}
+
+	push := func(ctx context.Context, name string, data []byte) error {
+		w, err := blobs(name).Writer(ctx)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(w, bytes.NewReader(data)); err != nil {
+			return err
+		}
+		_, err = w.Commit(ctx, manifestv1.Descriptor{Digest: digest.FromBytes(data)})
+		return err
+	}
+
+	used := func(prefix string) int64 {
+		usages := MustValue(t, func() ([]quota.Usage, error) {
+			return q.Usages(t.Context())
+		})
+		for _, u := range usages {
+			if u.Prefix == prefix {
+				return u.Used
+			}
+		}
+		return -1
+	}
+
+	Must(t, func() error { return push(t.Context(), "team-a/app", []byte("12345678")) })
+
+	t.Run("重复提交同一 Blob 不重复计入", func(t *testing.T) {
+		Must(t, func() error { return push(t.Context(), "team-a/app", []byte("12345678")) })
+
+		Then(t, "用量为 Blob 大小",
+			Expect(used("team-a"), Equal(int64(8))),
+		)
+	})
+
+	t.Run("提交超出配额", func(t *testing.T) {
+		err := push(t.Context(), "team-a/other", []byte("abc"))
+
+		exceeded, ok := errors.AsType[*v2.ErrQuotaExceeded](err)
+
+		Then(t, "返回 DENIED 且不计入用量",
+			Expect(ok, Equal(true)),
+			Expect(exceeded.ErrCode(), Equal("DENIED")),
+			Expect(exceeded.Prefix, Equal("team-a")),
+			Expect(used("team-a"), Equal(int64(8))),
+		)
+
+		stored := false
+		for dgst, err := range ns.(content.DigestIterable).Digests(t.Context()) {
+			if err == nil && dgst == digest.FromBytes([]byte("abc")) {
+				stored = true
+			}
+		}
+
+		Then(t, "超出配额的 Blob 不落盘",
+			Expect(stored, Equal(false)),
+		)
+	})
+
+	t.Run("跨前缀挂载", func(t *testing.T) {
+		Must(t, func() error {
+			_, err := blobs("team-b/app").(content.Mounter).Mount(t.Context(), named("team-a/app"), digest.FromBytes([]byte("12345678")))
+			return err
+		})
+
+		Then(t, "计入目标前缀",
+			Expect(used("team-b"), Equal(int64(8))),
+			Expect(used("team-a"), Equal(int64(8))),
+		)
+	})
+
+	t.Run("删除关联后释放", func(t *testing.T) {
+		Must(t, func() error {
+			return blobs("team-b/app").Remove(t.Context(), digest.FromBytes([]byte("12345678")))
+		})
+
+		Then(t, "用量归零",
+			Expect(used("team-b"), Equal(int64(0))),
+		)
+	})
+
+	t.Run("校正并恢复用量", func(t *testing.T) {
+		Must(t, func() error {
+			return q.Reconcile(t.Context(), func(ctx context.Context, account quota.AccountFunc) error {
+				account(named("team-a/app"), digest.FromString("1"), 3)
+				account(named("team-a/app"), digest.FromString("2"), 2)
+				account(named("team-a/other"), digest.FromString("3"), 3)
+				account(named("team-ab/app"), digest.FromString("4"), 7)
+				account(named("team-b/app"), digest.FromString("5"), 9)
+
+				// 扫描期间的变更覆盖扫描结果
+				if err := q.Release(ctx, named("team-b/app"), digest.FromString("5"), 9); err != nil {
+					return err
+				}
+				return q.Charge(ctx, named("team-b/app"), digest.FromString("6"), 4)
+			})
+		})
+
+		reopened := MustValue(t, func() (quota.Quota, error) {
+			return quota.New(t.Context(), d, quota.DefaultFile, c)
+		})
+
+		usages := MustValue(t, func() ([]quota.Usage, error) {
+			return reopened.Usages(t.Context())
+		})
+
+		Then(t, "仅累计匹配前缀的仓库",
+			Expect(usages, Equal([]quota.Usage{
+				{Prefix: "team-a", Limit: 10, Used: 8},
+				{Prefix: "team-b", Limit: 100, Used: 4},
+			})),
+		)
+	})
+}
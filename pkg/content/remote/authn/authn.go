@@ -27,25 +27,156 @@ type Token struct {
 
 type TokenGetFunc = func() (*Token, error)
 
+// Credential 换取 token 的凭证
+type Credential struct {
+	ClientID     string
+	ClientSecret string
+	// 声明时以 OAuth2 POST（grant_type=refresh_token）换取 token，如 Docker 的 identity token
+	RefreshToken string
+}
+
+// Authn 注册表认证
+//
+// 首次请求前探测 CheckEndpoint 的认证方式并缓存：无需认证时直接放行；Basic 时附加凭证；
+// Bearer 时按请求的 scope 换取 token（无凭证时匿名换取）。
+// 请求返回 401 的 Bearer 挑战（如 insufficient_scope）时按挑战的 scope 重新换取 token 并重试一次
 type Authn struct {
 	CheckEndpoint string
 	ClientID      string
 	ClientSecret  string
+	// 声明时以 OAuth2 POST 换取 token
+	RefreshToken string
+	// 声明时每次换取 token 前调用以获取凭证，取代 ClientID、ClientSecret 与 RefreshToken；返回 nil 时匿名换取
+	Credential func(ctx context.Context) (*Credential, error)
+
+	mu sync.Mutex
+	// 探测到的认证方式，AuthType 为空表示无需认证
+	challenge *WwwAuthenticate
 
 	scopeTokens Map[string, TokenGetFunc]
 }
 
-func (a *Authn) exchangeToken(ctx context.Context, realm *url.URL) (*Token, error) {
-	c := client.GetReasonableClientContext(ctx)
+func (a *Authn) credential(ctx context.Context) (*Credential, error) {
+	if a.Credential != nil {
+		c, err := a.Credential(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if c == nil {
+			return &Credential{}, nil
+		}
+		return c, nil
+	}
+
+	return &Credential{
+		ClientID:     a.ClientID,
+		ClientSecret: a.ClientSecret,
+		RefreshToken: a.RefreshToken,
+	}, nil
+}
+
+// discover 探测并缓存认证方式，失败时不缓存
+func (a *Authn) discover(ctx context.Context, next client.RoundTrip) (*WwwAuthenticate, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.challenge != nil {
+		return a.challenge, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.CheckEndpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := next(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(resp.Body)
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		c, ok := parseChallenge(resp.Header.Get("WWW-Authenticate"))
+		if !ok {
+			return nil, &ErrUnauthorized{Reason: errors.New(string(data))}
+		}
+		a.challenge = c
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return nil, fmt.Errorf("check %s failed: %d %s", a.CheckEndpoint, resp.StatusCode, string(data))
+	default:
+		// 根路径不要求认证时，仓库请求返回的挑战由重试处理
+		a.challenge = &WwwAuthenticate{}
+	}
+
+	return a.challenge, nil
+}
+
+func (a *Authn) rechallenge(c *WwwAuthenticate) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.challenge = c
+}
+
+func (a *Authn) exchangeToken(ctx context.Context, next client.RoundTrip, c *WwwAuthenticate, scope string) (*Token, error) {
+	realm, err := url.Parse(c.Params["realm"])
+	if err != nil || realm.Host == "" {
+		return nil, &ErrUnauthorized{Reason: fmt.Errorf("invalid realm %q", c.Params["realm"])}
+	}
 
-	req, err := http.NewRequest(http.MethodGet, realm.String(), nil)
+	cred, err := a.credential(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	req.SetBasicAuth(a.ClientID, a.ClientSecret)
+	var req *http.Request
 
-	resp, err := c.Do(req)
+	if cred.RefreshToken != "" {
+		form := url.Values{}
+		form.Set("grant_type", "refresh_token")
+		form.Set("refresh_token", cred.RefreshToken)
+		form.Set("client_id", "crkit")
+		if service := c.Params["service"]; service != "" {
+			form.Set("service", service)
+		}
+		if scope != "" {
+			form.Set("scope", scope)
+		}
+
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, realm.String(), strings.NewReader(form.Encode()))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	} else {
+		q := realm.Query()
+		for k, v := range c.Params {
+			switch k {
+			case "realm", "scope", "error":
+			default:
+				q.Set(k, v)
+			}
+		}
+		for _, s := range strings.Fields(scope) {
+			q.Add("scope", s)
+		}
+		realm.RawQuery = q.Encode()
+
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		// 无凭证时匿名换取
+		if cred.ClientID != "" {
+			req.SetBasicAuth(cred.ClientID, cred.ClientSecret)
+		}
+	}
+
+	resp, err := next(req)
 	if err != nil {
 		return nil, err
 	}
@@ -92,107 +223,213 @@ func (a *Authn) exchangeToken(ctx context.Context, realm *url.URL) (*Token, erro
 	}
 }
 
-func (a *Authn) getToken(ctx context.Context, name string, actions []string) (*Token, error) {
-	scope := fmt.Sprintf("repository:%s:%s", name, strings.Join(actions, ","))
+// tokenExchangeTimeout 单次换取 token 的超时
+const tokenExchangeTimeout = 30 * time.Second
 
+// getToken 按 scope 缓存 token，过期后重新换取
+//
+// 同一 scope 的并发请求共用一次换取，换取不随首个请求取消，调用方取消时仅自身返回
+func (a *Authn) getToken(ctx context.Context, next client.RoundTrip, c *WwwAuthenticate, scope string) (*Token, error) {
 	getToken, _ := a.scopeTokens.LoadOrStore(scope, sync.OnceValues(func() (*Token, error) {
-		c := client.GetReasonableClientContext(ctx)
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), tokenExchangeTimeout)
+		defer cancel()
 
-		req, err := http.NewRequest(http.MethodGet, a.CheckEndpoint, nil)
-		if err != nil {
-			return nil, err
-		}
-		resp, err := c.Do(req)
-		if err != nil {
-			return nil, err
-		}
-		data, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, err
-		}
-
-		if resp.StatusCode == http.StatusUnauthorized {
-			if wwwAuthenticate := resp.Header.Get("WWW-Authenticate"); wwwAuthenticate != "" {
-				parsed, err := ParseWwwAuthenticate(wwwAuthenticate)
-				if err == nil && parsed.Params != nil {
-					realm, ok := parsed.Params["realm"]
-					if ok && realm != "" {
-						realmUrl, err := url.Parse(realm)
-						if err == nil {
-							q := &url.Values{}
-							q.Set("scope", scope)
-
-							for k, v := range parsed.Params {
-								if k != "realm" {
-									q.Set(k, v)
-								}
-							}
-							realmUrl.RawQuery = q.Encode()
-
-							return a.exchangeToken(ctx, realmUrl)
-						}
-					}
-				}
-			}
-		}
-
-		return nil, &ErrUnauthorized{
-			Reason: errors.New(string(data)),
-		}
+		return a.exchangeToken(ctx, next, c, scope)
 	}))
 
-	tok, err := getToken()
+	var (
+		tok  *Token
+		err  error
+		done = make(chan struct{})
+	)
+
+	go func() {
+		defer close(done)
+		tok, err = getToken()
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-done:
+	}
+
 	if err != nil {
+		// 失败不缓存
+		a.scopeTokens.Delete(scope)
 		return nil, err
 	}
 
 	if tok.ExpiredAt.Before(time.Now()) {
 		a.scopeTokens.Delete(scope)
 		// retry
-		return a.getToken(ctx, name, actions)
+		return a.getToken(ctx, next, c, scope)
 	}
 
 	return tok, nil
 }
 
+func (a *Authn) authorize(ctx context.Context, next client.RoundTrip, req *http.Request, c *WwwAuthenticate, scope string) error {
+	switch strings.ToLower(c.AuthType) {
+	case "basic":
+		cred, err := a.credential(ctx)
+		if err != nil {
+			return err
+		}
+		if cred.ClientID != "" {
+			req.SetBasicAuth(cred.ClientID, cred.ClientSecret)
+		}
+	case "bearer":
+		tok, err := a.getToken(ctx, next, c, scope)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", tok.AccessToken))
+	}
+	return nil
+}
+
 func (a *Authn) AsHttpTransport() client.HttpTransport {
 	return client.HttpTransportFunc(func(req *http.Request, next client.RoundTrip) (*http.Response, error) {
-		repository := ""
-		actions := make([]string, 0)
-
-		l := strings.Index(req.URL.Path, "/v2/")
-		if l > -1 {
-			path := req.URL.Path[l+len("/v2/"):]
-
-			for _, v := range []string{
-				"/manifests/",
-				"/blobs/",
-				"/tags/",
-			} {
-				if r := strings.Index(path, v); r > 0 {
-					repository = path[0:r]
-
-					actions = append(actions, "pull")
-
-					switch req.Method {
-					case http.MethodPut, http.MethodPost:
-						actions = append(actions, "push")
-					}
-
-					break
-				}
-			}
+		// 仅认证发往注册表的请求，Blob 重定向等其他主机的请求直接发送
+		if check, err := url.Parse(a.CheckEndpoint); err == nil && check.Host != req.URL.Host {
+			return next(req)
 		}
 
-		tok, err := a.getToken(req.Context(), repository, actions)
+		ctx := req.Context()
+
+		c, err := a.discover(ctx, next)
 		if err != nil {
 			return nil, err
 		}
 
-		if tok != nil {
-			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", tok.AccessToken))
+		scope := requestScope(req)
+
+		r := req.Clone(ctx)
+		if err := a.authorize(ctx, next, r, c, scope); err != nil {
+			return nil, err
+		}
+
+		resp, err := next(r)
+		if err != nil || resp.StatusCode != http.StatusUnauthorized {
+			return resp, err
+		}
+
+		challenged, ok := parseChallenge(resp.Header.Get("WWW-Authenticate"))
+		if !ok || !strings.EqualFold(challenged.AuthType, "bearer") {
+			return resp, nil
 		}
 
-		return next(req)
+		retry, ok := rewind(req)
+		if !ok {
+			return resp, nil
+		}
+
+		_ = resp.Body.Close()
+
+		// 挑战中的 scope 与 error 仅针对本次请求，缓存前移除
+		a.rechallenge(challenged.withoutRequestParams())
+		a.scopeTokens.Delete(scope)
+
+		if s := challenged.Params["scope"]; s != "" {
+			scope = s
+			a.scopeTokens.Delete(scope)
+		}
+
+		if err := a.authorize(ctx, next, retry, challenged, scope); err != nil {
+			return nil, err
+		}
+
+		return next(retry)
 	})
 }
+
+// rewind 复制请求用于重试，请求体不可重放时返回 false
+func rewind(req *http.Request) (*http.Request, bool) {
+	r := req.Clone(req.Context())
+
+	if req.Body == nil || req.Body == http.NoBody {
+		return r, true
+	}
+
+	if req.GetBody == nil {
+		return nil, false
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, false
+	}
+	r.Body = body
+
+	return r, true
+}
+
+// requestScope 按请求路径与方法生成 token scope
+func requestScope(req *http.Request) string {
+	l := strings.Index(req.URL.Path, "/v2/")
+	if l < 0 {
+		return ""
+	}
+
+	p := req.URL.Path[l+len("/v2/"):]
+
+	if p == "_catalog" {
+		return "registry:catalog:*"
+	}
+
+	for _, v := range []string{
+		"/manifests/",
+		"/blobs/",
+		"/tags/",
+	} {
+		if r := strings.Index(p, v); r > 0 {
+			actions := "pull"
+
+			switch req.Method {
+			case http.MethodPut, http.MethodPost, http.MethodPatch:
+				actions = "pull,push"
+			case http.MethodDelete:
+				actions = "delete"
+			}
+
+			return fmt.Sprintf("repository:%s:%s", p[0:r], actions)
+		}
+	}
+
+	return ""
+}
+
+func parseChallenge(header string) (*WwwAuthenticate, bool) {
+	if header == "" {
+		return nil, false
+	}
+
+	if !strings.Contains(header, " ") {
+		return &WwwAuthenticate{AuthType: header}, true
+	}
+
+	c, err := ParseWwwAuthenticate(header)
+	if err != nil {
+		return nil, false
+	}
+
+	return c, true
+}
+
+func (v *WwwAuthenticate) withoutRequestParams() *WwwAuthenticate {
+	c := &WwwAuthenticate{
+		AuthType: v.AuthType,
+		Params:   map[string]string{},
+	}
+
+	for k, p := range v.Params {
+		switch k {
+		case "scope", "error":
+		default:
+			c.Params[k] = p
+		}
+	}
+
+	return c
+}
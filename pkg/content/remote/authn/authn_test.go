@@ -0,0 +1,181 @@
+package authn
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	. "github.com/octohelm/x/testing/v2"
+)
+
+// newRegistry token 为 "token:{scope}"，仓库 app 需同时具有 app 与 base 的 pull 权限
+func newRegistry(t *testing.T, tokenRequests chan<- *http.Request) (*httptest.Server, *atomic.Int32) {
+	checks := &atomic.Int32{}
+
+	var s *httptest.Server
+
+	s = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		challenge := fmt.Sprintf("Bearer realm=%q,service=%q", s.URL+"/token", "test")
+
+		switch req.URL.Path {
+		case "/token":
+			_ = req.ParseForm()
+			tokenRequests <- req
+
+			scope := strings.Join(req.Form["scope"], " ")
+			if req.Method == http.MethodPost {
+				if req.PostForm.Get("grant_type") != "refresh_token" || req.PostForm.Get("refresh_token") != "refresh" {
+					rw.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				scope = req.PostForm.Get("scope")
+			}
+
+			_ = json.NewEncoder(rw).Encode(&Token{AccessToken: "token:" + scope})
+			return
+		case "/v2/":
+			checks.Add(1)
+		}
+
+		required := "repository:lib:pull"
+		if strings.HasPrefix(req.URL.Path, "/v2/app/") {
+			required = "repository:app:pull repository:base:pull"
+		}
+
+		auth := req.Header.Get("Authorization")
+
+		switch {
+		case auth == "":
+			rw.Header().Set("WWW-Authenticate", challenge)
+			rw.WriteHeader(http.StatusUnauthorized)
+		case auth != "Bearer token:"+required && req.URL.Path != "/v2/":
+			rw.Header().Set("WWW-Authenticate", challenge+fmt.Sprintf(",scope=%q,error=%q", required, "insufficient_scope"))
+			rw.WriteHeader(http.StatusUnauthorized)
+		default:
+			rw.WriteHeader(http.StatusOK)
+		}
+	}))
+	t.Cleanup(s.Close)
+
+	return s, checks
+}
+
+func TestAuthn(t *testing.T) {
+	t.Run("匿名换取 token", func(t *testing.T) {
+		tokenRequests := make(chan *http.Request, 10)
+		s, checks := newRegistry(t, tokenRequests)
+
+		a := &Authn{CheckEndpoint: s.URL + "/v2/"}
+		c := &http.Client{Transport: a.AsHttpTransport()(http.DefaultTransport)}
+
+		status := func(path string) (int, error) {
+			resp, err := c.Get(s.URL + path)
+			if err != nil {
+				return 0, err
+			}
+			defer resp.Body.Close()
+			return resp.StatusCode, nil
+		}
+
+		Then(t, "请求成功",
+			ExpectMustValue(func() (int, error) {
+				return status("/v2/lib/manifests/latest")
+			}, Equal(http.StatusOK)),
+		)
+
+		req := <-tokenRequests
+
+		Then(t, "不携带凭证，附带挑战中的 service 与请求的 scope",
+			Expect(req.Header.Get("Authorization"), Equal("")),
+			Expect(req.URL.Query().Get("service"), Equal("test")),
+			Expect(req.URL.Query().Get("scope"), Equal("repository:lib:pull")),
+		)
+
+		t.Run("权限不足时按挑战的 scope 重新换取", func(t *testing.T) {
+			Then(t, "透明重试成功，认证方式仅探测一次",
+				ExpectMustValue(func() (int, error) {
+					return status("/v2/app/manifests/latest")
+				}, Equal(http.StatusOK)),
+				ExpectMustValue(func() (int, error) {
+					return status("/v2/lib/blobs/sha256:0000000000000000000000000000000000000000000000000000000000000000")
+				}, Equal(http.StatusOK)),
+				Expect(checks.Load(), Equal(int32(1))),
+			)
+		})
+	})
+
+	t.Run("以 refresh token 通过 POST 换取", func(t *testing.T) {
+		tokenRequests := make(chan *http.Request, 10)
+		s, _ := newRegistry(t, tokenRequests)
+
+		a := &Authn{CheckEndpoint: s.URL + "/v2/", RefreshToken: "refresh"}
+		c := &http.Client{Transport: a.AsHttpTransport()(http.DefaultTransport)}
+
+		Then(t, "请求成功",
+			ExpectMustValue(func() (int, error) {
+				resp, err := c.Get(s.URL + "/v2/lib/manifests/latest")
+				if err != nil {
+					return 0, err
+				}
+				defer resp.Body.Close()
+				return resp.StatusCode, nil
+			}, Equal(http.StatusOK)),
+		)
+
+		req := <-tokenRequests
+
+		Then(t, "使用 OAuth2 POST",
+			Expect(req.Method, Equal(http.MethodPost)),
+			Expect(req.PostForm.Get("service"), Equal("test")),
+			Expect(req.PostForm.Get("scope"), Equal("repository:lib:pull")),
+		)
+	})
+	t.Run("首个请求取消时不影响共用的换取", func(t *testing.T) {
+		tokenRequests := make(chan *http.Request, 10)
+		release := make(chan struct{})
+
+		s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			tokenRequests <- req
+			<-release
+			_ = json.NewEncoder(rw).Encode(&Token{AccessToken: "token"})
+		}))
+		t.Cleanup(s.Close)
+
+		a := &Authn{CheckEndpoint: s.URL + "/v2/"}
+		c := &WwwAuthenticate{AuthType: "Bearer", Params: map[string]string{"realm": s.URL + "/token"}}
+
+		ctx, cancel := context.WithCancel(t.Context())
+
+		canceled := make(chan error, 1)
+		go func() {
+			_, err := a.getToken(ctx, http.DefaultTransport.RoundTrip, c, "repository:lib:pull")
+			canceled <- err
+		}()
+
+		<-tokenRequests
+		cancel()
+
+		Then(t, "取消的请求返回自身的 ctx 错误",
+			Expect(errors.Is(<-canceled, context.Canceled), Equal(true)),
+		)
+
+		close(release)
+
+		Then(t, "之后的请求获得同一次换取的 token",
+			ExpectMustValue(func() (string, error) {
+				tok, err := a.getToken(t.Context(), http.DefaultTransport.RoundTrip, c, "repository:lib:pull")
+				if err != nil {
+					return "", err
+				}
+				return tok.AccessToken, nil
+			}, Equal("token")),
+			Expect(len(tokenRequests), Equal(0)),
+		)
+	})
+}
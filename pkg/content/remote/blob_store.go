@@ -6,12 +6,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
 	"sync"
-	"sync/atomic"
 
 	"github.com/distribution/reference"
 	"github.com/opencontainers/go-digest"
@@ -19,6 +19,7 @@ import (
 	"github.com/octohelm/courier/pkg/courier"
 	"github.com/octohelm/courier/pkg/statuserror"
 	"github.com/octohelm/unifs/pkg/units"
+	"github.com/octohelm/x/logr"
 
 	manifestv1 "github.com/octohelm/crkit/pkg/apis/manifest/v1"
 	"github.com/octohelm/crkit/pkg/apis/registry/v2"
@@ -74,17 +75,44 @@ func (bs *blobStore) Open(ctx context.Context, dgst digest.Digest) (r io.ReadClo
 		GetBlob: req,
 		ctx:     ctx,
 		client:  bs.client,
+		length:  -1,
 	}, nil
 }
 
+var _ content.RangeProvider = &blobStore{}
+
+func (bs *blobStore) OpenRange(ctx context.Context, dgst digest.Digest, offset int64, length int64) (io.ReadCloser, error) {
+	req := &endpointsv2.GetBlob{}
+	req.Name = v2.Name(bs.named.Name())
+	req.Digest = v2.Digest(dgst)
+
+	if length < 0 {
+		req.Range = fmt.Sprintf("bytes=%d-", offset)
+	} else {
+		req.Range = fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)
+	}
+
+	return &blobReader{
+		GetBlob: req,
+		ctx:     ctx,
+		client:  bs.client,
+		offset:  offset,
+		length:  length,
+	}, nil
+}
+
+// blobReader 连接中断时以 Range 从已接收的偏移处续传，完整读取时校验摘要
 type blobReader struct {
 	*endpointsv2.GetBlob
 	ctx    context.Context
 	client courier.Client
 
+	// 请求的起始偏移与长度，length 为 -1 时读取至末尾
+	offset int64
+	length int64
+
 	pr   *io.PipeReader
 	once sync.Once
-	err  atomic.Pointer[error]
 }
 
 func (b *blobReader) Read(p []byte) (int, error) {
@@ -93,18 +121,10 @@ func (b *blobReader) Read(p []byte) (int, error) {
 		b.pr = pr
 
 		go func() {
-			defer pw.Close()
-
-			if _, err := b.client.Do(b.ctx, b.GetBlob).Into(pw); err != nil {
-				b.err.Store(&err)
-			}
+			_ = pw.CloseWithError(b.download(pw))
 		}()
 	})
 
-	if err := b.err.Load(); err != nil {
-		return -1, *err
-	}
-
 	return b.pr.Read(p)
 }
 
@@ -115,6 +135,147 @@ func (b *blobReader) Close() error {
 	return nil
 }
 
+func (b *blobReader) download(w io.Writer) error {
+	var policy *RetryPolicy
+	if c, ok := b.client.(*Client); ok {
+		policy = c.retry
+	}
+
+	var verifier digest.Verifier
+	if b.offset == 0 && b.length < 0 {
+		if dgst, err := digest.Parse(string(b.Digest)); err == nil {
+			verifier = dgst.Verifier()
+		}
+	}
+
+	cw := &countingWriter{w: w}
+	if verifier != nil {
+		cw.w = io.MultiWriter(w, verifier)
+	}
+
+	failures := 0
+
+	for {
+		req := *b.GetBlob
+
+		if cw.n > 0 {
+			req.Range = b.rangeFrom(cw.n)
+		}
+
+		received := cw.n
+
+		// 续传须为 206 且 Content-Range 自已接收处开始，否则内容错位
+		err := b.fetch(&req, cw, cw.n > 0 || b.GetBlob.Range != "")
+		if err == nil {
+			break
+		}
+
+		// 有进展时重新计数，仅连续无进展的中断计入重试次数
+		if cw.n > received {
+			failures = 0
+		}
+
+		if !policy.enabled() || failures >= policy.MaxRetries || b.ctx.Err() != nil || !isConnectionError(err) {
+			return err
+		}
+
+		d := policy.backoff(failures)
+		failures++
+
+		logr.FromContext(b.ctx).
+			WithValues(
+				slog.String("name", string(b.Name)),
+				slog.String("digest", string(b.Digest)),
+				slog.Int64("offset", b.offset+cw.n),
+				slog.String("backoff", d.String()),
+			).
+			Warn(fmt.Errorf("blob download interrupted, resume: %w", err))
+
+		if err := policy.wait(b.ctx, d); err != nil {
+			return err
+		}
+	}
+
+	// 续传的内容与首次请求不一致时由摘要校验发现
+	if verifier != nil && !verifier.Verified() {
+		return &v2.ErrBlobInvalidDigest{
+			Digest: digest.Digest(b.Digest),
+			Reason: fmt.Errorf("content of %d bytes not match", cw.n),
+		}
+	}
+
+	return nil
+}
+
+// fetch 读取响应；checkRange 时先校验响应范围再写入，避免错位的内容混入
+func (b *blobReader) fetch(req *endpointsv2.GetBlob, w io.Writer, checkRange bool) error {
+	var body io.ReadCloser
+
+	meta, err := b.client.Do(b.ctx, req).Into(&body)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	r := io.Reader(body)
+
+	if checkRange {
+		r, err = b.rangeBody(req.Range, meta.Get("Content-Range"), body)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = io.Copy(w, r)
+	return err
+}
+
+// rangeBody 校验 206 响应的 Content-Range 起始偏移与请求一致（成功响应仅 206 携带 Content-Range）；
+// 服务端忽略 Range 返回完整内容时，仅自起始处读取的请求截取所需长度，否则报错
+func (b *blobReader) rangeBody(requested string, contentRange string, body io.Reader) (io.Reader, error) {
+	start, _, _ := strings.Cut(strings.TrimPrefix(requested, "bytes="), "-")
+
+	if contentRange == "" {
+		if start != "0" {
+			return nil, fmt.Errorf("range %s not honored by upstream", requested)
+		}
+		if b.length >= 0 {
+			return io.LimitReader(body, b.length), nil
+		}
+		return body, nil
+	}
+
+	spec, ok := strings.CutPrefix(contentRange, "bytes ")
+	if !ok {
+		return nil, fmt.Errorf("invalid Content-Range %q for range %s", contentRange, requested)
+	}
+
+	if s, _, _ := strings.Cut(spec, "-"); s != start {
+		return nil, fmt.Errorf("content range %q not match range %s", contentRange, requested)
+	}
+
+	return body, nil
+}
+
+// rangeFrom 从已接收 n 字节后续传的 Range
+func (b *blobReader) rangeFrom(n int64) string {
+	if b.length < 0 {
+		return fmt.Sprintf("bytes=%d-", b.offset+n)
+	}
+	return fmt.Sprintf("bytes=%d-%d", b.offset+n, b.offset+b.length-1)
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
 var _ content.Remover = &blobStore{}
 
 func (bs *blobStore) Remove(ctx context.Context, dgst digest.Digest) error {
@@ -126,6 +287,44 @@ func (bs *blobStore) Remove(ctx context.Context, dgst digest.Digest) error {
 	return err
 }
 
+var _ content.Mounter = &blobStore{}
+
+func (bs *blobStore) Mount(ctx context.Context, from reference.Named, dgst digest.Digest) (*manifestv1.Descriptor, error) {
+	req := &endpointsv2.CreateBlobUpload{}
+	req.Name = v2.Name(bs.named.Name())
+	req.Mount = v2.Digest(dgst)
+	req.From = v2.Name(from.Name())
+
+	_, meta, err := Do(ctx, bs.client, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// upstream fallback to upload session when mount not supported or blob not found in from
+	if location := meta.Get("Location"); strings.Contains(location, "/blobs/uploads/") {
+		bw := &blobWriter{
+			ctx:       ctx,
+			blobStore: bs,
+			chunk:     bytes.NewBuffer(nil),
+		}
+
+		if err := bw.syncFromMeta(0, meta); err != nil {
+			return nil, err
+		}
+
+		if err := bw.Cancel(ctx); err != nil {
+			return nil, err
+		}
+
+		return nil, &v2.ErrManifestBlobUnknown{
+			Name:   from.Name(),
+			Digest: dgst,
+		}
+	}
+
+	return bs.Info(ctx, dgst)
+}
+
 func (bs *blobStore) Resume(ctx context.Context, id string) (content.BlobWriter, error) {
 	bw := &blobWriter{
 		ctx:       ctx,
@@ -134,6 +333,12 @@ func (bs *blobStore) Resume(ctx context.Context, id string) (content.BlobWriter,
 	}
 	bw.location = fmt.Sprintf("/v2/%s/blobs/uploads/%s", bw.named.Name(), id)
 	bw.id = id
+
+	// 从服务端已接收的偏移处继续
+	if err := bw.syncFromBlobUpload(ctx); err != nil {
+		return nil, err
+	}
+
 	return bw, nil
 }
 
@@ -159,6 +364,17 @@ func (bs *blobStore) Writer(ctx context.Context) (content.BlobWriter, error) {
 	return bw, nil
 }
 
+// DefaultUploadChunkSize 未通过 WithUploadChunkSize 声明时的分块大小
+const DefaultUploadChunkSize = int64(20 * units.MiB)
+
+// WithUploadChunkSize 推送 Blob 时每个 PATCH 请求的分块大小，适用于限制请求大小的代理；
+// 服务端声明的 OCI-Chunk-Min-Length 更大时以其为准
+func WithUploadChunkSize(size int64) Option {
+	return func(n *namespace) {
+		n.uploadChunkSize = size
+	}
+}
+
 var _ content.BlobWriter = &blobWriter{}
 
 type blobWriter struct {
@@ -202,17 +418,40 @@ func (bw *blobWriter) endpoint() string {
 	return location
 }
 
+// chunkSize 协商后的分块大小，不小于服务端声明的 OCI-Chunk-Min-Length
+func (bw *blobWriter) chunkSize() int64 {
+	size := DefaultUploadChunkSize
+	if c, ok := bw.client.(*Client); ok && c.uploadChunkSize > 0 {
+		size = c.uploadChunkSize
+	}
+	return max(size, bw.chunkMinLength)
+}
+
+// Write 按分块大小逐个发送，余下部分留待后续写入或提交
+//
+// 发送失败时返回 p 中服务端已接收的字节数，未接收的部分不保留，由调用方重新写入
 func (bw *blobWriter) Write(p []byte) (int, error) {
-	n := len(p)
+	buffered := int64(bw.chunk.Len())
+	offset := bw.written
+
+	bw.chunk.Write(p)
+
+	for size := bw.chunkSize(); int64(bw.chunk.Len()) >= size; {
+		start := bw.written
 
-	bw.chunk.Write(p[:n])
+		if err := bw.sendChunk(bw.ctx, bw.chunk.Bytes()[:size]); err != nil {
+			bw.chunk.Next(int(min(max(bw.written-start, 0), size)))
 
-	if int64(bw.chunk.Len()) >= bw.chunkMinLength {
-		if err := bw.sendChunkIfNeed(bw.ctx); err != nil {
-			return -1, err
+			accepted := bw.written - offset
+			bw.chunk.Truncate(int(min(max(buffered-accepted, 0), int64(bw.chunk.Len()))))
+
+			return int(min(max(accepted-buffered, 0), int64(len(p)))), err
 		}
+
+		bw.chunk.Next(int(size))
 	}
-	return n, nil
+
+	return len(p), nil
 }
 
 func (bw *blobWriter) Cancel(ctx context.Context) error {
@@ -308,40 +547,106 @@ func (bw *blobWriter) sendChunkIfNeed(ctx context.Context) error {
 		return nil
 	}
 
-	return bw.sendChunk(ctx, false)
+	if err := bw.sendChunk(ctx, bw.chunk.Bytes()); err != nil {
+		return err
+	}
+
+	bw.chunk.Reset()
+
+	return nil
 }
 
-func (bw *blobWriter) sendChunk(ctx context.Context, retry bool) error {
-	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, bw.endpoint(), bw.chunk)
+// sendChunk 以 PATCH 发送 data，失败时查询上传进度并从服务端已接收的偏移处续传
+//
+// 未启用重试时仅在 416 时续传一次
+func (bw *blobWriter) sendChunk(ctx context.Context, data []byte) error {
+	var policy *RetryPolicy
+	if c, ok := bw.client.(*Client); ok {
+		policy = c.retry
+	}
+
+	retries := 1
+	if policy.enabled() {
+		retries = policy.MaxRetries
+	}
+
+	start := bw.written
+
+	for failures := 0; ; failures++ {
+		rest := data[bw.written-start:]
+		if len(rest) == 0 {
+			return nil
+		}
+
+		err := bw.patch(ctx, rest)
+		if err == nil {
+			return nil
+		}
+
+		if failures >= retries || ctx.Err() != nil || !isResumableUploadError(err, policy.enabled()) {
+			return err
+		}
+
+		if policy.enabled() {
+			d := policy.backoff(failures)
+
+			logr.FromContext(ctx).
+				WithValues(
+					slog.String("name", bw.named.Name()),
+					slog.String("upload.id", bw.id),
+					slog.Int64("offset", bw.written),
+					slog.String("backoff", d.String()),
+				).
+				Warn(fmt.Errorf("blob upload interrupted, resume: %w", err))
+
+			if err := policy.wait(ctx, d); err != nil {
+				return err
+			}
+		}
+
+		if err := bw.syncFromBlobUpload(ctx); err != nil {
+			return err
+		}
+
+		if bw.written < start || bw.written > start+int64(len(data)) {
+			return fmt.Errorf("upload %s at offset %d out of chunk [%d, %d): %w", bw.id, bw.written, start, start+int64(len(data)), err)
+		}
+	}
+}
+
+func (bw *blobWriter) patch(ctx context.Context, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, bw.endpoint(), bytes.NewReader(data))
 	if err != nil {
 		return err
 	}
 
-	n := int64(bw.chunk.Len())
+	n := int64(len(data))
 
 	bw.patchRequestContentLength(req, n)
 
 	meta, err := bw.client.Do(ctx, req).Into(nil)
 	if err != nil {
-		if !retry {
-			d := &statuserror.Descriptor{}
-			if errors.As(err, &d) {
-				if d.Status == http.StatusRequestedRangeNotSatisfiable {
-					if err := bw.syncFromBlobUpload(ctx); err != nil {
-						return err
-					}
-					return bw.sendChunk(ctx, true)
-				}
-			}
-		}
 		return err
 	}
 
-	bw.chunk.Reset()
-
 	return bw.syncFromMeta(n, meta)
 }
 
+// isResumableUploadError 416 表示偏移不一致；启用重试时连接错误、5xx 与 429 亦可续传
+func isResumableUploadError(err error, retry bool) bool {
+	if d, ok := errors.AsType[*statuserror.Descriptor](err); ok {
+		switch status := d.StatusCode(); {
+		case status == http.StatusRequestedRangeNotSatisfiable:
+			return true
+		case retry:
+			return isRetryableStatus(status)
+		}
+		return false
+	}
+
+	return retry && isConnectionError(err)
+}
+
 func (bw *blobWriter) syncFromBlobUpload(ctx context.Context) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, bw.endpoint(), nil)
 	if err != nil {
@@ -364,10 +669,6 @@ func (bw *blobWriter) syncFromMeta(n int64, meta courier.Metadata) error {
 		}
 	}
 
-	if bw.chunkMinLength == 0 {
-		bw.chunkMinLength = int64(20 * units.MiB)
-	}
-
 	if location := meta.Get("Location"); location != "" {
 		bw.location = location
 
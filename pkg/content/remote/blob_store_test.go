@@ -0,0 +1,323 @@
+package remote_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/distribution/reference"
+	"github.com/opencontainers/go-digest"
+
+	"github.com/octohelm/unifs/pkg/units"
+	. "github.com/octohelm/x/testing/v2"
+
+	manifestv1 "github.com/octohelm/crkit/pkg/apis/manifest/v1"
+	"github.com/octohelm/crkit/pkg/content"
+	contentremote "github.com/octohelm/crkit/pkg/content/remote"
+	contenttestutil "github.com/octohelm/crkit/pkg/content/testutil"
+)
+
+func TestChunkedUpload(t *testing.T) {
+	ctx := t.Context()
+
+	data := make([]byte, 100*units.KiB)
+	_, _ = rand.Read(data)
+	dgst := digest.FromBytes(data)
+
+	minLength := 32 * units.KiB
+
+	registry := contenttestutil.NewRegistry(t)
+
+	mu := sync.Mutex{}
+	patches := make([]int, 0)
+	contentRanges := make([]string, 0)
+
+	// 服务端声明最小分块；首个 PATCH 仅转发部分内容后中断连接
+	s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodPost:
+			rw.Header().Set("OCI-Chunk-Min-Length", strconv.Itoa(int(minLength)))
+		case http.MethodPatch:
+			chunk, _ := io.ReadAll(req.Body)
+
+			mu.Lock()
+			patches = append(patches, len(chunk))
+			contentRanges = append(contentRanges, req.Header.Get("Content-Range"))
+			first := len(patches) == 1
+			mu.Unlock()
+
+			if first {
+				partial := chunk[:10*units.KiB]
+
+				r := req.Clone(req.Context())
+				r.Body = io.NopCloser(bytes.NewReader(partial))
+				r.ContentLength = int64(len(partial))
+				registry.ServeHTTP(httptest.NewRecorder(), r)
+
+				panic(http.ErrAbortHandler)
+			}
+
+			req.Body = io.NopCloser(bytes.NewReader(chunk))
+		}
+
+		registry.ServeHTTP(rw, req)
+	}))
+	t.Cleanup(s.Close)
+
+	blobs := MustValue(t, func() (content.BlobStore, error) {
+		ns, err := contentremote.New(ctx, contentremote.Registry{Endpoint: s.URL},
+			contentremote.WithUploadChunkSize(int64(16*units.KiB)),
+			contentremote.WithRetry(contentremote.RetryPolicy{
+				MaxRetries: 3,
+				Backoff:    time.Millisecond,
+			}),
+		)
+		if err != nil {
+			return nil, err
+		}
+		named, err := reference.WithName("library/app")
+		if err != nil {
+			return nil, err
+		}
+		repo, err := ns.Repository(ctx, named)
+		if err != nil {
+			return nil, err
+		}
+		return repo.Blobs(ctx)
+	})
+
+	Must(t, func() error {
+		w, err := blobs.Writer(ctx)
+		if err != nil {
+			return err
+		}
+		defer w.Close()
+
+		if _, err := io.Copy(w, bytes.NewReader(data)); err != nil {
+			return err
+		}
+
+		_, err = w.Commit(ctx, manifestv1.Descriptor{Digest: dgst, Size: int64(len(data))})
+		return err
+	})
+
+	Then(t, "按协商的分块大小发送，中断后从服务端已接收的偏移续传",
+		Expect(patches, Equal([]int{
+			int(minLength),
+			int(minLength - 10*units.KiB),
+			int(minLength),
+			int(minLength),
+		})),
+		Expect(contentRanges[1], Equal(strconv.Itoa(int(10*units.KiB))+"-"+strconv.Itoa(int(minLength)-1))),
+		ExpectMustValue(func() (int64, error) {
+			d, err := blobs.Info(ctx, dgst)
+			if err != nil {
+				return 0, err
+			}
+			return d.Size, nil
+		}, Equal(int64(len(data)))),
+	)
+}
+
+func TestChunkedUploadPartialFailure(t *testing.T) {
+	ctx := t.Context()
+
+	data := make([]byte, 20*units.KiB)
+	_, _ = rand.Read(data)
+	dgst := digest.FromBytes(data)
+
+	registry := contenttestutil.NewRegistry(t)
+
+	mu := sync.Mutex{}
+	patches := 0
+
+	// 首个 PATCH 仅转发部分内容后中断连接，续传的 PATCH 同样中断
+	s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPatch {
+			chunk, _ := io.ReadAll(req.Body)
+
+			mu.Lock()
+			patches++
+			n := patches
+			mu.Unlock()
+
+			switch n {
+			case 1:
+				partial := chunk[:10*units.KiB]
+
+				r := req.Clone(req.Context())
+				r.Body = io.NopCloser(bytes.NewReader(partial))
+				r.ContentLength = int64(len(partial))
+				registry.ServeHTTP(httptest.NewRecorder(), r)
+
+				panic(http.ErrAbortHandler)
+			case 2:
+				panic(http.ErrAbortHandler)
+			}
+
+			req.Body = io.NopCloser(bytes.NewReader(chunk))
+		}
+
+		registry.ServeHTTP(rw, req)
+	}))
+	t.Cleanup(s.Close)
+
+	blobs := MustValue(t, func() (content.BlobStore, error) {
+		ns, err := contentremote.New(ctx, contentremote.Registry{Endpoint: s.URL},
+			contentremote.WithUploadChunkSize(int64(16*units.KiB)),
+			contentremote.WithRetry(contentremote.RetryPolicy{
+				MaxRetries: 1,
+				Backoff:    time.Millisecond,
+			}),
+		)
+		if err != nil {
+			return nil, err
+		}
+		named, err := reference.WithName("library/app")
+		if err != nil {
+			return nil, err
+		}
+		repo, err := ns.Repository(ctx, named)
+		if err != nil {
+			return nil, err
+		}
+		return repo.Blobs(ctx)
+	})
+
+	w := MustValue(t, func() (content.BlobWriter, error) {
+		return blobs.Writer(ctx)
+	})
+	defer w.Close()
+
+	n, err := w.Write(data)
+
+	Then(t, "发送失败时返回服务端已接收的字节数",
+		Expect(err == nil, Equal(false)),
+		Expect(n, Equal(int(10*units.KiB))),
+	)
+
+	Must(t, func() error {
+		if _, err := w.Write(data[n:]); err != nil {
+			return err
+		}
+
+		_, err := w.Commit(ctx, manifestv1.Descriptor{Digest: dgst, Size: int64(len(data))})
+		return err
+	})
+
+	Then(t, "调用方从返回的偏移继续写入后内容完整",
+		ExpectMustValue(func() (int64, error) {
+			d, err := blobs.Info(ctx, dgst)
+			if err != nil {
+				return 0, err
+			}
+			return d.Size, nil
+		}, Equal(int64(len(data)))),
+	)
+}
+
+func TestOpenRange(t *testing.T) {
+	ctx := t.Context()
+
+	data := make([]byte, 64*units.KiB)
+	_, _ = rand.Read(data)
+	dgst := digest.FromBytes(data)
+
+	newBlobs := func(t *testing.T, handle http.HandlerFunc) content.BlobStore {
+		s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			if req.URL.Path == "/v2/" {
+				rw.WriteHeader(http.StatusOK)
+				return
+			}
+			handle(rw, req)
+		}))
+		t.Cleanup(s.Close)
+
+		return MustValue(t, func() (content.BlobStore, error) {
+			ns, err := contentremote.New(ctx, contentremote.Registry{Endpoint: s.URL})
+			if err != nil {
+				return nil, err
+			}
+			named, err := reference.WithName("library/app")
+			if err != nil {
+				return nil, err
+			}
+			repo, err := ns.Repository(ctx, named)
+			if err != nil {
+				return nil, err
+			}
+			return repo.Blobs(ctx)
+		})
+	}
+
+	readRange := func(blobs content.BlobStore, offset int64, length int64) ([]byte, error) {
+		r, err := blobs.(content.RangeProvider).OpenRange(ctx, dgst, offset, length)
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return io.ReadAll(r)
+	}
+
+	t.Run("按 Range 返回部分内容", func(t *testing.T) {
+		blobs := newBlobs(t, func(rw http.ResponseWriter, req *http.Request) {
+			http.ServeContent(rw, req, "", time.Time{}, bytes.NewReader(data))
+		})
+
+		Then(t, "内容与请求的范围一致",
+			ExpectMustValue(func() ([]byte, error) {
+				return readRange(blobs, 100, 200)
+			}, Equal(data[100:300])),
+		)
+	})
+
+	t.Run("上游忽略 Range 返回完整内容", func(t *testing.T) {
+		blobs := newBlobs(t, func(rw http.ResponseWriter, req *http.Request) {
+			rw.Header().Set("Content-Length", strconv.Itoa(len(data)))
+			rw.WriteHeader(http.StatusOK)
+			_, _ = rw.Write(data)
+		})
+
+		Then(t, "自起始处读取时截取所需长度",
+			ExpectMustValue(func() ([]byte, error) {
+				return readRange(blobs, 0, 200)
+			}, Equal(data[:200])),
+		)
+
+		Then(t, "其他偏移报错",
+			ExpectDo(
+				func() error {
+					_, err := readRange(blobs, 100, 200)
+					return err
+				},
+				ErrorMatch(regexp.MustCompile("not honored")),
+			),
+		)
+	})
+
+	t.Run("Content-Range 与请求不一致", func(t *testing.T) {
+		blobs := newBlobs(t, func(rw http.ResponseWriter, req *http.Request) {
+			rw.Header().Set("Content-Range", "bytes 0-199/"+strconv.Itoa(len(data)))
+			rw.Header().Set("Content-Length", "200")
+			rw.WriteHeader(http.StatusPartialContent)
+			_, _ = rw.Write(data[:200])
+		})
+
+		Then(t, "报错",
+			ExpectDo(
+				func() error {
+					_, err := readRange(blobs, 100, 200)
+					return err
+				},
+				ErrorMatch(regexp.MustCompile("not match")),
+			),
+		)
+	})
+}
@@ -0,0 +1,136 @@
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/octohelm/x/logr"
+)
+
+// ErrUpstreamUnavailable 远程注册表处于熔断期
+var ErrUpstreamUnavailable = errors.New("upstream unavailable")
+
+// WithCircuitBreaker 远程注册表连续失败 threshold 次后熔断 cooldown，期间请求直接返回 ErrUpstreamUnavailable
+//
+// 网络错误、5xx 与 429 视为失败；熔断结束后仅放行一个探测请求，探测成功后恢复，失败时重新熔断
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(n *namespace) {
+		n.breakerThreshold = threshold
+		n.breakerCooldown = cooldown
+	}
+}
+
+type circuitBreaker struct {
+	server    string
+	threshold int
+	cooldown  time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	// 熔断结束后的探测请求进行中
+	probing bool
+}
+
+// allow 返回是否放行，熔断结束后首个放行的请求为探测请求，探测结束前拒绝其他请求
+func (b *circuitBreaker) allow(now time.Time) (ok bool, probe bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return true, false
+	}
+
+	if now.Before(b.openUntil) || b.probing {
+		return false, false
+	}
+
+	b.probing = true
+	return true, true
+}
+
+// record 返回本次是否触发熔断
+func (b *circuitBreaker) record(failed bool, probe bool, now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if probe {
+		b.probing = false
+	}
+
+	if !failed {
+		b.failures = 0
+		return false
+	}
+
+	b.failures++
+	if b.failures < b.threshold {
+		return false
+	}
+
+	tripped := probe || b.failures == b.threshold
+	b.openUntil = now.Add(b.cooldown)
+	return tripped
+}
+
+// cancel 探测请求被调用方取消，不影响熔断状态，由之后的请求重新探测
+func (b *circuitBreaker) cancel(probe bool) {
+	if !probe {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+func newCircuitBreakerRoundTripper(b *circuitBreaker) func(roundTripper http.RoundTripper) http.RoundTripper {
+	return func(roundTripper http.RoundTripper) http.RoundTripper {
+		return &circuitBreakerRoundTripper{
+			breaker:          b,
+			nextRoundTripper: roundTripper,
+		}
+	}
+}
+
+type circuitBreakerRoundTripper struct {
+	breaker          *circuitBreaker
+	nextRoundTripper http.RoundTripper
+}
+
+func (rt *circuitBreakerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	ok, probe := rt.breaker.allow(time.Now())
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUpstreamUnavailable, rt.breaker.server)
+	}
+
+	resp, err := rt.nextRoundTripper.RoundTrip(req)
+
+	failed := false
+	if err != nil {
+		// 调用方取消不计为远程失败
+		if req.Context().Err() != nil {
+			rt.breaker.cancel(probe)
+			return resp, err
+		}
+		failed = true
+	} else if resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests {
+		failed = true
+	}
+
+	if rt.breaker.record(failed, probe, time.Now()) {
+		logr.FromContext(req.Context()).
+			WithValues(
+				slog.String("server", rt.breaker.server),
+				slog.String("cooldown", rt.breaker.cooldown.String()),
+			).
+			Warn(fmt.Errorf("%w: marked unhealthy after %d consecutive failures", ErrUpstreamUnavailable, rt.breaker.threshold))
+	}
+
+	return resp, err
+}
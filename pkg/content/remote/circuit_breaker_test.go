@@ -0,0 +1,103 @@
+package remote
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/distribution/reference"
+
+	. "github.com/octohelm/x/testing/v2"
+
+	"github.com/octohelm/crkit/pkg/content"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	requested := atomic.Int32{}
+
+	s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requested.Add(1)
+		rw.WriteHeader(http.StatusBadGateway)
+	}))
+	t.Cleanup(s.Close)
+
+	tags := MustValue(t, func() (content.TagService, error) {
+		ns, err := New(t.Context(), Registry{Endpoint: s.URL}, WithCircuitBreaker(2, time.Minute))
+		if err != nil {
+			return nil, err
+		}
+		named, err := reference.WithName("library/app")
+		if err != nil {
+			return nil, err
+		}
+		repo, err := ns.Repository(t.Context(), named)
+		if err != nil {
+			return nil, err
+		}
+		return repo.Tags(t.Context())
+	})
+
+	for range 2 {
+		_, _ = tags.Get(t.Context(), "latest")
+	}
+
+	Then(t, "连续失败后熔断，不再访问远程",
+		ExpectDo(
+			func() error {
+				_, err := tags.Get(t.Context(), "latest")
+				return err
+			},
+			ErrorMatch(regexp.MustCompile(ErrUpstreamUnavailable.Error())),
+		),
+		Expect(requested.Load(), Equal(int32(2))),
+	)
+
+	t.Run("熔断结束后仅放行一个探测请求", func(t *testing.T) {
+		b := &circuitBreaker{threshold: 1, cooldown: time.Second}
+		now := time.Now()
+
+		allowed := func(at time.Time) bool {
+			ok, _ := b.allow(at)
+			return ok
+		}
+
+		Then(t, "熔断期内拒绝",
+			Expect(b.record(true, false, now), Equal(true)),
+			Expect(allowed(now.Add(500*time.Millisecond)), Equal(false)),
+		)
+
+		ok, probe := b.allow(now.Add(time.Second))
+
+		Then(t, "结束后放行探测请求，探测结束前拒绝其他请求",
+			Expect(ok, Equal(true)),
+			Expect(probe, Equal(true)),
+			Expect(allowed(now.Add(time.Second)), Equal(false)),
+		)
+
+		Then(t, "探测失败时重新熔断",
+			Expect(b.record(true, probe, now.Add(time.Second)), Equal(true)),
+			Expect(allowed(now.Add(1500*time.Millisecond)), Equal(false)),
+		)
+
+		ok, probe = b.allow(now.Add(2 * time.Second))
+		b.cancel(probe)
+
+		Then(t, "探测请求被取消后由之后的请求重新探测",
+			Expect(ok, Equal(true)),
+			ExpectMustValue(func() (bool, error) {
+				_, probe := b.allow(now.Add(2 * time.Second))
+				return probe, nil
+			}, Equal(true)),
+		)
+
+		Then(t, "探测成功后恢复",
+			Expect(b.record(false, true, now.Add(2*time.Second)), Equal(false)),
+			Expect(b.failures, Equal(0)),
+			Expect(allowed(now.Add(2*time.Second)), Equal(true)),
+			Expect(allowed(now.Add(2*time.Second)), Equal(true)),
+		)
+	})
+}
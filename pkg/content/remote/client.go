@@ -16,6 +16,7 @@ import (
 	"github.com/octohelm/courier/pkg/courierhttp/client"
 	"github.com/octohelm/x/logr"
 
+	"github.com/octohelm/crkit/pkg/content/metrics"
 	"github.com/octohelm/crkit/pkg/content/remote/authn"
 )
 
@@ -24,6 +25,14 @@ type Client struct {
 
 	RoundTripperCreateFunc client.RoundTripperCreateFunc
 
+	breaker         *circuitBreaker
+	retry           *RetryPolicy
+	uploadChunkSize int64
+	credentials     CredentialProvider
+	identityToken   string
+	// 认证由 RoundTripper 处理，如镜像端点
+	skipAuthn bool
+
 	c courier.Client
 }
 
@@ -40,26 +49,37 @@ func (c *Client) Init(ctx context.Context) error {
 
 		u.Path = "/v2/"
 
-		if c.Username != "" {
+		transports := make([]client.HttpTransport, 0, 4)
+
+		if c.breaker != nil {
+			transports = append(transports, newCircuitBreakerRoundTripper(c.breaker))
+		}
+
+		// 重试在熔断内，熔断仅计入重试后的结果
+		if c.retry.enabled() {
+			transports = append(transports, newRetryRoundTripper(c.retry))
+		}
+
+		transports = append(transports, newLogRoundTripper())
+
+		if !c.skipAuthn {
+			// 未声明凭证时匿名换取 token
 			a := &authn.Authn{}
 			a.CheckEndpoint = u.String()
 			a.ClientID = c.Username
 			a.ClientSecret = c.Password
+			a.RefreshToken = c.identityToken
 
-			c.c = &client.Client{
-				Endpoint: u.String(),
-				HttpTransports: []client.HttpTransport{
-					newLogRoundTripper(),
-					a.AsHttpTransport(),
-				},
-			}
-		} else {
-			c.c = &client.Client{
-				Endpoint: u.String(),
-				HttpTransports: []client.HttpTransport{
-					newLogRoundTripper(),
-				},
+			if c.Username == "" && c.identityToken == "" && c.credentials != nil {
+				a.Credential = credentialFunc(c.credentials, u.Host)
 			}
+
+			transports = append(transports, a.AsHttpTransport())
+		}
+
+		c.c = &client.Client{
+			Endpoint:       u.String(),
+			HttpTransports: transports,
 		}
 	}
 
@@ -118,6 +138,12 @@ func (rt *logRoundTripper) RoundTrip(req *http.Request) (*http.Response, error)
 
 	cost := time.Since(startedAt)
 
+	statusCode := 0
+	if resp != nil {
+		statusCode = resp.StatusCode
+	}
+	metrics.RecordRemoteRequest(ctx, req.URL.Host, req.Method, statusCode, cost)
+
 	l := logr.FromContext(ctx).WithValues(
 		slog.Any("http.url", omitAuthorization(req.URL)),
 		slog.Any("http.method", req.Method),
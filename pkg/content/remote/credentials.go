@@ -0,0 +1,242 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/octohelm/crkit/pkg/content/remote/authn"
+)
+
+// CredentialProvider 按注册表主机解析认证信息，仅用于配置中未声明认证信息的上游与镜像
+type CredentialProvider interface {
+	// Credential host 为注册表的主机（含端口），无认证信息时返回 nil
+	Credential(ctx context.Context, host string) (*RegistryAuth, error)
+}
+
+// WithCredentials 未声明认证信息的上游与镜像通过 p 获取认证信息
+func WithCredentials(p CredentialProvider) Option {
+	return func(n *namespace) {
+		n.credentials = p
+	}
+}
+
+func credentialFunc(p CredentialProvider, host string) func(ctx context.Context) (*authn.Credential, error) {
+	return func(ctx context.Context) (*authn.Credential, error) {
+		auth, err := p.Credential(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		if auth == nil {
+			return nil, nil
+		}
+		return auth.credential(), nil
+	}
+}
+
+func (a *RegistryAuth) credential() *authn.Credential {
+	return &authn.Credential{
+		ClientID:     a.Username,
+		ClientSecret: a.Password,
+		RefreshToken: a.IdentityToken,
+	}
+}
+
+// StaticCredentials 按主机声明的认证信息
+type StaticCredentials map[string]RegistryAuth
+
+func (c StaticCredentials) Credential(ctx context.Context, host string) (*RegistryAuth, error) {
+	key := normalizeCredentialHost(host)
+
+	for h, auth := range c {
+		if normalizeCredentialHost(h) == key {
+			return &auth, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// CredentialProviders 按顺序使用首个返回认证信息的来源
+type CredentialProviders []CredentialProvider
+
+func (providers CredentialProviders) Credential(ctx context.Context, host string) (*RegistryAuth, error) {
+	for _, p := range providers {
+		auth, err := p.Credential(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		if auth != nil {
+			return auth, nil
+		}
+	}
+	return nil, nil
+}
+
+// DockerConfig Docker 客户端配置文件 config.json 中的认证部分
+//
+// 查找顺序：credHelpers 中声明的主机凭证助手、auths、credsStore
+type DockerConfig struct {
+	Auths       map[string]DockerConfigAuth `json:"auths,omitzero"`
+	CredsStore  string                      `json:"credsStore,omitzero"`
+	CredHelpers map[string]string           `json:"credHelpers,omitzero"`
+}
+
+type DockerConfigAuth struct {
+	// base64(username:password)
+	Auth          string `json:"auth,omitzero"`
+	Username      string `json:"username,omitzero"`
+	Password      string `json:"password,omitzero"`
+	IdentityToken string `json:"identitytoken,omitzero"`
+}
+
+// DefaultDockerConfigFile $DOCKER_CONFIG/config.json，未声明时为 ~/.docker/config.json
+func DefaultDockerConfigFile() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return filepath.Join(dir, "config.json")
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(home, ".docker", "config.json")
+}
+
+// LoadDockerConfig 文件不存在时返回空配置
+func LoadDockerConfig(filename string) (*DockerConfig, error) {
+	c := &DockerConfig{}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return c, nil
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (c *DockerConfig) Credential(ctx context.Context, host string) (*RegistryAuth, error) {
+	key := normalizeCredentialHost(host)
+
+	for h, helper := range c.CredHelpers {
+		if normalizeCredentialHost(h) == key {
+			return CredentialHelper(helper).Credential(ctx, host)
+		}
+	}
+
+	for h, a := range c.Auths {
+		if normalizeCredentialHost(h) != key {
+			continue
+		}
+
+		if a.IdentityToken != "" {
+			return &RegistryAuth{Username: a.Username, IdentityToken: a.IdentityToken}, nil
+		}
+
+		if a.Auth != "" {
+			raw, err := base64.StdEncoding.DecodeString(a.Auth)
+			if err != nil {
+				return nil, fmt.Errorf("invalid auth of %s: %w", h, err)
+			}
+			username, password, ok := strings.Cut(string(raw), ":")
+			if !ok {
+				return nil, fmt.Errorf("invalid auth of %s", h)
+			}
+			return &RegistryAuth{Username: username, Password: password}, nil
+		}
+
+		if a.Username != "" {
+			return &RegistryAuth{Username: a.Username, Password: a.Password}, nil
+		}
+	}
+
+	if c.CredsStore != "" {
+		return CredentialHelper(c.CredsStore).Credential(ctx, host)
+	}
+
+	return nil, nil
+}
+
+// CredentialHelper Docker 凭证助手 docker-credential-{name}，通过 get 协议获取认证信息
+type CredentialHelper string
+
+func (h CredentialHelper) Credential(ctx context.Context, host string) (*RegistryAuth, error) {
+	stdout := bytes.NewBuffer(nil)
+	stderr := bytes.NewBuffer(nil)
+
+	cmd := exec.CommandContext(ctx, "docker-credential-"+string(h), "get")
+	cmd.Stdin = strings.NewReader(credentialHelperServerURL(host))
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		// 助手以非零状态与该输出表示无凭证
+		if strings.Contains(stdout.String(), "credentials not found") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("docker-credential-%s get %s failed: %w: %s", h, host, err, strings.TrimSpace(stdout.String()+stderr.String()))
+	}
+
+	resp := &struct {
+		ServerURL string `json:"ServerURL"`
+		Username  string `json:"Username"`
+		Secret    string `json:"Secret"`
+	}{}
+
+	if err := json.Unmarshal(stdout.Bytes(), resp); err != nil {
+		return nil, fmt.Errorf("invalid output of docker-credential-%s: %w", h, err)
+	}
+
+	// 用户名为 <token> 时 Secret 为 identity token
+	if resp.Username == "<token>" {
+		return &RegistryAuth{IdentityToken: resp.Secret}, nil
+	}
+
+	if resp.Username == "" && resp.Secret == "" {
+		return nil, nil
+	}
+
+	return &RegistryAuth{Username: resp.Username, Password: resp.Secret}, nil
+}
+
+// normalizeCredentialHost 去除协议与路径，Docker Hub 的各主机统一为 docker.io
+func normalizeCredentialHost(host string) string {
+	if strings.Contains(host, "://") {
+		if u, err := url.Parse(host); err == nil {
+			host = u.Host
+		}
+	}
+
+	host, _, _ = strings.Cut(host, "/")
+
+	switch host {
+	case "index.docker.io", "registry-1.docker.io", "registry.hub.docker.com":
+		return "docker.io"
+	}
+
+	return host
+}
+
+// credentialHelperServerURL Docker Hub 的凭证以 https://index.docker.io/v1/ 保存
+func credentialHelperServerURL(host string) string {
+	if normalizeCredentialHost(host) == "docker.io" {
+		return "https://index.docker.io/v1/"
+	}
+	return host
+}
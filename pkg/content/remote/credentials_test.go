@@ -0,0 +1,85 @@
+package remote_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/octohelm/x/testing/v2"
+
+	contentremote "github.com/octohelm/crkit/pkg/content/remote"
+)
+
+func TestCredentials(t *testing.T) {
+	ctx := t.Context()
+
+	// 凭证助手以 Secret 回显查询的主机，便于校验 get 协议的输入
+	bin := t.TempDir()
+	Must(t, func() error {
+		return os.WriteFile(filepath.Join(bin, "docker-credential-fake"), []byte(`#!/bin/sh
+read host
+if [ "$host" = "unknown.example.com" ]; then
+  echo "credentials not found in native keychain"
+  exit 1
+fi
+printf '{"ServerURL":"%s","Username":"helper","Secret":"%s"}' "$host" "$host"
+`), 0o755)
+	})
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	Must(t, func() error {
+		return os.WriteFile(configFile, []byte(`{
+  "auths": {
+    "https://index.docker.io/v1/": { "auth": "dXNlcjpwYXNz" },
+    "registry.example.com:5000": { "username": "u", "password": "p" }
+  },
+  "credHelpers": { "ghcr.io": "fake" },
+  "credsStore": "fake"
+}`), 0o644)
+	})
+
+	c := MustValue(t, func() (*contentremote.DockerConfig, error) {
+		return contentremote.LoadDockerConfig(configFile)
+	})
+
+	t.Run("读取 auths", func(t *testing.T) {
+		Then(t, "Docker Hub 的各主机使用同一凭证",
+			ExpectMustValue(func() (*contentremote.RegistryAuth, error) {
+				return c.Credential(ctx, "registry-1.docker.io")
+			}, Equal(&contentremote.RegistryAuth{Username: "user", Password: "pass"})),
+			ExpectMustValue(func() (*contentremote.RegistryAuth, error) {
+				return c.Credential(ctx, "registry.example.com:5000")
+			}, Equal(&contentremote.RegistryAuth{Username: "u", Password: "p"})),
+		)
+	})
+
+	t.Run("使用凭证助手", func(t *testing.T) {
+		Then(t, "credHelpers 优先，其余主机使用 credsStore",
+			ExpectMustValue(func() (*contentremote.RegistryAuth, error) {
+				return c.Credential(ctx, "ghcr.io")
+			}, Equal(&contentremote.RegistryAuth{Username: "helper", Password: "ghcr.io"})),
+			ExpectMustValue(func() (*contentremote.RegistryAuth, error) {
+				return c.Credential(ctx, "unknown.example.com")
+			}, Equal[*contentremote.RegistryAuth](nil)),
+		)
+	})
+
+	t.Run("静态凭证", func(t *testing.T) {
+		p := contentremote.CredentialProviders{
+			contentremote.StaticCredentials{
+				"docker.io": {Username: "static", Password: "x"},
+			},
+			c,
+		}
+
+		Then(t, "按顺序使用首个返回凭证的来源",
+			ExpectMustValue(func() (*contentremote.RegistryAuth, error) {
+				return p.Credential(ctx, "index.docker.io")
+			}, Equal(&contentremote.RegistryAuth{Username: "static", Password: "x"})),
+			ExpectMustValue(func() (*contentremote.RegistryAuth, error) {
+				return p.Credential(ctx, "ghcr.io")
+			}, Equal(&contentremote.RegistryAuth{Username: "helper", Password: "ghcr.io"})),
+		)
+	})
+}
@@ -0,0 +1,243 @@
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// hostsFileEndpoint containerd hosts.toml 中的端点配置，不支持的字段（如 header、dial_timeout）被忽略
+type hostsFileEndpoint struct {
+	Capabilities []string `toml:"capabilities"`
+	// 证书文件路径，字符串或字符串数组
+	CA any `toml:"ca"`
+	// 客户端证书，同时包含证书与私钥的文件路径，或 [证书, 私钥] 路径对
+	Client       any  `toml:"client"`
+	SkipVerify   bool `toml:"skip_verify"`
+	OverridePath bool `toml:"override_path"`
+}
+
+type hostsFile struct {
+	hostsFileEndpoint
+
+	Server string                       `toml:"server"`
+	Host   map[string]hostsFileEndpoint `toml:"host"`
+}
+
+// LoadHostsDir 读取 containerd 格式的 hosts 目录 {dir}/{host}/hosts.toml
+//
+// server 作为上游，[host."..."] 按声明顺序作为镜像；ca、client 的相对路径相对于 hosts.toml 所在目录。
+// 不支持 _default 目录
+func LoadHostsDir(dir string) (RegistryHosts, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	hosts := RegistryHosts{}
+
+	for _, entry := range entries {
+		if !entry.IsDir() || entry.Name() == "_default" {
+			continue
+		}
+
+		filename := filepath.Join(dir, entry.Name(), "hosts.toml")
+
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+
+		rh, err := parseHostsFile(entry.Name(), filepath.Dir(filename), data)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s failed: %w", filename, err)
+		}
+
+		hosts[entry.Name()] = *rh
+	}
+
+	return hosts, nil
+}
+
+func parseHostsFile(host string, baseDir string, data []byte) (*RegistryHost, error) {
+	f := &hostsFile{}
+
+	md, err := toml.Decode(string(data), f)
+	if err != nil {
+		return nil, err
+	}
+
+	rh := &RegistryHost{
+		Server: f.Server,
+	}
+
+	if rh.Server == "" {
+		if host == "docker.io" {
+			rh.Server = "https://registry-1.docker.io"
+		} else {
+			rh.Server = "https://" + host
+		}
+	} else if !strings.HasPrefix(rh.Server, "http") {
+		rh.Server = "https://" + rh.Server
+	}
+
+	ca, client, err := f.tls(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	rh.CertificateAuthorityData = ca
+	rh.Client = client
+
+	// map 无序，按 [host."..."] 的声明顺序排列镜像
+	for _, key := range md.Keys() {
+		if len(key) != 2 || key[0] != "host" {
+			continue
+		}
+
+		e := f.Host[key[1]]
+
+		m := RegistryMirror{
+			Server:       key[1],
+			OverridePath: e.OverridePath,
+		}
+
+		if !strings.HasPrefix(m.Server, "http") {
+			m.Server = "https://" + m.Server
+		}
+
+		for _, c := range e.Capabilities {
+			// 镜像不处理推送
+			if c == "push" {
+				continue
+			}
+			m.Capabilities = append(m.Capabilities, c)
+		}
+
+		// 仅声明 push 时不作为镜像
+		if len(e.Capabilities) > 0 && len(m.Capabilities) == 0 {
+			continue
+		}
+
+		ca, client, err := e.tls(baseDir)
+		if err != nil {
+			return nil, fmt.Errorf("host %s: %w", key[1], err)
+		}
+		m.CertificateAuthorityData = ca
+		m.Client = client
+
+		rh.Mirrors = append(rh.Mirrors, m)
+	}
+
+	return rh, nil
+}
+
+func (e *hostsFileEndpoint) tls(baseDir string) ([]byte, *RegistryClient, error) {
+	var ca []byte
+
+	caFiles, err := stringList(e.CA)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid ca: %w", err)
+	}
+
+	for _, file := range caFiles {
+		data, err := os.ReadFile(absPath(baseDir, file))
+		if err != nil {
+			return nil, nil, err
+		}
+		ca = append(ca, data...)
+		ca = append(ca, '\n')
+	}
+
+	var client *RegistryClient
+
+	if e.Client != nil || e.SkipVerify {
+		client = &RegistryClient{
+			SkipVerify: e.SkipVerify,
+		}
+	}
+
+	if e.Client != nil {
+		pair, err := clientPair(e.Client)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid client: %w", err)
+		}
+
+		cert, err := os.ReadFile(absPath(baseDir, pair[0]))
+		if err != nil {
+			return nil, nil, err
+		}
+		client.ClientCertificateData = cert
+		client.ClientKeyData = cert
+
+		if pair[1] != "" {
+			key, err := os.ReadFile(absPath(baseDir, pair[1]))
+			if err != nil {
+				return nil, nil, err
+			}
+			client.ClientKeyData = key
+		}
+	}
+
+	return ca, client, nil
+}
+
+// clientPair 仅支持单个客户端证书
+func clientPair(v any) ([2]string, error) {
+	switch x := v.(type) {
+	case string:
+		return [2]string{x, ""}, nil
+	case []any:
+		if len(x) != 1 {
+			return [2]string{}, errors.New("only one client certificate is supported")
+		}
+
+		switch p := x[0].(type) {
+		case string:
+			return [2]string{p, ""}, nil
+		case []any:
+			files, err := stringList(p)
+			if err != nil {
+				return [2]string{}, err
+			}
+			if len(files) != 2 {
+				return [2]string{}, fmt.Errorf("invalid pair %v", p)
+			}
+			return [2]string{files[0], files[1]}, nil
+		}
+	}
+	return [2]string{}, fmt.Errorf("invalid type %T", v)
+}
+
+func stringList(v any) ([]string, error) {
+	switch x := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{x}, nil
+	case []any:
+		list := make([]string, 0, len(x))
+		for _, item := range x {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid type %T", item)
+			}
+			list = append(list, s)
+		}
+		return list, nil
+	}
+	return nil, fmt.Errorf("invalid type %T", v)
+}
+
+func absPath(baseDir string, p string) string {
+	if filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(baseDir, p)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"iter"
 	"maps"
 	"slices"
 	"strconv"
@@ -107,3 +108,25 @@ func (ms *manifestService) Get(ctx context.Context, dgst digest.Digest) (manifes
 
 	return p, nil
 }
+
+var _ content.ReferrersIterable = &manifestService{}
+
+func (ms *manifestService) Referrers(ctx context.Context, subject digest.Digest) iter.Seq2[manifestv1.Descriptor, error] {
+	return func(yield func(manifestv1.Descriptor, error) bool) {
+		req := &endpointsv2.GetReferrers{}
+		req.Name = registryv2.Name(ms.named.Name())
+		req.Digest = registryv2.Digest(subject)
+
+		idx, _, err := Do(ctx, ms.client, req)
+		if err != nil {
+			yield(manifestv1.Descriptor{}, err)
+			return
+		}
+
+		for _, d := range idx.Manifests {
+			if !yield(d, nil) {
+				return
+			}
+		}
+	}
+}
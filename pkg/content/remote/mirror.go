@@ -0,0 +1,254 @@
+package remote
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+
+	"github.com/octohelm/x/logr"
+
+	"github.com/octohelm/crkit/pkg/content/remote/authn"
+)
+
+const (
+	// 未声明熔断时，镜像端点连续失败该次数后暂停使用
+	defaultMirrorFailureThreshold = 3
+	defaultMirrorCooldown         = 30 * time.Second
+)
+
+type mirrorEndpoint struct {
+	server string
+	scheme string
+	host   string
+	// API 根路径，如 /v2
+	root string
+
+	pull    bool
+	resolve bool
+
+	transport    http.RoundTripper
+	roundTripper http.RoundTripper
+}
+
+type mirrorEndpointOptions struct {
+	server       string
+	auth         *RegistryAuth
+	ca           []byte
+	client       *RegistryClient
+	capabilities []string
+	overridePath bool
+	credentials  CredentialProvider
+	breaker      *circuitBreaker
+}
+
+func newMirrorEndpoint(base *http.Transport, o *mirrorEndpointOptions) (*mirrorEndpoint, error) {
+	u, err := url.Parse(o.server)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mirror server %q: %w", o.server, err)
+	}
+
+	e := &mirrorEndpoint{
+		server: o.server,
+		scheme: u.Scheme,
+		host:   u.Host,
+		root:   "/v2",
+	}
+
+	if u.Path != "" && u.Path != "/" {
+		e.root = path.Clean(u.Path)
+		if !o.overridePath && !strings.HasSuffix(e.root, "/v2") {
+			e.root = e.root + "/v2"
+		}
+	}
+
+	if len(o.capabilities) == 0 {
+		e.pull = true
+		e.resolve = true
+	}
+
+	for _, c := range o.capabilities {
+		switch c {
+		case CapabilityPull:
+			e.pull = true
+		case CapabilityResolve:
+			e.resolve = true
+		default:
+			return nil, fmt.Errorf("unsupported capability %q of mirror %s", c, o.server)
+		}
+	}
+
+	tlsConfig, err := newTLSConfig(base.TLSClientConfig, o.ca, o.client)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tls config of mirror %s: %w", o.server, err)
+	}
+
+	t := base.Clone()
+	t.TLSClientConfig = tlsConfig
+	e.transport = t
+
+	rt := http.RoundTripper(t)
+
+	a := &authn.Authn{}
+	a.CheckEndpoint = (&url.URL{Scheme: e.scheme, Host: e.host, Path: e.root + "/"}).String()
+
+	if o.auth != nil {
+		a.ClientID = o.auth.Username
+		a.ClientSecret = o.auth.Password
+		a.RefreshToken = o.auth.IdentityToken
+	} else if o.credentials != nil {
+		a.Credential = credentialFunc(o.credentials, e.host)
+	}
+
+	rt = a.AsHttpTransport()(rt)
+
+	if o.breaker != nil {
+		rt = newCircuitBreakerRoundTripper(o.breaker)(rt)
+	}
+
+	e.roundTripper = rt
+
+	return e, nil
+}
+
+func (e *mirrorEndpoint) supports(capability string) bool {
+	switch capability {
+	case CapabilityPull:
+		return e.pull
+	case CapabilityResolve:
+		return e.resolve
+	}
+	return false
+}
+
+// request 将发往上游 /v2 的请求改写到当前端点
+func (e *mirrorEndpoint) request(req *http.Request) *http.Request {
+	r := req.Clone(req.Context())
+
+	r.URL.Scheme = e.scheme
+	r.URL.Host = e.host
+	r.URL.Path = e.root + strings.TrimPrefix(req.URL.Path, "/v2")
+	r.URL.RawPath = ""
+	r.Host = e.host
+
+	return r
+}
+
+func newTLSConfig(base *tls.Config, ca []byte, c *RegistryClient) (*tls.Config, error) {
+	tlsConfig := &tls.Config{}
+	if base != nil {
+		tlsConfig = base.Clone()
+	}
+
+	if len(ca) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, errors.New("no valid certificate in certificateAuthorityData")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if c != nil {
+		tlsConfig.InsecureSkipVerify = c.SkipVerify
+
+		if len(c.ClientCertificateData) > 0 {
+			cert, err := tls.X509KeyPair(c.ClientCertificateData, c.ClientKeyData)
+			if err != nil {
+				return nil, err
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+	}
+
+	return tlsConfig, nil
+}
+
+// mirrorRoundTripper 拉取请求按顺序尝试支持对应操作的镜像端点，最后访问上游
+//
+// 连接失败或 5xx 时切换至下一端点；端点连续失败后在熔断期内直接跳过
+type mirrorRoundTripper struct {
+	// 上游的主机，仅改写发往该主机的请求，其他请求（如 Blob 重定向）直接发送
+	host      string
+	upstream  *mirrorEndpoint
+	endpoints []*mirrorEndpoint
+}
+
+func (rt *mirrorRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != rt.host {
+		return rt.upstream.transport.RoundTrip(req)
+	}
+
+	candidates := make([]*mirrorEndpoint, 0, len(rt.endpoints)+1)
+	if capability := requestCapability(req); capability != "" {
+		for _, e := range rt.endpoints {
+			if e.supports(capability) {
+				candidates = append(candidates, e)
+			}
+		}
+	}
+	candidates = append(candidates, rt.upstream)
+
+	for i, e := range candidates {
+		resp, err := e.roundTripper.RoundTrip(e.request(req))
+
+		if i == len(candidates)-1 || !shouldFailover(req, resp, err) {
+			return resp, err
+		}
+
+		if err == nil {
+			_ = resp.Body.Close()
+			err = fmt.Errorf("unexpected status %d", resp.StatusCode)
+		}
+
+		logr.FromContext(req.Context()).
+			WithValues(
+				slog.String("mirror", e.server),
+				slog.String("next", candidates[i+1].server),
+			).
+			Warn(fmt.Errorf("mirror request failed, fail over: %w", err))
+	}
+
+	// 上游始终为最后一个候选端点，不会执行到此
+	return nil, errors.New("no available endpoint")
+}
+
+func shouldFailover(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		// 调用方取消时不再切换
+		return req.Context().Err() == nil
+	}
+	return resp.StatusCode >= http.StatusInternalServerError
+}
+
+// requestCapability 返回请求所需的镜像能力，为空时仅由上游处理
+func requestCapability(req *http.Request) string {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return ""
+	}
+
+	p := strings.TrimPrefix(req.URL.Path, "/v2/")
+
+	if i := strings.LastIndex(p, "/manifests/"); i > 0 {
+		if _, err := digest.Parse(p[i+len("/manifests/"):]); err == nil {
+			return CapabilityPull
+		}
+		return CapabilityResolve
+	}
+
+	if i := strings.LastIndex(p, "/blobs/"); i > 0 && !strings.HasPrefix(p[i+len("/blobs/"):], "uploads") {
+		return CapabilityPull
+	}
+
+	return ""
+}
@@ -0,0 +1,173 @@
+package remote_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/distribution/reference"
+
+	"github.com/octohelm/unifs/pkg/units"
+	. "github.com/octohelm/x/testing/v2"
+
+	"github.com/octohelm/crkit/pkg/content"
+	contentremote "github.com/octohelm/crkit/pkg/content/remote"
+	contenttestutil "github.com/octohelm/crkit/pkg/content/testutil"
+	"github.com/octohelm/crkit/pkg/oci"
+	"github.com/octohelm/crkit/pkg/oci/random"
+	"github.com/octohelm/crkit/pkg/oci/remote"
+)
+
+func TestMirrors(t *testing.T) {
+	ctx := t.Context()
+
+	registry := contenttestutil.NewRegistry(t)
+
+	upstreamBlobs := atomic.Int32{}
+	upstream := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if strings.Contains(req.URL.Path, "/blobs/") && req.Method == http.MethodGet {
+			upstreamBlobs.Add(1)
+		}
+		registry.ServeHTTP(rw, req)
+	}))
+	t.Cleanup(upstream.Close)
+
+	broken := atomic.Int32{}
+	brokenMirror := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		broken.Add(1)
+		rw.WriteHeader(http.StatusBadGateway)
+	}))
+	t.Cleanup(brokenMirror.Close)
+
+	// 镜像与上游共享内容，路径带前缀
+	mirrorBlobs := atomic.Int32{}
+	mirror := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if strings.Contains(req.URL.Path, "/blobs/") && req.Method == http.MethodGet {
+			mirrorBlobs.Add(1)
+		}
+		req.URL.Path = strings.TrimPrefix(req.URL.Path, "/proxy")
+		registry.ServeHTTP(rw, req)
+	}))
+	t.Cleanup(mirror.Close)
+
+	Must(t, func() error {
+		ns, err := contentremote.New(ctx, contentremote.Registry{Endpoint: upstream.URL})
+		if err != nil {
+			return err
+		}
+		named, err := reference.WithName("library/app")
+		if err != nil {
+			return err
+		}
+		repo, err := ns.Repository(ctx, named)
+		if err != nil {
+			return err
+		}
+		img, err := random.Image(int64(10*units.KiB), 3)
+		if err != nil {
+			return err
+		}
+		return remote.Push(ctx, img, repo, "latest")
+	})
+
+	repo := MustValue(t, func() (content.Repository, error) {
+		ns, err := contentremote.New(ctx, contentremote.RegistryHosts{
+			"example.com": {
+				Server: upstream.URL,
+				Mirrors: []contentremote.RegistryMirror{
+					{Server: brokenMirror.URL},
+					{
+						Server:       mirror.URL + "/proxy/v2",
+						Capabilities: []string{contentremote.CapabilityPull},
+					},
+				},
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		named, err := reference.WithName("example.com/library/app")
+		if err != nil {
+			return nil, err
+		}
+		return ns.Repository(ctx, named)
+	})
+
+	Must(t, func() error {
+		m, err := remote.Manifest(ctx, repo, "latest")
+		if err != nil {
+			return err
+		}
+
+		for layer, err := range m.(oci.Image).Layers(ctx) {
+			if err != nil {
+				return err
+			}
+			r, err := layer.Open(ctx)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(io.Discard, r)
+			_ = r.Close()
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	Then(t, "拉取请求依次切换镜像，标签解析由上游处理",
+		Expect(mirrorBlobs.Load() > 0, Equal(true)),
+		Expect(upstreamBlobs.Load(), Equal(int32(0))),
+	)
+
+	Then(t, "镜像连续失败后不再访问",
+		Expect(broken.Load(), Equal(int32(3))),
+	)
+}
+
+func TestLoadHostsDir(t *testing.T) {
+	dir := t.TempDir()
+
+	Must(t, func() error {
+		if err := os.MkdirAll(filepath.Join(dir, "docker.io"), 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dir, "docker.io", "hosts.toml"), []byte(`
+server = "https://registry-1.docker.io"
+
+[host."https://z-mirror.example.com"]
+  capabilities = ["pull", "resolve"]
+  skip_verify = true
+
+[host."a-mirror.example.com/v2/proxy"]
+  capabilities = ["pull"]
+  override_path = true
+
+[host."https://push.example.com"]
+  capabilities = ["push"]
+`), 0o644)
+	})
+
+	hosts := MustValue(t, func() (contentremote.RegistryHosts, error) {
+		return contentremote.LoadHostsDir(dir)
+	})
+
+	rh := hosts["docker.io"]
+
+	Then(t, "按声明顺序作为镜像，忽略仅支持推送的端点",
+		Expect(rh.Server, Equal("https://registry-1.docker.io")),
+		Expect(len(rh.Mirrors), Equal(2)),
+		Expect(rh.Mirrors[0].Server, Equal("https://z-mirror.example.com")),
+		Expect(rh.Mirrors[0].Client.SkipVerify, Equal(true)),
+		Expect(rh.Mirrors[1].Server, Equal("https://a-mirror.example.com/v2/proxy")),
+		Expect(rh.Mirrors[1].Capabilities, Equal([]string{"pull"})),
+		Expect(rh.Mirrors[1].OverridePath, Equal(true)),
+	)
+}
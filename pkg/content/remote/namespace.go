@@ -25,6 +25,7 @@ type Option func(n *namespace)
 func New(ctx context.Context, registryResolver RegistryResolver, options ...Option) (content.Namespace, error) {
 	n := &namespace{
 		RegistryResolver: registryResolver,
+		retry:            DefaultRetryPolicy,
 	}
 
 	for _, opt := range options {
@@ -37,6 +38,12 @@ func New(ctx context.Context, registryResolver RegistryResolver, options ...Opti
 type namespace struct {
 	RegistryResolver
 
+	breakerThreshold int
+	breakerCooldown  time.Duration
+	retry            RetryPolicy
+	uploadChunkSize  int64
+	credentials      CredentialProvider
+
 	clients syncx.Map[string, func() (courier.Client, error)]
 }
 
@@ -82,10 +89,42 @@ func (n *namespace) clientAndInnerNamed(ctx context.Context, named reference.Nam
 		// FIXME support full registry host
 		c := &Client{}
 		c.Endpoint = rh.Server
+		c.retry = &n.retry
+		c.uploadChunkSize = n.uploadChunkSize
+
+		if len(rh.Mirrors) > 0 {
+			// 认证与熔断由各端点处理
+			rt, err := n.newMirrorRoundTripper(rh)
+			if err != nil {
+				return nil, err
+			}
+
+			c.skipAuthn = true
+			c.RoundTripperCreateFunc = func() http.RoundTripper {
+				return rt
+			}
+
+			if err := c.Init(ctx); err != nil {
+				return nil, err
+			}
+
+			return c, nil
+		}
+
+		if n.breakerThreshold > 0 {
+			c.breaker = &circuitBreaker{
+				server:    rh.Server,
+				threshold: n.breakerThreshold,
+				cooldown:  n.breakerCooldown,
+			}
+		}
 
 		if rh.Auth != nil {
 			c.Username = rh.Auth.Username
 			c.Password = rh.Auth.Password
+			c.identityToken = rh.Auth.IdentityToken
+		} else {
+			c.credentials = n.credentials
 		}
 
 		t2 := t.Clone()
@@ -125,6 +164,72 @@ func (n *namespace) clientAndInnerNamed(ctx context.Context, named reference.Nam
 	return c, innerNamed, nil
 }
 
+func (n *namespace) newMirrorRoundTripper(rh *RegistryHost) (*mirrorRoundTripper, error) {
+	u, err := url.Parse(rh.Server)
+	if err != nil {
+		return nil, err
+	}
+
+	threshold, cooldown := n.breakerThreshold, n.breakerCooldown
+	if threshold <= 0 {
+		threshold = defaultMirrorFailureThreshold
+	}
+	if cooldown <= 0 {
+		cooldown = defaultMirrorCooldown
+	}
+
+	rt := &mirrorRoundTripper{
+		host:      u.Host,
+		endpoints: make([]*mirrorEndpoint, 0, len(rh.Mirrors)),
+	}
+
+	for _, m := range rh.Mirrors {
+		e, err := newMirrorEndpoint(t, &mirrorEndpointOptions{
+			server:       m.Server,
+			auth:         m.Auth,
+			ca:           m.CertificateAuthorityData,
+			client:       m.Client,
+			capabilities: m.Capabilities,
+			overridePath: m.OverridePath,
+			credentials:  n.credentials,
+			breaker: &circuitBreaker{
+				server:    m.Server,
+				threshold: threshold,
+				cooldown:  cooldown,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		rt.endpoints = append(rt.endpoints, e)
+	}
+
+	o := &mirrorEndpointOptions{
+		server:      rh.Server,
+		auth:        rh.Auth,
+		ca:          rh.CertificateAuthorityData,
+		client:      rh.Client,
+		credentials: n.credentials,
+	}
+
+	// 上游仅在声明熔断时熔断
+	if n.breakerThreshold > 0 {
+		o.breaker = &circuitBreaker{
+			server:    rh.Server,
+			threshold: n.breakerThreshold,
+			cooldown:  n.breakerCooldown,
+		}
+	}
+
+	upstream, err := newMirrorEndpoint(t, o)
+	if err != nil {
+		return nil, err
+	}
+	rt.upstream = upstream
+
+	return rt, nil
+}
+
 type crRoundTripper struct {
 	RegistryHosts RegistryHosts
 
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/distribution/reference"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
 )
 
 type RegistryResolver interface {
@@ -21,6 +22,8 @@ type Registry struct {
 	Username string `flag:",omitzero"`
 	// Remote container registry password
 	Password string `flag:",omitzero,secret"`
+	// 代理缓存的标签在该时长内直接使用本地缓存，不访问远程
+	TagTTL strfmt.Duration `flag:",omitzero"`
 }
 
 func (r Registry) Resolve(ctx context.Context, named reference.Named) (reference.Named, *RegistryHost, error) {
@@ -31,6 +34,7 @@ func (r Registry) Resolve(ctx context.Context, named reference.Named) (reference
 
 	rh := &RegistryHost{
 		Server: r.Endpoint,
+		TagTTL: r.TagTTL,
 	}
 
 	if r.Username != "" {
@@ -106,11 +110,36 @@ type RegistryHost struct {
 	Auth                     *RegistryAuth   `json:"auth,omitzero"`
 	CertificateAuthorityData []byte          `json:"certificateAuthorityData,omitzero"`
 	Client                   *RegistryClient `json:"client,omitzero"`
+	// 代理缓存的标签在该时长内直接使用本地缓存，不访问远程
+	TagTTL strfmt.Duration `json:"tagTTL,omitzero"`
+	// 按顺序优先访问的镜像端点，连接失败或 5xx 时依次切换，最后访问 Server
+	Mirrors []RegistryMirror `json:"mirrors,omitzero"`
+}
+
+const (
+	// CapabilityPull 按摘要拉取清单与 Blob
+	CapabilityPull = "pull"
+	// CapabilityResolve 按标签解析清单
+	CapabilityResolve = "resolve"
+)
+
+// RegistryMirror 上游的镜像端点，仅处理拉取请求，推送、标签列表等其他请求始终访问 Server
+type RegistryMirror struct {
+	Server                   string          `json:"server"`
+	Auth                     *RegistryAuth   `json:"auth,omitzero"`
+	CertificateAuthorityData []byte          `json:"certificateAuthorityData,omitzero"`
+	Client                   *RegistryClient `json:"client,omitzero"`
+	// 端点支持的操作，pull 或 resolve，默认两者
+	Capabilities []string `json:"capabilities,omitzero"`
+	// 为 true 时 Server 的路径即 API 根路径，否则追加 /v2
+	OverridePath bool `json:"overridePath,omitzero"`
 }
 
 type RegistryAuth struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
+	// 声明时以 OAuth2 POST（grant_type=refresh_token）换取 token，如 Docker 的 identity token
+	IdentityToken string `json:"identityToken,omitzero"`
 }
 
 type RegistryClient struct {
@@ -0,0 +1,222 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/octohelm/x/logr"
+)
+
+// RetryPolicy 远程请求的重试策略
+//
+// 仅重试幂等请求（GET、HEAD），连接错误、5xx 与 429 时按指数退避重试，响应声明 Retry-After 时按其等待
+type RetryPolicy struct {
+	// 最大重试次数，为 0 时不重试
+	MaxRetries int
+	// 首次重试前的等待时间，之后逐次翻倍
+	Backoff time.Duration
+	// 单次等待的上限，Retry-After 超过该值时不再重试
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy 未通过 WithRetry 声明时使用
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	Backoff:    500 * time.Millisecond,
+	MaxBackoff: 30 * time.Second,
+}
+
+// WithRetry 覆盖默认的重试策略，MaxRetries 为 0 时关闭重试与 Blob 断点续传
+func WithRetry(p RetryPolicy) Option {
+	return func(n *namespace) {
+		n.retry = p
+	}
+}
+
+func (p *RetryPolicy) enabled() bool {
+	return p != nil && p.MaxRetries > 0
+}
+
+// backoff 第 attempt 次（从 0 开始）重试前的等待时间，附加随机抖动避免同时重试
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.Backoff
+	if d <= 0 {
+		d = DefaultRetryPolicy.Backoff
+	}
+
+	maxBackoff := p.maxBackoff()
+
+	for range attempt {
+		d *= 2
+		if d >= maxBackoff {
+			d = maxBackoff
+			break
+		}
+	}
+
+	if half := int64(d / 2); half > 0 {
+		d = time.Duration(half + rand.Int64N(half))
+	}
+
+	return d
+}
+
+func (p *RetryPolicy) maxBackoff() time.Duration {
+	if p.MaxBackoff > 0 {
+		return p.MaxBackoff
+	}
+	return DefaultRetryPolicy.MaxBackoff
+}
+
+// wait 等待重试，调用方取消时返回错误
+func (p *RetryPolicy) wait(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func newRetryRoundTripper(p *RetryPolicy) func(roundTripper http.RoundTripper) http.RoundTripper {
+	return func(roundTripper http.RoundTripper) http.RoundTripper {
+		return &retryRoundTripper{
+			policy:           p,
+			nextRoundTripper: roundTripper,
+		}
+	}
+}
+
+type retryRoundTripper struct {
+	policy           *RetryPolicy
+	nextRoundTripper http.RoundTripper
+}
+
+func (rt *retryRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isIdempotent(req) {
+		return rt.nextRoundTripper.RoundTrip(req)
+	}
+
+	ctx := req.Context()
+
+	for attempt := 0; ; attempt++ {
+		resp, err := rt.nextRoundTripper.RoundTrip(req)
+
+		if attempt >= rt.policy.MaxRetries || ctx.Err() != nil {
+			return resp, err
+		}
+
+		var d time.Duration
+
+		if err != nil {
+			if !isConnectionError(err) {
+				return resp, err
+			}
+			d = rt.policy.backoff(attempt)
+		} else {
+			if !isRetryableStatus(resp.StatusCode) {
+				return resp, err
+			}
+
+			d = rt.policy.backoff(attempt)
+
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				// 等待过久时交由调用方处理
+				if retryAfter > rt.policy.maxBackoff() {
+					return resp, err
+				}
+				d = max(d, retryAfter)
+			}
+
+			err = fmt.Errorf("unexpected status %d", resp.StatusCode)
+
+			// 读完响应体以复用连接
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			_ = resp.Body.Close()
+		}
+
+		logr.FromContext(ctx).
+			WithValues(
+				slog.String("http.url", omitAuthorization(req.URL)),
+				slog.String("http.method", req.Method),
+				slog.Int("attempt", attempt+1),
+				slog.String("backoff", d.String()),
+			).
+			Warn(fmt.Errorf("http request failed, retry: %w", err))
+
+		if err := rt.policy.wait(ctx, d); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+		return req.Body == nil || req.Body == http.NoBody
+	}
+	return false
+}
+
+func isRetryableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusNotImplemented, http.StatusHTTPVersionNotSupported:
+		return false
+	}
+	return statusCode >= http.StatusInternalServerError
+}
+
+// isConnectionError 连接建立失败、连接被重置或响应中断
+func isConnectionError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, ErrUpstreamUnavailable) {
+		return false
+	}
+
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNABORTED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	if _, ok := errors.AsType[net.Error](err); ok {
+		return true
+	}
+
+	return false
+}
+
+// parseRetryAfter 支持秒数与 HTTP 日期两种格式
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.ParseInt(v, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,178 @@
+package remote_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/distribution/reference"
+	"github.com/opencontainers/go-digest"
+
+	. "github.com/octohelm/x/testing/v2"
+
+	"github.com/octohelm/crkit/pkg/content"
+	contentremote "github.com/octohelm/crkit/pkg/content/remote"
+)
+
+func TestRetry(t *testing.T) {
+	data := make([]byte, 256*1024)
+	_, _ = rand.Read(data)
+	dgst := digest.FromBytes(data)
+
+	newBlobs := func(t *testing.T, handle func(rw http.ResponseWriter, req *http.Request)) content.BlobStore {
+		s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			if req.URL.Path == "/v2/" {
+				rw.WriteHeader(http.StatusOK)
+				return
+			}
+			handle(rw, req)
+		}))
+		t.Cleanup(s.Close)
+
+		return MustValue(t, func() (content.BlobStore, error) {
+			ns, err := contentremote.New(t.Context(), contentremote.Registry{Endpoint: s.URL}, contentremote.WithRetry(contentremote.RetryPolicy{
+				MaxRetries: 3,
+				Backoff:    time.Millisecond,
+			}))
+			if err != nil {
+				return nil, err
+			}
+			named, err := reference.WithName("library/app")
+			if err != nil {
+				return nil, err
+			}
+			repo, err := ns.Repository(t.Context(), named)
+			if err != nil {
+				return nil, err
+			}
+			return repo.Blobs(t.Context())
+		})
+	}
+
+	// 首次响应只返回一半内容后中断连接
+	serveInterrupted := func(requests *atomic.Int32, blob []byte) func(rw http.ResponseWriter, req *http.Request) {
+		return func(rw http.ResponseWriter, req *http.Request) {
+			if requests.Add(1) == 1 {
+				rw.Header().Set("Content-Length", strconv.Itoa(len(blob)))
+				rw.WriteHeader(http.StatusOK)
+				_, _ = rw.Write(blob[:len(blob)/2])
+				rw.(http.Flusher).Flush()
+				panic(http.ErrAbortHandler)
+			}
+			http.ServeContent(rw, req, "", time.Time{}, bytes.NewReader(blob))
+		}
+	}
+
+	read := func(blobs content.BlobStore) ([]byte, error) {
+		r, err := blobs.Open(t.Context(), dgst)
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return io.ReadAll(r)
+	}
+
+	t.Run("5xx 与 429 时按 Retry-After 重试", func(t *testing.T) {
+		requests := atomic.Int32{}
+
+		blobs := newBlobs(t, func(rw http.ResponseWriter, req *http.Request) {
+			switch requests.Add(1) {
+			case 1:
+				rw.WriteHeader(http.StatusServiceUnavailable)
+			case 2:
+				rw.Header().Set("Retry-After", "0")
+				rw.WriteHeader(http.StatusTooManyRequests)
+			default:
+				rw.Header().Set("Content-Length", strconv.Itoa(len(data)))
+				rw.Header().Set("Docker-Content-Digest", string(dgst))
+				rw.WriteHeader(http.StatusOK)
+			}
+		})
+
+		Then(t, "重试后成功",
+			ExpectMustValue(func() (int64, error) {
+				d, err := blobs.Info(t.Context(), dgst)
+				if err != nil {
+					return 0, err
+				}
+				return d.Size, nil
+			}, Equal(int64(len(data)))),
+			Expect(requests.Load(), Equal(int32(3))),
+		)
+	})
+
+	t.Run("下载中断时从已接收的偏移处续传", func(t *testing.T) {
+		requests := atomic.Int32{}
+
+		mu := sync.Mutex{}
+		ranges := make([]string, 0)
+
+		blobs := newBlobs(t, func(rw http.ResponseWriter, req *http.Request) {
+			if requests.Load() > 0 {
+				mu.Lock()
+				ranges = append(ranges, req.Header.Get("Range"))
+				mu.Unlock()
+			}
+			serveInterrupted(&requests, data)(rw, req)
+		})
+
+		Then(t, "内容完整",
+			ExpectMustValue(func() (bool, error) {
+				received, err := read(blobs)
+				if err != nil {
+					return false, err
+				}
+				return bytes.Equal(received, data), nil
+			}, Equal(true)),
+			Expect(ranges, Equal([]string{"bytes=" + strconv.Itoa(len(data)/2) + "-"})),
+		)
+	})
+
+	t.Run("续传时上游忽略 Range 返回完整内容时报错", func(t *testing.T) {
+		requests := atomic.Int32{}
+
+		blobs := newBlobs(t, func(rw http.ResponseWriter, req *http.Request) {
+			if requests.Load() > 0 {
+				req.Header.Del("Range")
+			}
+			serveInterrupted(&requests, data)(rw, req)
+		})
+
+		Then(t, "不写入错位的内容",
+			ExpectDo(
+				func() error {
+					_, err := read(blobs)
+					return err
+				},
+				ErrorMatch(regexp.MustCompile("not honored")),
+			),
+		)
+	})
+
+	t.Run("续传后内容与摘要不符时报错", func(t *testing.T) {
+		requests := atomic.Int32{}
+
+		tampered := bytes.Clone(data)
+		tampered[len(tampered)-1] ^= 0xff
+
+		blobs := newBlobs(t, serveInterrupted(&requests, tampered))
+
+		Then(t, "校验失败",
+			ExpectDo(
+				func() error {
+					_, err := read(blobs)
+					return err
+				},
+				ErrorMatch(regexp.MustCompile("invalid digest")),
+			),
+		)
+	})
+}
@@ -3,6 +3,7 @@ package remote
 import (
 	"context"
 	"errors"
+	"iter"
 	"maps"
 	"slices"
 	"strconv"
@@ -97,3 +98,37 @@ func (ts *tagService) All(ctx context.Context) ([]string, error) {
 
 	return list.Tags, nil
 }
+
+var _ content.TagAfterIterable = &tagService{}
+
+func (ts *tagService) TagsAfter(ctx context.Context, last string) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		for {
+			req := &endpointsv2.ListTag{}
+			req.Name = v2.Name(ts.named.Name())
+			req.N = tagsPageSize
+			req.Last = last
+
+			list, meta, err := Do(ctx, ts.client, req)
+			if err != nil {
+				yield("", err)
+				return
+			}
+
+			for _, tag := range list.Tags {
+				if !yield(tag, nil) {
+					return
+				}
+			}
+
+			// no more pages
+			if meta.Get("Link") == "" || len(list.Tags) == 0 {
+				return
+			}
+
+			last = list.Tags[len(list.Tags)-1]
+		}
+	}
+}
+
+const tagsPageSize = 1000
@@ -15,6 +15,16 @@ type TagService interface {
 	All(ctx context.Context) ([]string, error)
 }
 
+type TagAfterIterable interface {
+	TagsAfter(ctx context.Context, last string) iter.Seq2[string, error]
+}
+
+// ImmutableTagger 仅在标签不存在或已指向 desc 时写入标签，
+// 标签已指向其他清单时返回 ErrTagImmutable；检查与写入在存储层原子完成
+type ImmutableTagger interface {
+	TagIfAbsent(ctx context.Context, tag string, desc manifestv1.Descriptor) error
+}
+
 type TagRevisionIterable interface {
 	TagRevisions(ctx context.Context, tag string) iter.Seq2[LinkedDigest, error]
 }
@@ -4,6 +4,8 @@ import (
 	"context"
 	"io"
 	"io/fs"
+	"iter"
+	"time"
 
 	"github.com/octohelm/unifs/pkg/filesystem"
 )
@@ -30,3 +32,27 @@ type FileWriter interface {
 	Cancel(context.Context) error
 	Commit(context.Context) error
 }
+
+// DirLister 按名称顺序列出目录的直接子项，仅返回名称大于 after 的子项
+//
+// 调用方停止迭代时即停止列举，用于分页场景避免遍历整个目录
+type DirLister interface {
+	ListDir(ctx context.Context, path string, after string) iter.Seq2[fs.DirEntry, error]
+}
+
+// RangeReader 读取文件从 offset 开始的 length 字节，length < 0 时读取至文件末尾
+type RangeReader interface {
+	ReadRange(ctx context.Context, path string, offset int64, length int64) (io.ReadCloser, error)
+}
+
+// Presigner 生成文件限时有效的 GET 地址，客户端可直接从存储下载
+type Presigner interface {
+	PresignedURL(ctx context.Context, path string, expires time.Duration) (string, error)
+}
+
+// ExclusiveWriter 仅在文件不存在时写入内容，文件已存在时返回 fs.ErrExist
+//
+// 用于多副本共享存储时以 create-if-absent 保证写入的原子性
+type ExclusiveWriter interface {
+	PutContentIfAbsent(ctx context.Context, path string, data []byte) error
+}
@@ -36,6 +36,41 @@ func (d *fsDriver) Reader(ctx context.Context, path string) (io.ReadCloser, erro
 	return filesystem.Open(ctx, d.fs, path)
 }
 
+var _ driver.RangeReader = &fsDriver{}
+
+func (d *fsDriver) ReadRange(ctx context.Context, path string, offset int64, length int64) (io.ReadCloser, error) {
+	f, err := filesystem.Open(ctx, d.fs, path)
+	if err != nil {
+		return nil, err
+	}
+
+	if offset > 0 {
+		if seeker, ok := f.(io.Seeker); ok {
+			if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+				_ = f.Close()
+				return nil, err
+			}
+		} else {
+			if _, err := io.CopyN(io.Discard, f, offset); err != nil {
+				_ = f.Close()
+				return nil, err
+			}
+		}
+	}
+
+	if length < 0 {
+		return f, nil
+	}
+
+	return &struct {
+		io.Reader
+		io.Closer
+	}{
+		Reader: io.LimitReader(f, length),
+		Closer: f,
+	}, nil
+}
+
 func (d *fsDriver) WalkDir(ctx context.Context, path string, fn iofs.WalkDirFunc) error {
 	return filesystem.WalkDir(ctx, filesystem.Sub(d.fs, path), ".", fn)
 }
@@ -74,6 +109,29 @@ func (d *fsDriver) PutContent(ctx context.Context, path string, contents []byte)
 	return writer.Commit(ctx)
 }
 
+var _ driver.ExclusiveWriter = &fsDriver{}
+
+func (d *fsDriver) PutContentIfAbsent(ctx context.Context, pathname string, contents []byte) error {
+	if dir := path.Dir(pathname); dir != "" {
+		if err := filesystem.MkdirAll(ctx, d.fs, dir); err != nil {
+			return err
+		}
+	}
+
+	file, err := d.fs.OpenFile(ctx, pathname, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o666)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(contents); err != nil {
+		_ = file.Close()
+		_ = d.fs.RemoveAll(ctx, pathname)
+		return err
+	}
+
+	return file.Close()
+}
+
 func (d *fsDriver) Writer(ctx context.Context, pathname string, append bool) (driver.FileWriter, error) {
 	dir := path.Dir(pathname)
 	if dir != "" {
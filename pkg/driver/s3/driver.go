@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
+	"iter"
 	"net/http"
 	"os"
 	"path"
@@ -137,6 +138,114 @@ func (d *s3Driver) Reader(ctx context.Context, name string) (io.ReadCloser, erro
 	return r, nil
 }
 
+var _ driver.RangeReader = (*s3Driver)(nil)
+
+func (d *s3Driver) ReadRange(ctx context.Context, name string, offset int64, length int64) (io.ReadCloser, error) {
+	if err := d.ensureBucket(ctx); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.presignedURL(name, http.MethodGet, 5*time.Minute), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if length < 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	} else {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+	}
+
+	resp, err := d.httpClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("open s3 object %q for range read: %w", name, err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		return resp.Body, nil
+	case http.StatusOK:
+		// range ignored by server
+		if _, err := io.CopyN(io.Discard, resp.Body, offset); err != nil {
+			_ = resp.Body.Close()
+			return nil, err
+		}
+		if length < 0 {
+			return resp.Body, nil
+		}
+		return &struct {
+			io.Reader
+			io.Closer
+		}{
+			Reader: io.LimitReader(resp.Body, length),
+			Closer: resp.Body,
+		}, nil
+	case http.StatusNotFound:
+		_ = resp.Body.Close()
+		return nil, pathError("open", name, os.ErrNotExist)
+	default:
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("open s3 object %q for range read: status code: %d", name, resp.StatusCode)
+	}
+}
+
+var _ driver.Presigner = (*s3Driver)(nil)
+
+func (d *s3Driver) PresignedURL(ctx context.Context, name string, expires time.Duration) (string, error) {
+	if err := d.ensureBucket(ctx); err != nil {
+		return "", err
+	}
+	return d.presignedURL(name, http.MethodGet, expires), nil
+}
+
+var _ driver.ExclusiveWriter = (*s3Driver)(nil)
+
+// PutContentIfAbsent 以 If-None-Match: * 条件写入，对象已存在时 S3 返回 412
+func (d *s3Driver) PutContentIfAbsent(ctx context.Context, name string, contents []byte) error {
+	if err := d.ensureBucket(ctx); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, d.presignedURL(name, http.MethodPut, 5*time.Minute), bytes.NewReader(contents))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("If-None-Match", "*")
+	req.Header.Set("Content-Type", contentType(ctx))
+
+	resp, err := d.httpClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("put s3 object %q if absent: %w", name, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+		return nil
+	case http.StatusPreconditionFailed:
+		return pathError("put", name, os.ErrExist)
+	default:
+		return fmt.Errorf("put s3 object %q if absent: status code: %d", name, resp.StatusCode)
+	}
+}
+
+func (d *s3Driver) presignedURL(name string, method string, expires time.Duration) string {
+	return d.client.GeneratePresignedURL(simples3.PresignedInput{
+		Bucket:        d.bucket,
+		ObjectKey:     d.key(name),
+		Method:        method,
+		Timestamp:     time.Now(),
+		ExpirySeconds: int(expires.Seconds()),
+	})
+}
+
+func (d *s3Driver) httpClient() *http.Client {
+	if d.client.Client != nil {
+		return d.client.Client
+	}
+	return http.DefaultClient
+}
+
 func (d *s3Driver) WalkDir(ctx context.Context, root string, fn fs.WalkDirFunc) error {
 	info, err := d.Stat(ctx, root)
 	if err != nil {
@@ -150,6 +259,113 @@ func (d *s3Driver) WalkDir(ctx context.Context, root string, fn fs.WalkDirFunc)
 	return err
 }
 
+var _ driver.DirLister = (*s3Driver)(nil)
+
+func (d *s3Driver) ListDir(ctx context.Context, name string, after string) iter.Seq2[fs.DirEntry, error] {
+	return func(yield func(fs.DirEntry, error) bool) {
+		if err := d.ensureBucket(ctx); err != nil {
+			yield(nil, err)
+			return
+		}
+
+		dirPrefix := d.dirPrefixKey(name)
+
+		input := simples3.ListInput{
+			Bucket:    d.bucket,
+			Prefix:    dirPrefix,
+			Delimiter: "/",
+		}
+
+		if after != "" {
+			// keys of sub entries always greater than the key of after
+			input.StartAfter = dirPrefix + after
+		}
+
+		stop := errors.New("stop")
+
+		// S3 按键排序，目录键带 / 后缀（v1-rc/ < v1/），与按名称的顺序不同；
+		// 暂存之后的页仍可能出现更小名称的子项，仅输出名称小于下界的部分
+		pending := make([]fs.DirEntry, 0)
+
+		flush := func(bound string, all bool) error {
+			sort.Slice(pending, func(i, j int) bool {
+				return pending[i].Name() < pending[j].Name()
+			})
+
+			n := 0
+			for _, entry := range pending {
+				if !all && entry.Name() >= bound {
+					break
+				}
+				n++
+				if !yield(entry, nil) {
+					return stop
+				}
+			}
+			pending = pending[n:]
+
+			return nil
+		}
+
+		err := d.forEachListPage(ctx, input, func(resp simples3.ListResponse) error {
+			lastKey := ""
+
+			for _, obj := range resp.Objects {
+				rel := strings.TrimPrefix(obj.Key, dirPrefix)
+				if rel == "" || strings.Contains(rel, "/") {
+					continue
+				}
+				lastKey = max(lastKey, rel)
+				if rel <= after {
+					continue
+				}
+				pending = append(pending, fs.FileInfoToDirEntry(fsutil.NewFileInfo(
+					path.Base(rel),
+					obj.Size,
+					parseS3Time(obj.LastModified),
+				)))
+			}
+
+			for _, prefix := range resp.CommonPrefixes {
+				rel := strings.TrimSuffix(strings.TrimPrefix(prefix, dirPrefix), "/")
+				if rel == "" || strings.Contains(rel, "/") {
+					continue
+				}
+				lastKey = max(lastKey, rel+"/")
+				if rel <= after {
+					continue
+				}
+				pending = append(pending, fs.FileInfoToDirEntry(fsutil.NewDirFileInfo(path.Base(rel))))
+			}
+
+			if lastKey == "" {
+				return nil
+			}
+
+			return flush(nameLowerBound(lastKey), false)
+		})
+		if err == nil {
+			err = flush("", true)
+		}
+		if err != nil && !errors.Is(err, stop) {
+			yield(nil, fmt.Errorf("list s3 dir %q: %w", name, err))
+		}
+	}
+}
+
+// nameLowerBound 键大于 key 的子项名称的下界
+//
+// 目录键为 {name}/，名称为 key 的前缀且其后的字符小于 / 时，该目录的键仍可能大于 key
+func nameLowerBound(key string) string {
+	name := strings.TrimSuffix(key, "/")
+	for i := 1; i < len(name); i++ {
+		if name[i] < '/' {
+			return name[:i]
+		}
+	}
+	return name
+}
+
 func (d *s3Driver) Move(ctx context.Context, oldName string, newName string) error {
 	if newName == oldName {
 		return nil
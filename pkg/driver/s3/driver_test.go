@@ -261,6 +261,52 @@ func TestS3DriverMoveAndDeletePagedDirectory(t *testing.T) {
 	}
 }
 
+func TestS3DriverListDirAcrossPages(t *testing.T) {
+	server, _, backend := newFakeS3Server(t)
+	driver := FromS3Endpoint(endpointForServer(t, server, "/"+testBucket+"/listed"))
+	ctx := context.Background()
+
+	// 999 个文件后 v1-rc/ 恰为首页的最后一个键，v1/ 在第二页
+	const fillerCount = 999
+	keys := make([]string, 0, fillerCount+2)
+	for i := range fillerCount {
+		keys = append(keys, fmt.Sprintf("listed/tags/a-%04d", i))
+	}
+	keys = append(keys, "listed/tags/v1-rc/link", "listed/tags/v1/link")
+
+	for _, key := range keys {
+		if _, err := backend.PutObject(testBucket, key, nil, bytes.NewReader([]byte("x")), 1, nil); err != nil {
+			t.Fatalf("seed object %q: %v", key, err)
+		}
+	}
+
+	list := func(after string) []string {
+		names := make([]string, 0)
+		for entry, err := range driver.(*s3Driver).ListDir(ctx, "tags", after) {
+			if err != nil {
+				t.Fatalf("list dir after %q: %v", after, err)
+			}
+			names = append(names, entry.Name())
+		}
+		return names
+	}
+
+	all := list("")
+	if len(all) != fillerCount+2 {
+		t.Fatalf("listed %d entries, want %d", len(all), fillerCount+2)
+	}
+	if got, want := all[fillerCount:], []string{"v1", "v1-rc"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("last entries = %v, want %v", got, want)
+	}
+
+	if got, want := list("a-0998"), []string{"v1", "v1-rc"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("entries after a-0998 = %v, want %v", got, want)
+	}
+	if got, want := list("v1"), []string{"v1-rc"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("entries after v1 = %v, want %v", got, want)
+	}
+}
+
 func TestS3WriterCancel(t *testing.T) {
 	server, _, _ := newFakeS3Server(t)
 	driver := FromS3Endpoint(endpointForServer(t, server, "/"+testBucket+"/cancel"))
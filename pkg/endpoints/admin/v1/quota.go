@@ -0,0 +1,23 @@
+package v1
+
+import (
+	"github.com/octohelm/courier/pkg/courierhttp"
+
+	adminv1 "github.com/octohelm/crkit/pkg/apis/admin/v1"
+	registryv2 "github.com/octohelm/crkit/pkg/apis/registry/v2"
+)
+
+// ListQuotaUsage 列出存储配额与用量
+type ListQuotaUsage struct {
+	courierhttp.MethodGet `path:"/quotas"`
+}
+
+func (ListQuotaUsage) ResponseData() *adminv1.QuotaUsageList {
+	return new(adminv1.QuotaUsageList)
+}
+
+func (ListQuotaUsage) ResponseErrors() []error {
+	return []error{
+		&registryv2.ErrNotImplemented{},
+	}
+}
@@ -0,0 +1,23 @@
+package v1
+
+import (
+	"github.com/octohelm/courier/pkg/courierhttp"
+
+	adminv1 "github.com/octohelm/crkit/pkg/apis/admin/v1"
+	registryv2 "github.com/octohelm/crkit/pkg/apis/registry/v2"
+)
+
+// ListReplicationStatus 列出推送复制的目标状态
+type ListReplicationStatus struct {
+	courierhttp.MethodGet `path:"/replications"`
+}
+
+func (ListReplicationStatus) ResponseData() *adminv1.ReplicationStatusList {
+	return new(adminv1.ReplicationStatusList)
+}
+
+func (ListReplicationStatus) ResponseErrors() []error {
+	return []error{
+		&registryv2.ErrNotImplemented{},
+	}
+}
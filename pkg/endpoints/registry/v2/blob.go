@@ -13,8 +13,10 @@ import (
 type GetBlob struct {
 	courierhttp.MethodGet `path:"/{name...}/blobs/{digest}"`
 
-	Name   registryv2.Name   `name:"name" in:"path"`
-	Digest registryv2.Digest `name:"digest" in:"path"`
+	Name    registryv2.Name   `name:"name" in:"path"`
+	Digest  registryv2.Digest `name:"digest" in:"path"`
+	Range   string            `name:"Range,omitzero" in:"header"`
+	IfRange string            `name:"If-Range,omitzero" in:"header"`
 }
 
 func (GetBlob) ResponseData() *io.ReadCloser {
@@ -26,6 +28,8 @@ func (GetBlob) ResponseErrors() []error {
 		&registryv2.ErrBlobUnknown{},
 		&registryv2.ErrRepositoryNameInvalid{},
 		&registryv2.ErrRepositoryUnknown{},
+		&registryv2.ErrUnauthorized{},
+		&registryv2.ErrDenied{},
 	}
 }
 
@@ -46,6 +50,8 @@ func (HeadBlob) ResponseErrors() []error {
 		&registryv2.ErrBlobUnknown{},
 		&registryv2.ErrRepositoryNameInvalid{},
 		&registryv2.ErrRepositoryUnknown{},
+		&registryv2.ErrUnauthorized{},
+		&registryv2.ErrDenied{},
 	}
 }
 
@@ -64,5 +70,7 @@ func (DeleteBlob) ResponseData() *courier.NoContent {
 func (DeleteBlob) ResponseErrors() []error {
 	return []error{
 		&registryv2.ErrBlobUnknown{},
+		&registryv2.ErrUnauthorized{},
+		&registryv2.ErrDenied{},
 	}
 }
@@ -17,6 +17,8 @@ type CreateBlobUpload struct {
 	ContentLength int               `name:"Content-Length,omitzero" in:"header"`
 	ContentType   string            `name:"Content-Type,omitzero" in:"header"`
 	Digest        registryv2.Digest `name:"digest,omitzero" in:"query"`
+	Mount         registryv2.Digest `name:"mount,omitzero" in:"query"`
+	From          registryv2.Name   `name:"from,omitzero" in:"query"`
 	Blob          io.ReadCloser     `in:"body"`
 }
 
@@ -29,6 +31,9 @@ func (CreateBlobUpload) ResponseErrors() []error {
 		&registryv2.ErrRepositoryNameInvalid{},
 		&registryv2.ErrRepositoryUnknown{},
 		&registryv2.ErrBlobInvalidDigest{},
+		&registryv2.ErrQuotaExceeded{},
+		&registryv2.ErrUnauthorized{},
+		&registryv2.ErrDenied{},
 	}
 }
 
@@ -43,6 +48,8 @@ type GetBlobUpload struct {
 func (GetBlobUpload) ResponseErrors() []error {
 	return []error{
 		&registryv2.ErrBlobUploadUnknown{},
+		&registryv2.ErrUnauthorized{},
+		&registryv2.ErrDenied{},
 	}
 }
 
@@ -58,6 +65,8 @@ type PatchBlobUpload struct {
 func (PatchBlobUpload) ResponseErrors() []error {
 	return []error{
 		&registryv2.ErrBlobUploadUnknown{},
+		&registryv2.ErrUnauthorized{},
+		&registryv2.ErrDenied{},
 	}
 }
 
@@ -78,6 +87,9 @@ func (PutBlobUpload) ResponseErrors() []error {
 		&registryv2.ErrBlobUploadUnknown{},
 		&registryv2.ErrBlobInvalidDigest{},
 		&registryv2.ErrBlobInvalidLength{},
+		&registryv2.ErrQuotaExceeded{},
+		&registryv2.ErrUnauthorized{},
+		&registryv2.ErrDenied{},
 	}
 }
 
@@ -92,5 +104,7 @@ type CancelBlobUpload struct {
 func (CancelBlobUpload) ResponseErrors() []error {
 	return []error{
 		&registryv2.ErrBlobUploadUnknown{},
+		&registryv2.ErrUnauthorized{},
+		&registryv2.ErrDenied{},
 	}
 }
@@ -9,6 +9,9 @@ import (
 // Catalog 列出仓库
 type Catalog struct {
 	courierhttp.MethodGet `path:"/_catalog"`
+
+	N    int    `name:"n,omitzero" in:"query"`
+	Last string `name:"last,omitzero" in:"query"`
 }
 
 func (Catalog) ResponseData() *registryv2.CatalogResponse {
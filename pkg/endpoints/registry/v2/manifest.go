@@ -26,6 +26,8 @@ func (GetManifest) ResponseErrors() []error {
 		&registryv2.ErrManifestUnknownRevision{},
 		&registryv2.ErrRepositoryNameInvalid{},
 		&registryv2.ErrRepositoryUnknown{},
+		&registryv2.ErrUnauthorized{},
+		&registryv2.ErrDenied{},
 	}
 }
 
@@ -47,6 +49,8 @@ func (HeadManifest) ResponseErrors() []error {
 		&registryv2.ErrManifestUnknownRevision{},
 		&registryv2.ErrRepositoryNameInvalid{},
 		&registryv2.ErrRepositoryUnknown{},
+		&registryv2.ErrUnauthorized{},
+		&registryv2.ErrDenied{},
 	}
 }
 
@@ -69,6 +73,10 @@ func (PutManifest) ResponseErrors() []error {
 		&registryv2.ErrManifestBlobUnknown{},
 		&registryv2.ErrRepositoryNameInvalid{},
 		&registryv2.ErrRepositoryUnknown{},
+		&registryv2.ErrTagImmutable{},
+		&registryv2.ErrQuotaExceeded{},
+		&registryv2.ErrUnauthorized{},
+		&registryv2.ErrDenied{},
 	}
 }
 
@@ -89,5 +97,8 @@ func (DeleteManifest) ResponseErrors() []error {
 		&registryv2.ErrManifestUnknownRevision{},
 		&registryv2.ErrRepositoryNameInvalid{},
 		&registryv2.ErrRepositoryUnknown{},
+		&registryv2.ErrTagImmutable{},
+		&registryv2.ErrUnauthorized{},
+		&registryv2.ErrDenied{},
 	}
 }
@@ -0,0 +1,31 @@
+package v2
+
+import (
+	"github.com/octohelm/courier/pkg/courierhttp"
+
+	manifestv1 "github.com/octohelm/crkit/pkg/apis/manifest/v1"
+	registryv2 "github.com/octohelm/crkit/pkg/apis/registry/v2"
+)
+
+// GetReferrers 列出 subject 指向该清单的引用清单
+type GetReferrers struct {
+	courierhttp.MethodGet `path:"/{name...}/referrers/{digest}"`
+
+	Name         registryv2.Name   `name:"name" in:"path"`
+	Digest       registryv2.Digest `name:"digest" in:"path"`
+	ArtifactType string            `name:"artifactType,omitzero" in:"query"`
+}
+
+func (GetReferrers) ResponseData() *manifestv1.OciIndex {
+	return new(manifestv1.OciIndex)
+}
+
+func (GetReferrers) ResponseErrors() []error {
+	return []error{
+		&registryv2.ErrRepositoryNameInvalid{},
+		&registryv2.ErrRepositoryUnknown{},
+		&registryv2.ErrNotImplemented{},
+		&registryv2.ErrUnauthorized{},
+		&registryv2.ErrDenied{},
+	}
+}
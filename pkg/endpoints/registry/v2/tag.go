@@ -11,6 +11,8 @@ type ListTag struct {
 	courierhttp.MethodGet `path:"/{name...}/tags/list"`
 
 	Name registryv2.Name `name:"name" in:"path"`
+	N    int             `name:"n,omitzero" in:"query"`
+	Last string          `name:"last,omitzero" in:"query"`
 }
 
 func (*ListTag) ResponseData() *registryv2.TagList {
@@ -21,5 +23,7 @@ func (ListTag) ResponseErrors() []error {
 	return []error{
 		&registryv2.ErrRepositoryUnknown{},
 		&registryv2.ErrRepositoryNameInvalid{},
+		&registryv2.ErrUnauthorized{},
+		&registryv2.ErrDenied{},
 	}
 }
@@ -33,6 +33,11 @@ func (v *CancelBlobUpload) RuntimeDoc(names ...string) ([]string, bool) {
 func (v *Catalog) RuntimeDoc(names ...string) ([]string, bool) {
 	if len(names) > 0 {
 		switch names[0] {
+		case "N":
+			return []string{}, true
+		case "Last":
+			return []string{}, true
+
 		}
 
 		return nil, false
@@ -53,6 +58,10 @@ func (v *CreateBlobUpload) RuntimeDoc(names ...string) ([]string, bool) {
 			return []string{}, true
 		case "Digest":
 			return []string{}, true
+		case "Mount":
+			return []string{}, true
+		case "From":
+			return []string{}, true
 		case "Blob":
 			return []string{}, true
 
@@ -106,6 +115,10 @@ func (v *GetBlob) RuntimeDoc(names ...string) ([]string, bool) {
 			return []string{}, true
 		case "Digest":
 			return []string{}, true
+		case "Range":
+			return []string{}, true
+		case "IfRange":
+			return []string{}, true
 
 		}
 
@@ -152,6 +165,25 @@ func (v *GetManifest) RuntimeDoc(names ...string) ([]string, bool) {
 	}, true
 }
 
+func (v *GetReferrers) RuntimeDoc(names ...string) ([]string, bool) {
+	if len(names) > 0 {
+		switch names[0] {
+		case "Name":
+			return []string{}, true
+		case "Digest":
+			return []string{}, true
+		case "ArtifactType":
+			return []string{}, true
+
+		}
+
+		return nil, false
+	}
+	return []string{
+		"列出 subject 指向该清单的引用清单",
+	}, true
+}
+
 func (v *HeadBlob) RuntimeDoc(names ...string) ([]string, bool) {
 	if len(names) > 0 {
 		switch names[0] {
@@ -193,6 +225,11 @@ func (v *ListTag) RuntimeDoc(names ...string) ([]string, bool) {
 		switch names[0] {
 		case "Name":
 			return []string{}, true
+		case "N":
+			return []string{}, true
+		case "Last":
+			return []string{}, true
+
 		}
 
 		return nil, false
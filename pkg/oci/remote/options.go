@@ -0,0 +1,37 @@
+package remote
+
+import (
+	"golang.org/x/sync/semaphore"
+)
+
+// DefaultConcurrency 未声明 WithConcurrency 时同时传输的 Blob 数
+const DefaultConcurrency = 4
+
+type Option func(o *options)
+
+// WithConcurrency 同时传输的 Blob 数，小于 1 时为 1
+func WithConcurrency(n int) Option {
+	return func(o *options) {
+		o.concurrency = n
+	}
+}
+
+type options struct {
+	concurrency int
+
+	sem *semaphore.Weighted
+}
+
+func newOptions(options ...Option) *options {
+	o := &options{
+		concurrency: DefaultConcurrency,
+	}
+
+	for _, opt := range options {
+		opt(o)
+	}
+
+	o.sem = semaphore.NewWeighted(int64(max(o.concurrency, 1)))
+
+	return o
+}
@@ -38,6 +38,27 @@ func Manifest(ctx context.Context, repo content.Repository, reference string) (o
 	return manifest(ctx, repo, d)
 }
 
+// Pull 复制 repo 中 reference 对应的清单及其引用的全部内容至 local（如本地 Namespace 的仓库），reference 为标签时同时打标签
+//
+// 与 Push 相同，Blob 并发传输并按摘要去重
+func Pull(ctx context.Context, repo content.Repository, reference string, local content.Repository, options ...Option) (oci.Manifest, error) {
+	m, err := Manifest(ctx, repo, reference)
+	if err != nil {
+		return nil, err
+	}
+
+	tag := ""
+	if _, err := v2.Reference(reference).Digest(); err != nil {
+		tag = reference
+	}
+
+	if err := Push(ctx, m, local, tag, options...); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
 func manifest(pctx context.Context, repo content.Repository, d ocispecv1.Descriptor) (finalM oci.Manifest, finalErr error) {
 	ctx, l := logr.FromContext(pctx).Start(
 		pctx, "resolve manifest",
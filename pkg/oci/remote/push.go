@@ -6,13 +6,18 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"sync"
 
 	"github.com/containerd/platforms"
 	"github.com/distribution/reference"
+	"github.com/opencontainers/go-digest"
 	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/semaphore"
 
 	"github.com/octohelm/x/logr"
 	"github.com/octohelm/x/ptr"
+	syncx "github.com/octohelm/x/sync"
 
 	"github.com/octohelm/crkit/internal/pkg/progress"
 	"github.com/octohelm/crkit/pkg/apis/registry/v2"
@@ -21,46 +26,68 @@ import (
 	"github.com/octohelm/crkit/pkg/oci/internal"
 )
 
-func PushIndex(ctx context.Context, idx oci.Index, ns content.Namespace) error {
-	for m, err := range idx.Manifests(ctx) {
-		if err != nil {
-			return fmt.Errorf("iter manifest failed: %w", err)
-		}
-		d, err := m.Descriptor(ctx)
-		if err != nil {
-			return fmt.Errorf("resolve failed: %w", err)
-		}
-
-		if d.Annotations != nil {
-			imageName := d.Annotations[ocispecv1.AnnotationBaseImageName]
-			imageRef := d.Annotations[ocispecv1.AnnotationRefName]
+func PushIndex(ctx context.Context, idx oci.Index, ns content.Namespace, options ...Option) error {
+	o := newOptions(options...)
 
-			if imageName == "" || imageRef == "" {
-				continue
-			}
+	g, gctx := errgroup.WithContext(ctx)
 
-			named, err := reference.ParseNormalizedNamed(imageName)
+	err := func() error {
+		for m, err := range idx.Manifests(ctx) {
 			if err != nil {
-				return fmt.Errorf("invalid image name: %w", err)
+				return fmt.Errorf("iter manifest failed: %w", err)
 			}
-
-			repo, err := ns.Repository(ctx, named)
+			d, err := m.Descriptor(ctx)
 			if err != nil {
-				return fmt.Errorf("resolve repo failed: %w", err)
+				return fmt.Errorf("resolve failed: %w", err)
 			}
 
-			if err := Push(ctx, m, repo, imageRef); err != nil {
-				return fmt.Errorf("push %s %s failed: %w", imageName, imageRef, err)
+			if d.Annotations != nil {
+				imageName := d.Annotations[ocispecv1.AnnotationBaseImageName]
+				imageRef := d.Annotations[ocispecv1.AnnotationRefName]
+
+				if imageName == "" || imageRef == "" {
+					continue
+				}
+
+				named, err := reference.ParseNormalizedNamed(imageName)
+				if err != nil {
+					return fmt.Errorf("invalid image name: %w", err)
+				}
+
+				repo, err := ns.Repository(ctx, named)
+				if err != nil {
+					return fmt.Errorf("resolve repo failed: %w", err)
+				}
+
+				g.Go(func() error {
+					if err := o.push(gctx, m, repo, imageRef); err != nil {
+						return fmt.Errorf("push %s %s failed: %w", imageName, imageRef, err)
+					}
+					return nil
+				})
 			}
 		}
+		return nil
+	}()
+
+	if err := g.Wait(); err != nil {
+		return err
 	}
 
-	return nil
+	return err
 }
 
-func Push(pctx context.Context, m oci.Manifest, repo content.Repository, tag string) error {
+// Push 推送清单及其引用的全部内容至 repo，tag 非空时打标签
+//
+// 子清单与 Blob 并发推送，同时传输的 Blob 数由 WithConcurrency 限制；单次推送内相同摘要的内容仅推送一次
+func Push(ctx context.Context, m oci.Manifest, repo content.Repository, tag string, options ...Option) error {
+	return newOptions(options...).push(ctx, m, repo, tag)
+}
+
+func (o *options) push(pctx context.Context, m oci.Manifest, repo content.Repository, tag string) error {
 	p := &pusher{
 		repo: repo,
+		sem:  o.sem,
 	}
 
 	ctx, l := logr.FromContext(pctx).Start(pctx, "Push", slog.Any("repo.name", p.repo.Named().Name()))
@@ -90,6 +117,12 @@ func Push(pctx context.Context, m oci.Manifest, repo content.Repository, tag str
 
 type pusher struct {
 	repo content.Repository
+	// 限制同时传输的 Blob 数，同一次 PushIndex 的各仓库共享
+	sem *semaphore.Weighted
+
+	// 单次推送内按摘要去重，跨平台共享的子清单与 Blob 仅推送一次
+	manifests syncx.Map[digest.Digest, func() error]
+	blobs     syncx.Map[digest.Digest, func() error]
 }
 
 func (w *pusher) tag(ctx context.Context, tag string, d ocispecv1.Descriptor) error {
@@ -106,13 +139,22 @@ func (w *pusher) tag(ctx context.Context, tag string, d ocispecv1.Descriptor) er
 }
 
 func (w *pusher) push(ctx context.Context, m oci.Manifest) error {
-	switch x := m.(type) {
-	case oci.Index:
-		return w.pushIndex(ctx, x)
-	case oci.Image:
-		return w.pushImage(ctx, x)
+	d, err := m.Descriptor(ctx)
+	if err != nil {
+		return err
 	}
-	return nil
+
+	do, _ := w.manifests.LoadOrStore(d.Digest, sync.OnceValue(func() error {
+		switch x := m.(type) {
+		case oci.Index:
+			return w.pushIndex(ctx, x)
+		case oci.Image:
+			return w.pushImage(ctx, x)
+		}
+		return nil
+	}))
+
+	return do()
 }
 
 func (p *pusher) pushIndex(ctx context.Context, idx oci.Index) error {
@@ -138,14 +180,31 @@ func (p *pusher) pushIndex(ctx context.Context, idx oci.Index) error {
 		return nil
 	}
 
-	for child, err := range idx.Manifests(ctx) {
-		if err != nil {
-			return fmt.Errorf("resolve manifests failed, %T: %p", idx, err)
-		}
+	// 子清单并发推送，Blob 传输数由 sem 限制
+	g, gctx := errgroup.WithContext(ctx)
+
+	err = func() error {
+		for child, err := range idx.Manifests(ctx) {
+			if err != nil {
+				return fmt.Errorf("resolve manifests failed, %T: %w", idx, err)
+			}
 
-		if err := p.push(ctx, child); err != nil {
-			return fmt.Errorf("push manifest failed: %w", err)
+			g.Go(func() error {
+				if err := p.push(gctx, child); err != nil {
+					return fmt.Errorf("push manifest failed: %w", err)
+				}
+				return nil
+			})
 		}
+		return nil
+	}()
+
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	if err != nil {
+		return err
 	}
 
 	m, err := internal.ToManifest(ctx, idx)
@@ -196,14 +255,21 @@ func (p *pusher) pushImage(ctx context.Context, img oci.Image) error {
 	if err != nil {
 		return err
 	}
-	if err := p.pushBlob(ctx, c); err != nil {
-		return err
-	}
+
+	g, gctx := errgroup.WithContext(ctx)
+
+	g.Go(func() error {
+		return p.pushBlob(gctx, c)
+	})
 
 	for b := range img.Layers(ctx) {
-		if err := p.pushBlob(ctx, b); err != nil {
-			return err
-		}
+		g.Go(func() error {
+			return p.pushBlob(gctx, b)
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return err
 	}
 
 	m, err := internal.ToManifest(ctx, img)
@@ -223,13 +289,26 @@ func (p *pusher) pushImage(ctx context.Context, img oci.Image) error {
 }
 
 func (p *pusher) pushBlob(ctx context.Context, b oci.Blob) error {
-	l := logr.FromContext(ctx)
-
 	d, err := b.Descriptor(ctx)
 	if err != nil {
 		return err
 	}
 
+	do, _ := p.blobs.LoadOrStore(d.Digest, sync.OnceValue(func() error {
+		if err := p.sem.Acquire(ctx, 1); err != nil {
+			return err
+		}
+		defer p.sem.Release(1)
+
+		return p.transferBlob(ctx, b, d)
+	}))
+
+	return do()
+}
+
+func (p *pusher) transferBlob(ctx context.Context, b oci.Blob, d ocispecv1.Descriptor) error {
+	l := logr.FromContext(ctx)
+
 	l = l.WithValues(
 		slog.Any("repo.name", p.repo.Named().Name()),
 		slog.Any("digest", d.Digest),
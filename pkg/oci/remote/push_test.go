@@ -0,0 +1,188 @@
+package remote_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/distribution/reference"
+	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/octohelm/unifs/pkg/filesystem/local"
+	"github.com/octohelm/unifs/pkg/units"
+	. "github.com/octohelm/x/testing/v2"
+
+	manifestv1 "github.com/octohelm/crkit/pkg/apis/manifest/v1"
+	"github.com/octohelm/crkit/pkg/content"
+	contentfs "github.com/octohelm/crkit/pkg/content/fs"
+	driverfs "github.com/octohelm/crkit/pkg/driver/fs"
+	"github.com/octohelm/crkit/pkg/oci"
+	"github.com/octohelm/crkit/pkg/oci/empty"
+	"github.com/octohelm/crkit/pkg/oci/mutate"
+	"github.com/octohelm/crkit/pkg/oci/partial"
+	"github.com/octohelm/crkit/pkg/oci/random"
+	"github.com/octohelm/crkit/pkg/oci/remote"
+)
+
+func TestPush(t *testing.T) {
+	ctx := t.Context()
+
+	newRepo := func(t *testing.T, name string) content.Repository {
+		return MustValue(t, func() (content.Repository, error) {
+			named, err := reference.WithName(name)
+			if err != nil {
+				return nil, err
+			}
+			return contentfs.NewNamespace(driverfs.FromFileSystem(local.NewFS(t.TempDir()))).Repository(ctx, named)
+		})
+	}
+
+	// 各平台共享基础镜像的层
+	idx := MustValue(t, func() (oci.Index, error) {
+		base, err := random.Image(int64(10*units.KiB), 1)
+		if err != nil {
+			return nil, err
+		}
+
+		manifests := make([]oci.Manifest, 0, 3)
+
+		for _, platform := range []string{"linux/amd64", "linux/arm64", "linux/riscv64"} {
+			img, err := mutate.AppendLayers(base, partial.BlobFromBytes([]byte(platform), ocispecv1.Descriptor{MediaType: ocispecv1.MediaTypeImageLayer}))
+			if err != nil {
+				return nil, err
+			}
+			img, err = mutate.WithPlatform(img, platform)
+			if err != nil {
+				return nil, err
+			}
+			manifests = append(manifests, img)
+		}
+
+		return mutate.AppendManifests(empty.Index, manifests...)
+	})
+
+	repo := &trackingRepository{Repository: newRepo(t, "library/app")}
+
+	Must(t, func() error {
+		return remote.Push(ctx, idx, repo, "latest", remote.WithConcurrency(2))
+	})
+
+	Then(t, "共享的 Blob 仅推送一次，同时传输数不超过并发数",
+		// 共享层 1 + 各平台的层 3 + 各平台的配置 3
+		Expect(repo.written.Load(), Equal(int32(7))),
+		Expect(repo.maxInflight.Load() <= 2, Equal(true)),
+	)
+
+	t.Run("拉取至本地仓库", func(t *testing.T) {
+		local := newRepo(t, "mirror/app")
+
+		m := MustValue(t, func() (oci.Manifest, error) {
+			return remote.Pull(ctx, repo, "latest", local)
+		})
+
+		Then(t, "内容与标签完整复制",
+			ExpectMustValue(func() (bool, error) {
+				d, err := m.Descriptor(ctx)
+				if err != nil {
+					return false, err
+				}
+
+				tags, err := local.Tags(ctx)
+				if err != nil {
+					return false, err
+				}
+
+				current, err := tags.Get(ctx, "latest")
+				if err != nil {
+					return false, err
+				}
+
+				return current.Digest == d.Digest, nil
+			}, Equal(true)),
+			ExpectDo(func() error {
+				m, err := remote.Manifest(ctx, local, "latest")
+				if err != nil {
+					return err
+				}
+
+				for child, err := range m.(oci.Index).Manifests(ctx) {
+					if err != nil {
+						return err
+					}
+					for layer, err := range child.(oci.Image).Layers(ctx) {
+						if err != nil {
+							return err
+						}
+						d, err := layer.Descriptor(ctx)
+						if err != nil {
+							return err
+						}
+						blobs, err := local.Blobs(ctx)
+						if err != nil {
+							return err
+						}
+						if _, err := blobs.Info(ctx, d.Digest); err != nil {
+							return err
+						}
+					}
+				}
+
+				return nil
+			}),
+		)
+	})
+}
+
+// trackingRepository 统计 Blob 写入次数与同时写入的最大数
+type trackingRepository struct {
+	content.Repository
+
+	written     atomic.Int32
+	inflight    atomic.Int32
+	maxInflight atomic.Int32
+}
+
+func (r *trackingRepository) Blobs(ctx context.Context) (content.BlobStore, error) {
+	bs, err := r.Repository.Blobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &trackingBlobStore{BlobStore: bs, repo: r}, nil
+}
+
+type trackingBlobStore struct {
+	content.BlobStore
+
+	repo *trackingRepository
+}
+
+func (bs *trackingBlobStore) Writer(ctx context.Context) (content.BlobWriter, error) {
+	w, err := bs.BlobStore.Writer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	bs.repo.written.Add(1)
+
+	n := bs.repo.inflight.Add(1)
+	for {
+		m := bs.repo.maxInflight.Load()
+		if n <= m || bs.repo.maxInflight.CompareAndSwap(m, n) {
+			break
+		}
+	}
+
+	return &trackingBlobWriter{BlobWriter: w, repo: bs.repo}, nil
+}
+
+type trackingBlobWriter struct {
+	content.BlobWriter
+
+	repo *trackingRepository
+}
+
+func (w *trackingBlobWriter) Commit(ctx context.Context, expected manifestv1.Descriptor) (*manifestv1.Descriptor, error) {
+	defer w.repo.inflight.Add(-1)
+
+	return w.BlobWriter.Commit(ctx, expected)
+}
@@ -0,0 +1,178 @@
+package accesspolicy
+
+import (
+	"context"
+	"slices"
+
+	"github.com/gobwas/glob"
+
+	apiregistryv2 "github.com/octohelm/crkit/pkg/apis/registry/v2"
+	"github.com/octohelm/crkit/pkg/registryhttp/tokenauth"
+)
+
+const (
+	ActionPull   = tokenauth.ActionPull
+	ActionPush   = tokenauth.ActionPush
+	ActionDelete = tokenauth.ActionDelete
+	ActionAdmin  = tokenauth.ActionAdmin
+	ActionAll    = tokenauth.ActionAll
+)
+
+// RegistryScope 不针对具体仓库的管理操作以此作为仓库名校验，仅匹配空仓库名的规则（如 `**`）可授权
+const RegistryScope = ""
+
+// +gengo:injectable:provider
+type AccessController interface {
+	Authorize(ctx context.Context, name string, action string) error
+	ImmutableTag(name string, tag string) bool
+}
+
+// Config 访问策略配置
+type Config struct {
+	// 未声明时不限制访问
+	Policies []Policy `json:"policies,omitzero"`
+	// 不可变标签规则
+	ImmutableTags []ImmutableTagRule `json:"immutableTags,omitzero"`
+}
+
+// Policy 命中 Subjects 与 Repositories 的请求允许执行 Actions
+type Policy struct {
+	// 用户名规则
+	Subjects Patterns `json:"subjects,omitzero"`
+	// 是否适用于未认证的请求
+	Anonymous bool `json:"anonymous,omitzero"`
+	// 仓库名规则
+	Repositories Patterns `json:"repositories"`
+	// 允许的动作 pull / push / delete / admin，`*` 表示全部
+	Actions []string `json:"actions"`
+}
+
+// ImmutableTagRule 命中 Repositories 与 Tags 的标签一经创建不可指向其他清单
+type ImmutableTagRule struct {
+	// 仓库名规则
+	Repositories Patterns `json:"repositories"`
+	// 标签规则，如 `v*.*.*`
+	Tags Patterns `json:"tags"`
+}
+
+func NewAccessController(c *Config) (AccessController, error) {
+	ac := &accessController{
+		policies:      make([]*policy, 0, len(c.Policies)),
+		immutableTags: make([]*immutableTagRule, 0, len(c.ImmutableTags)),
+	}
+
+	for _, p := range c.Policies {
+		compiled := &policy{
+			anonymous: p.Anonymous,
+			actions:   p.Actions,
+		}
+
+		if len(p.Subjects) > 0 {
+			subjects, err := p.Subjects.Compile()
+			if err != nil {
+				return nil, err
+			}
+			compiled.subjects = subjects
+		}
+
+		repositories, err := p.Repositories.Compile()
+		if err != nil {
+			return nil, err
+		}
+		compiled.repositories = repositories
+
+		ac.policies = append(ac.policies, compiled)
+	}
+
+	for _, r := range c.ImmutableTags {
+		repositories, err := r.Repositories.Compile()
+		if err != nil {
+			return nil, err
+		}
+
+		tags, err := r.Tags.Compile()
+		if err != nil {
+			return nil, err
+		}
+
+		ac.immutableTags = append(ac.immutableTags, &immutableTagRule{
+			repositories: repositories,
+			tags:         tags,
+		})
+	}
+
+	return ac, nil
+}
+
+type accessController struct {
+	policies      []*policy
+	immutableTags []*immutableTagRule
+}
+
+// Authorize 任一策略允许即通过；未认证的请求被拒绝时返回 ErrUnauthorized，否则返回 ErrDenied
+func (ac *accessController) Authorize(ctx context.Context, name string, action string) error {
+	if len(ac.policies) == 0 {
+		return nil
+	}
+
+	subject := ""
+	if claims, ok := tokenauth.ClaimsFromContext(ctx); ok {
+		subject = claims.Subject
+	}
+
+	for _, p := range ac.policies {
+		if p.allows(subject, name, action) {
+			return nil
+		}
+	}
+
+	if subject == "" {
+		return &apiregistryv2.ErrUnauthorized{
+			Name:   name,
+			Action: action,
+		}
+	}
+
+	return &apiregistryv2.ErrDenied{
+		Name:    name,
+		Action:  action,
+		Subject: subject,
+	}
+}
+
+type policy struct {
+	subjects     glob.Glob
+	anonymous    bool
+	repositories glob.Glob
+	actions      []string
+}
+
+func (p *policy) allows(subject string, name string, action string) bool {
+	if subject == "" {
+		if !p.anonymous {
+			return false
+		}
+	} else if p.subjects == nil || !p.subjects.Match(subject) {
+		return false
+	}
+
+	if !p.repositories.Match(name) {
+		return false
+	}
+
+	return slices.Contains(p.actions, action) || slices.Contains(p.actions, ActionAll)
+}
+
+func (ac *accessController) ImmutableTag(name string, tag string) bool {
+	for _, r := range ac.immutableTags {
+		if r.repositories.Match(name) && r.tags.Match(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+type immutableTagRule struct {
+	repositories glob.Glob
+	tags         glob.Glob
+}
@@ -0,0 +1,94 @@
+package accesspolicy_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/octohelm/x/cmp"
+	. "github.com/octohelm/x/testing/v2"
+
+	apiregistryv2 "github.com/octohelm/crkit/pkg/apis/registry/v2"
+	"github.com/octohelm/crkit/pkg/registryhttp/accesspolicy"
+	"github.com/octohelm/crkit/pkg/registryhttp/tokenauth"
+)
+
+func TestAccessController(t *testing.T) {
+	ac := MustValue(t, func() (accesspolicy.AccessController, error) {
+		return accesspolicy.NewAccessController(&accesspolicy.Config{
+			Policies: []accesspolicy.Policy{
+				{
+					Anonymous:    true,
+					Repositories: accesspolicy.Patterns{"public/**"},
+					Actions:      []string{accesspolicy.ActionPull},
+				},
+				{
+					Subjects:     accesspolicy.Patterns{"*"},
+					Repositories: accesspolicy.Patterns{"**", "!prod/**"},
+					Actions:      []string{accesspolicy.ActionPull, accesspolicy.ActionPush},
+				},
+				{
+					Subjects:     accesspolicy.Patterns{"ci-*"},
+					Repositories: accesspolicy.Patterns{"prod/*"},
+					Actions:      []string{accesspolicy.ActionAll},
+				},
+			},
+		})
+	})
+
+	as := func(subject string) context.Context {
+		return tokenauth.ClaimsInjectContext(context.Background(), &tokenauth.Claims{Subject: subject})
+	}
+
+	t.Run("未认证请求", func(t *testing.T) {
+		err := ac.Authorize(context.Background(), "library/app", accesspolicy.ActionPull)
+		_, unauthorized := errors.AsType[*apiregistryv2.ErrUnauthorized](err)
+
+		Then(t, "仅可拉取公开仓库",
+			Expect(ac.Authorize(context.Background(), "public/app", accesspolicy.ActionPull), Be(cmp.Nil[error]())),
+			Expect(unauthorized, Equal(true)),
+		)
+	})
+
+	t.Run("认证用户", func(t *testing.T) {
+		err := ac.Authorize(as("alice"), "prod/app", accesspolicy.ActionPush)
+		denied, ok := errors.AsType[*apiregistryv2.ErrDenied](err)
+
+		Then(t, "排除的仓库返回 DENIED",
+			Expect(ac.Authorize(as("alice"), "library/app", accesspolicy.ActionPush), Be(cmp.Nil[error]())),
+			Expect(ok, Equal(true)),
+			Expect(denied.Subject, Equal("alice")),
+		)
+	})
+
+	t.Run("匹配用户名规则的用户", func(t *testing.T) {
+		Then(t, "`*` 不跨越 `/`",
+			Expect(ac.Authorize(as("ci-bot"), "prod/app", accesspolicy.ActionDelete), Be(cmp.Nil[error]())),
+			Expect(ac.Authorize(as("ci-bot"), "prod/team/app", accesspolicy.ActionDelete) != nil, Equal(true)),
+		)
+	})
+}
+
+func TestImmutableTag(t *testing.T) {
+	ac := MustValue(t, func() (accesspolicy.AccessController, error) {
+		return accesspolicy.NewAccessController(&accesspolicy.Config{
+			ImmutableTags: []accesspolicy.ImmutableTagRule{
+				{
+					Repositories: accesspolicy.Patterns{"prod/**"},
+					Tags:         accesspolicy.Patterns{"v*.*.*", "!*-rc*"},
+				},
+			},
+		})
+	})
+
+	Then(t, "仅命中规则的标签不可变",
+		Expect(ac.ImmutableTag("prod/team/app", "v1.2.3"), Equal(true)),
+		Expect(ac.ImmutableTag("prod/team/app", "v1.2.3-rc1"), Equal(false)),
+		Expect(ac.ImmutableTag("prod/team/app", "latest"), Equal(false)),
+		Expect(ac.ImmutableTag("dev/app", "v1.2.3"), Equal(false)),
+	)
+
+	Then(t, "未声明访问策略时不限制访问",
+		Expect(ac.Authorize(context.Background(), "prod/app", accesspolicy.ActionPush), Be(cmp.Nil[error]())),
+	)
+}
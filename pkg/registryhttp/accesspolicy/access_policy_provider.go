@@ -0,0 +1,41 @@
+package accesspolicy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// +gengo:injectable:provider
+type AccessPolicyProvider struct {
+	// 访问策略配置文件（JSON），声明时启用访问控制
+	AccessPolicyFile string `flag:",omitzero"`
+
+	accessController AccessController `provide:""`
+}
+
+func (p *AccessPolicyProvider) afterInit(ctx context.Context) error {
+	if p.AccessPolicyFile == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(p.AccessPolicyFile)
+	if err != nil {
+		return fmt.Errorf("读取访问策略配置文件失败: %w", err)
+	}
+
+	c := &Config{}
+	if err := json.Unmarshal(data, c); err != nil {
+		return fmt.Errorf("解析访问策略配置文件失败: %w", err)
+	}
+
+	ac, err := NewAccessController(c)
+	if err != nil {
+		return fmt.Errorf("编译访问策略失败: %w", err)
+	}
+
+	p.accessController = ac
+
+	return nil
+}
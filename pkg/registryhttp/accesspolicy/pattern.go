@@ -0,0 +1,62 @@
+package accesspolicy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gobwas/glob"
+)
+
+// Patterns glob 规则列表，`!` 开头为排除规则
+//
+// 以 `/` 为分隔符，`*` 不跨越 `/`，`**` 可跨越；仅声明排除规则时，其余均命中
+type Patterns []string
+
+func (patterns Patterns) Compile() (glob.Glob, error) {
+	rr := rules{}
+
+	for _, p := range patterns {
+		omit := strings.HasPrefix(p, "!")
+		if omit {
+			p = p[1:]
+		}
+
+		g, err := glob.Compile(p, '/')
+		if err != nil {
+			return nil, fmt.Errorf("compile failed %s: %w", p, err)
+		}
+
+		if omit {
+			rr.omits = append(rr.omits, g)
+		} else {
+			rr.includes = append(rr.includes, g)
+		}
+	}
+
+	return rr, nil
+}
+
+type rules struct {
+	includes []glob.Glob
+	omits    []glob.Glob
+}
+
+func (rr rules) Match(s string) bool {
+	for _, g := range rr.omits {
+		if g.Match(s) {
+			return false
+		}
+	}
+
+	if len(rr.includes) == 0 {
+		return len(rr.omits) > 0
+	}
+
+	for _, g := range rr.includes {
+		if g.Match(s) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,13 @@
+// +gengo:operator:register=R
+//
+//go:generate go tool gen .
+package admin
+
+import (
+	"github.com/octohelm/courier/pkg/courier"
+	"github.com/octohelm/courier/pkg/courierhttp"
+)
+
+var R = courier.NewRouter(
+	courierhttp.Group("/admin"),
+)
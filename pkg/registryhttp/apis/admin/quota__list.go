@@ -0,0 +1,53 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	adminv1 "github.com/octohelm/crkit/pkg/apis/admin/v1"
+	apiregistryv2 "github.com/octohelm/crkit/pkg/apis/registry/v2"
+	"github.com/octohelm/crkit/pkg/content/quota"
+	endpointadminv1 "github.com/octohelm/crkit/pkg/endpoints/admin/v1"
+	"github.com/octohelm/crkit/pkg/registryhttp/accesspolicy"
+)
+
+// +gengo:injectable
+type ListQuotaUsage struct {
+	endpointadminv1.ListQuotaUsage
+
+	quota  quota.Quota                   `inject:",opt"`
+	access accesspolicy.AccessController `inject:",opt"`
+}
+
+func (r *ListQuotaUsage) Output(ctx context.Context) (any, error) {
+	if r.quota == nil {
+		return nil, &apiregistryv2.ErrNotImplemented{Reason: errors.New("quota is not configured")}
+	}
+
+	usages, err := r.quota.Usages(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.access != nil {
+		// 仅列出具有 admin 权限的前缀
+		usages = slices.DeleteFunc(usages, func(u quota.Usage) bool {
+			return r.access.Authorize(ctx, u.Prefix, accesspolicy.ActionAdmin) != nil
+		})
+	}
+
+	list := &adminv1.QuotaUsageList{
+		Items: make([]adminv1.QuotaUsage, 0, len(usages)),
+	}
+
+	for _, u := range usages {
+		list.Items = append(list.Items, adminv1.QuotaUsage{
+			Prefix: u.Prefix,
+			Limit:  u.Limit,
+			Used:   u.Used,
+		})
+	}
+
+	return list, nil
+}
@@ -0,0 +1,56 @@
+package admin
+
+import (
+	"context"
+	"errors"
+
+	adminv1 "github.com/octohelm/crkit/pkg/apis/admin/v1"
+	apiregistryv2 "github.com/octohelm/crkit/pkg/apis/registry/v2"
+	endpointadminv1 "github.com/octohelm/crkit/pkg/endpoints/admin/v1"
+	"github.com/octohelm/crkit/pkg/registryhttp/accesspolicy"
+	"github.com/octohelm/crkit/pkg/registryhttp/replication"
+)
+
+// +gengo:injectable
+type ListReplicationStatus struct {
+	endpointadminv1.ListReplicationStatus
+
+	replicator replication.Replicator        `inject:",opt"`
+	access     accesspolicy.AccessController `inject:",opt"`
+}
+
+func (r *ListReplicationStatus) Output(ctx context.Context) (any, error) {
+	if r.replicator == nil {
+		return nil, &apiregistryv2.ErrNotImplemented{Reason: errors.New("replication is not configured")}
+	}
+
+	// 复制目标不属于具体仓库，需具有整个注册表的 admin 权限
+	if r.access != nil {
+		if err := r.access.Authorize(ctx, accesspolicy.RegistryScope, accesspolicy.ActionAdmin); err != nil {
+			return nil, err
+		}
+	}
+
+	statuses, err := r.replicator.Statuses(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &adminv1.ReplicationStatusList{
+		Items: make([]adminv1.ReplicationStatus, 0, len(statuses)),
+	}
+
+	for _, s := range statuses {
+		list.Items = append(list.Items, adminv1.ReplicationStatus{
+			Target:          s.Target,
+			Pending:         s.Pending,
+			Retrying:        s.Retrying,
+			OldestPendingAt: s.OldestPendingAt,
+			LastSuccessAt:   s.LastSuccessAt,
+			LastErrorAt:     s.LastErrorAt,
+			LastError:       s.LastError,
+		})
+	}
+
+	return list, nil
+}
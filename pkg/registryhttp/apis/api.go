@@ -5,6 +5,7 @@ import (
 	"github.com/octohelm/courier/pkg/courierhttp"
 	"github.com/octohelm/courier/pkg/courierhttp/handler/httprouter"
 
+	"github.com/octohelm/crkit/pkg/registryhttp/apis/admin"
 	"github.com/octohelm/crkit/pkg/registryhttp/apis/registry"
 )
 
@@ -13,6 +14,7 @@ var R = courierhttp.GroupRouter("/").With(
 	courierhttp.GroupRouter("/api/crkit").With(
 		courier.NewRouter(&httprouter.OpenAPI{}),
 		courier.NewRouter(&httprouter.OpenAPIView{}),
+		admin.R,
 	),
 
 	registry.R,
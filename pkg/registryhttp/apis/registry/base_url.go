@@ -3,9 +3,17 @@ package registry
 import (
 	"context"
 
+	"github.com/opencontainers/go-digest"
+
+	"github.com/octohelm/x/logr"
+
 	apiregistryv2 "github.com/octohelm/crkit/pkg/apis/registry/v2"
 	"github.com/octohelm/crkit/pkg/content"
 	endpointregistryv2 "github.com/octohelm/crkit/pkg/endpoints/registry/v2"
+	"github.com/octohelm/crkit/pkg/registryhttp/accesspolicy"
+	"github.com/octohelm/crkit/pkg/registryhttp/audit"
+	"github.com/octohelm/crkit/pkg/registryhttp/notification"
+	"github.com/octohelm/crkit/pkg/registryhttp/replication"
 )
 
 type BaseURL struct {
@@ -19,3 +27,37 @@ func (r *BaseURL) Output(ctx context.Context) (any, error) {
 func repository(ctx context.Context, ns content.Namespace, name apiregistryv2.Name) (content.Repository, error) {
 	return ns.Repository(ctx, name)
 }
+
+func authorize(ctx context.Context, access accesspolicy.AccessController, name apiregistryv2.Name, action string) error {
+	if access == nil {
+		return nil
+	}
+	return access.Authorize(ctx, string(name), action)
+}
+
+func notify(ctx context.Context, notifier notification.Notifier, action string, target notification.Target) {
+	if notifier == nil {
+		return
+	}
+	notifier.Notify(ctx, action, target)
+}
+
+func replicate(ctx context.Context, replicator replication.Replicator, repository string, dgst digest.Digest, tag string) {
+	if replicator == nil {
+		return
+	}
+	replicator.Replicate(ctx, repository, dgst, tag)
+}
+
+// recordAudit 审计写入失败时请求失败，避免操作在审计日志中缺失；
+// 此时操作已生效，客户端重试时以相同内容重放并补写记录
+func recordAudit(ctx context.Context, auditor audit.Auditor, r *audit.Record) error {
+	if auditor == nil {
+		return nil
+	}
+	if err := auditor.Audit(ctx, r); err != nil {
+		logr.FromContext(ctx).Error(err)
+		return err
+	}
+	return nil
+}
@@ -8,16 +8,26 @@ import (
 	apiregistryv2 "github.com/octohelm/crkit/pkg/apis/registry/v2"
 	"github.com/octohelm/crkit/pkg/content"
 	endpointregistryv2 "github.com/octohelm/crkit/pkg/endpoints/registry/v2"
+	"github.com/octohelm/crkit/pkg/registryhttp/accesspolicy"
+	"github.com/octohelm/crkit/pkg/registryhttp/audit"
+	"github.com/octohelm/crkit/pkg/registryhttp/notification"
 )
 
 // +gengo:injectable
 type DeleteBlob struct {
 	endpointregistryv2.DeleteBlob
 
-	namespace content.Namespace `inject:""`
+	namespace content.Namespace             `inject:""`
+	access    accesspolicy.AccessController `inject:",opt"`
+	notifier  notification.Notifier         `inject:",opt"`
+	auditor   audit.Auditor                 `inject:",opt"`
 }
 
 func (req *DeleteBlob) Output(ctx context.Context) (any, error) {
+	if err := authorize(ctx, req.access, apiregistryv2.Name(req.Name), accesspolicy.ActionDelete); err != nil {
+		return nil, err
+	}
+
 	repo, err := repository(ctx, req.namespace, apiregistryv2.Name(req.Name))
 	if err != nil {
 		return nil, err
@@ -32,5 +42,20 @@ func (req *DeleteBlob) Output(ctx context.Context) (any, error) {
 	if err != nil {
 		return nil, err
 	}
+
+	if err := recordAudit(ctx, req.auditor, &audit.Record{
+		Action:     audit.ActionBlobDelete,
+		Repository: repo.Named().Name(),
+		Reference:  string(req.Digest),
+		OldDigest:  digest.Digest(req.Digest),
+	}); err != nil {
+		return nil, err
+	}
+
+	notify(ctx, req.notifier, notification.ActionDelete, notification.Target{
+		Digest:     digest.Digest(req.Digest),
+		Repository: repo.Named().Name(),
+	})
+
 	return nil, nil
 }
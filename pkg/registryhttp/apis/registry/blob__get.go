@@ -2,7 +2,10 @@ package registry
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"strings"
 
 	"github.com/opencontainers/go-digest"
 
@@ -11,16 +14,24 @@ import (
 	apiregistryv2 "github.com/octohelm/crkit/pkg/apis/registry/v2"
 	"github.com/octohelm/crkit/pkg/content"
 	endpointregistryv2 "github.com/octohelm/crkit/pkg/endpoints/registry/v2"
+	"github.com/octohelm/crkit/pkg/registryhttp/accesspolicy"
+	"github.com/octohelm/crkit/pkg/registryhttp/notification"
 )
 
 // +gengo:injectable
 type GetBlob struct {
 	endpointregistryv2.GetBlob
 
-	namespace content.Namespace `inject:""`
+	namespace content.Namespace             `inject:""`
+	access    accesspolicy.AccessController `inject:",opt"`
+	notifier  notification.Notifier         `inject:",opt"`
 }
 
 func (req *GetBlob) Output(ctx context.Context) (any, error) {
+	if err := authorize(ctx, req.access, apiregistryv2.Name(req.Name), accesspolicy.ActionPull); err != nil {
+		return nil, err
+	}
+
 	repo, err := repository(ctx, req.namespace, apiregistryv2.Name(req.Name))
 	if err != nil {
 		return nil, err
@@ -36,15 +47,93 @@ func (req *GetBlob) Output(ctx context.Context) (any, error) {
 		return nil, err
 	}
 
+	etag := fmt.Sprintf("%q", req.Digest)
+
+	target := notification.Target{
+		MediaType:  desc.MediaType,
+		Digest:     desc.Digest,
+		Size:       desc.Size,
+		Repository: repo.Named().Name(),
+		URL:        fmt.Sprintf("/v2/%s/blobs/%s", repo.Named().Name(), desc.Digest),
+	}
+
+	// https://www.rfc-editor.org/rfc/rfc9110#name-range-requests
+	// 不支持的 Range（其他单位、多个范围或格式错误）按规范忽略，返回完整内容
+	if rangeProvider, ok := blobs.(content.RangeProvider); ok && req.Range != "" && req.ifRangeMatched(etag) {
+		rng, err := apiregistryv2.ParseByteRange(req.Range, desc.Size)
+
+		switch {
+		case err == nil:
+			b, err := rangeProvider.OpenRange(ctx, digest.Digest(req.Digest), rng.Start, rng.Length)
+			if err != nil {
+				return nil, err
+			}
+
+			notify(ctx, req.notifier, notification.ActionPull, target)
+
+			return courierhttp.Wrap(
+				b,
+				courierhttp.WithStatusCode(http.StatusPartialContent),
+				courierhttp.WithMetadata("Docker-Content-Digest", string(req.Digest)),
+				courierhttp.WithMetadata("ETag", etag),
+				courierhttp.WithMetadata("Accept-Ranges", "bytes"),
+				courierhttp.WithMetadata("Content-Type", desc.MediaType),
+				courierhttp.WithMetadata("Content-Range", rng.ContentRange(desc.Size)),
+				courierhttp.WithMetadata("Content-Length", fmt.Sprintf("%d", rng.Length)),
+			), nil
+		case errors.Is(err, apiregistryv2.ErrRangeNotSatisfiable):
+			// https://www.rfc-editor.org/rfc/rfc9110#name-416-range-not-satisfiable
+			// 416 须以 Content-Range: bytes */<size> 告知完整长度
+			return courierhttp.Wrap[any](
+				nil,
+				courierhttp.WithStatusCode(http.StatusRequestedRangeNotSatisfiable),
+				courierhttp.WithMetadata("Content-Range", fmt.Sprintf("bytes */%d", desc.Size)),
+				courierhttp.WithMetadata("Accept-Ranges", "bytes"),
+			), nil
+		}
+	}
+
+	// 存储支持预签名时重定向，客户端直接从存储下载；否则由服务转发
+	if presigner, ok := blobs.(content.PresignedURLProvider); ok {
+		u, err := presigner.PresignedURL(ctx, digest.Digest(req.Digest))
+		if err == nil {
+			notify(ctx, req.notifier, notification.ActionPull, target)
+
+			return courierhttp.Wrap[any](
+				nil,
+				courierhttp.WithStatusCode(http.StatusTemporaryRedirect),
+				courierhttp.WithMetadata("Location", u),
+				courierhttp.WithMetadata("Docker-Content-Digest", string(req.Digest)),
+			), nil
+		}
+
+		if _, ok := errors.AsType[*apiregistryv2.ErrNotImplemented](err); !ok {
+			return nil, err
+		}
+	}
+
 	b, err := blobs.Open(ctx, digest.Digest(req.Digest))
 	if err != nil {
 		return nil, err
 	}
 
+	notify(ctx, req.notifier, notification.ActionPull, target)
+
 	return courierhttp.Wrap(
 		b,
 		courierhttp.WithMetadata("Docker-Content-Digest", string(req.Digest)),
+		courierhttp.WithMetadata("ETag", etag),
+		courierhttp.WithMetadata("Accept-Ranges", "bytes"),
 		courierhttp.WithMetadata("Content-Type", desc.MediaType),
 		courierhttp.WithMetadata("Content-Length", fmt.Sprintf("%d", desc.Size)),
 	), nil
 }
+
+// ifRangeMatched blob 内容由 digest 确定，If-Range 仅支持以 digest 作为强 ETag
+func (req *GetBlob) ifRangeMatched(etag string) bool {
+	if req.IfRange == "" {
+		return true
+	}
+	ifRange := strings.TrimSpace(req.IfRange)
+	return ifRange == etag || ifRange == string(req.Digest)
+}
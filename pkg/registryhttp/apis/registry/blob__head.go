@@ -11,16 +11,22 @@ import (
 	apiregistryv2 "github.com/octohelm/crkit/pkg/apis/registry/v2"
 	"github.com/octohelm/crkit/pkg/content"
 	endpointregistryv2 "github.com/octohelm/crkit/pkg/endpoints/registry/v2"
+	"github.com/octohelm/crkit/pkg/registryhttp/accesspolicy"
 )
 
 // +gengo:injectable
 type HeadBlob struct {
 	endpointregistryv2.HeadBlob
 
-	namespace content.Namespace `inject:""`
+	namespace content.Namespace             `inject:""`
+	access    accesspolicy.AccessController `inject:",opt"`
 }
 
 func (req *HeadBlob) Output(ctx context.Context) (any, error) {
+	if err := authorize(ctx, req.access, apiregistryv2.Name(req.Name), accesspolicy.ActionPull); err != nil {
+		return nil, err
+	}
+
 	repo, err := repository(ctx, req.namespace, apiregistryv2.Name(req.Name))
 	if err != nil {
 		return nil, err
@@ -2,6 +2,7 @@ package registry
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -14,6 +15,9 @@ import (
 	apiregistryv2 "github.com/octohelm/crkit/pkg/apis/registry/v2"
 	"github.com/octohelm/crkit/pkg/content"
 	endpointregistryv2 "github.com/octohelm/crkit/pkg/endpoints/registry/v2"
+	"github.com/octohelm/crkit/pkg/registryhttp/accesspolicy"
+	"github.com/octohelm/crkit/pkg/registryhttp/audit"
+	"github.com/octohelm/crkit/pkg/registryhttp/notification"
 )
 
 // CreateBlobUpload
@@ -21,12 +25,19 @@ import (
 type CreateBlobUpload struct {
 	endpointregistryv2.CreateBlobUpload
 
-	namespace content.Namespace `inject:""`
+	namespace content.Namespace             `inject:""`
+	access    accesspolicy.AccessController `inject:",opt"`
+	notifier  notification.Notifier         `inject:",opt"`
+	auditor   audit.Auditor                 `inject:",opt"`
 }
 
 func (req *CreateBlobUpload) Output(ctx context.Context) (any, error) {
 	defer req.Blob.Close()
 
+	if err := authorize(ctx, req.access, apiregistryv2.Name(req.Name), accesspolicy.ActionPush); err != nil {
+		return nil, err
+	}
+
 	repo, err := repository(ctx, req.namespace, apiregistryv2.Name(req.Name))
 	if err != nil {
 		return nil, err
@@ -55,6 +66,23 @@ func (req *CreateBlobUpload) Output(ctx context.Context) (any, error) {
 			return nil, err
 		}
 
+		if err := recordAudit(ctx, req.auditor, &audit.Record{
+			Action:     audit.ActionBlobPut,
+			Repository: repo.Named().Name(),
+			Reference:  d.Digest.String(),
+			NewDigest:  d.Digest,
+		}); err != nil {
+			return nil, err
+		}
+
+		notify(ctx, req.notifier, notification.ActionPush, notification.Target{
+			MediaType:  d.MediaType,
+			Digest:     d.Digest,
+			Size:       d.Size,
+			Repository: repo.Named().Name(),
+			URL:        fmt.Sprintf("/v2/%s/blobs/%s", repo.Named().Name(), d.Digest),
+		})
+
 		return courierhttp.Wrap[any](
 			nil,
 			courierhttp.WithStatusCode(http.StatusCreated),
@@ -68,6 +96,46 @@ func (req *CreateBlobUpload) Output(ctx context.Context) (any, error) {
 		return nil, err
 	}
 
+	// https://github.com/opencontainers/distribution-spec/blob/main/spec.md#mounting-a-blob-from-another-repository
+	// 缺少源仓库的 pull 权限时，退化为普通上传
+	if req.Mount != "" && req.From != "" && authorize(ctx, req.access, req.From, accesspolicy.ActionPull) == nil {
+		if mounter, ok := blobs.(content.Mounter); ok {
+			d, err := mounter.Mount(ctx, apiregistryv2.Name(req.From), digest.Digest(req.Mount))
+			if err == nil {
+				if err := recordAudit(ctx, req.auditor, &audit.Record{
+					Action:     audit.ActionBlobMount,
+					Repository: repo.Named().Name(),
+					Reference:  d.Digest.String(),
+					From:       string(req.From),
+					NewDigest:  d.Digest,
+				}); err != nil {
+					return nil, err
+				}
+
+				notify(ctx, req.notifier, notification.ActionMount, notification.Target{
+					MediaType:      d.MediaType,
+					Digest:         d.Digest,
+					Size:           d.Size,
+					Repository:     repo.Named().Name(),
+					URL:            fmt.Sprintf("/v2/%s/blobs/%s", repo.Named().Name(), d.Digest),
+					FromRepository: string(req.From),
+				})
+
+				return courierhttp.Wrap[any](
+					nil,
+					courierhttp.WithStatusCode(http.StatusCreated),
+					courierhttp.WithMetadata("Docker-Content-Digest", d.Digest.String()),
+					courierhttp.WithMetadata("Location", fmt.Sprintf("/v2/%s/blobs/%s", repo.Named().Name(), d.Digest.String())),
+				), nil
+			}
+
+			// fallback to upload session when blob not found in from
+			if !isBlobUnknown(err) {
+				return nil, err
+			}
+		}
+	}
+
 	w, err := blobs.Writer(ctx)
 	if err != nil {
 		return nil, err
@@ -80,3 +148,13 @@ func (req *CreateBlobUpload) Output(ctx context.Context) (any, error) {
 		courierhttp.WithMetadata("Location", fmt.Sprintf("/v2/%s/blobs/uploads/%s", repo.Named().Name(), w.ID())),
 	), nil
 }
+
+func isBlobUnknown(err error) bool {
+	if _, ok := errors.AsType[*apiregistryv2.ErrBlobUnknown](err); ok {
+		return true
+	}
+	if _, ok := errors.AsType[*apiregistryv2.ErrManifestBlobUnknown](err); ok {
+		return true
+	}
+	return false
+}
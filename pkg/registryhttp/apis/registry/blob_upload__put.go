@@ -14,18 +14,28 @@ import (
 	apiregistryv2 "github.com/octohelm/crkit/pkg/apis/registry/v2"
 	"github.com/octohelm/crkit/pkg/content"
 	endpointregistryv2 "github.com/octohelm/crkit/pkg/endpoints/registry/v2"
+	"github.com/octohelm/crkit/pkg/registryhttp/accesspolicy"
+	"github.com/octohelm/crkit/pkg/registryhttp/audit"
+	"github.com/octohelm/crkit/pkg/registryhttp/notification"
 )
 
 // +gengo:injectable
 type PutBlobUpload struct {
 	endpointregistryv2.PutBlobUpload
 
-	namespace content.Namespace `inject:""`
+	namespace content.Namespace             `inject:""`
+	access    accesspolicy.AccessController `inject:",opt"`
+	notifier  notification.Notifier         `inject:",opt"`
+	auditor   audit.Auditor                 `inject:",opt"`
 }
 
 func (req *PutBlobUpload) Output(ctx context.Context) (any, error) {
 	defer req.Chunk.Close()
 
+	if err := authorize(ctx, req.access, apiregistryv2.Name(req.Name), accesspolicy.ActionPush); err != nil {
+		return nil, err
+	}
+
 	repo, err := repository(ctx, req.namespace, apiregistryv2.Name(req.Name))
 	if err != nil {
 		return nil, err
@@ -55,6 +65,23 @@ func (req *PutBlobUpload) Output(ctx context.Context) (any, error) {
 		return nil, err
 	}
 
+	if err := recordAudit(ctx, req.auditor, &audit.Record{
+		Action:     audit.ActionBlobPut,
+		Repository: repo.Named().Name(),
+		Reference:  d.Digest.String(),
+		NewDigest:  d.Digest,
+	}); err != nil {
+		return nil, err
+	}
+
+	notify(ctx, req.notifier, notification.ActionPush, notification.Target{
+		MediaType:  d.MediaType,
+		Digest:     d.Digest,
+		Size:       d.Size,
+		Repository: repo.Named().Name(),
+		URL:        fmt.Sprintf("/v2/%s/blobs/%s", repo.Named().Name(), d.Digest),
+	})
+
 	return courierhttp.Wrap[any](
 		nil,
 		courierhttp.WithStatusCode(http.StatusCreated),
@@ -3,23 +3,71 @@ package registry
 import (
 	"context"
 
+	"github.com/octohelm/courier/pkg/courierhttp"
+
 	apiregistryv2 "github.com/octohelm/crkit/pkg/apis/registry/v2"
 	"github.com/octohelm/crkit/pkg/content"
 	"github.com/octohelm/crkit/pkg/content/collect"
 	endpointregistryv2 "github.com/octohelm/crkit/pkg/endpoints/registry/v2"
+	"github.com/octohelm/crkit/pkg/registryhttp/accesspolicy"
 )
 
 // +gengo:injectable
 type Catalog struct {
 	endpointregistryv2.Catalog
 
-	namespace content.Namespace `inject:""`
+	namespace content.Namespace             `inject:""`
+	access    accesspolicy.AccessController `inject:",opt"`
 }
 
 func (r *Catalog) Output(ctx context.Context) (any, error) {
-	names, err := collect.Catalogs(ctx, r.namespace)
+	names, err := r.visibleNames(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return &apiregistryv2.CatalogResponse{Repositories: names}, nil
+
+	names, next := paginate(names, r.N)
+
+	resp := &apiregistryv2.CatalogResponse{Repositories: names}
+
+	if next != "" {
+		return courierhttp.Wrap(
+			resp,
+			courierhttp.WithMetadata("Link", nextLink("/v2/_catalog", next, r.N)),
+		), nil
+	}
+
+	return resp, nil
+}
+
+// visibleNames 先按权限过滤再分页，过滤后不足一页时继续读取
+func (r *Catalog) visibleNames(ctx context.Context) ([]string, error) {
+	limit := pageLimit(r.N)
+
+	if r.access == nil {
+		return collect.RepositoryNames(ctx, r.namespace, r.Last, limit)
+	}
+
+	visible := make([]string, 0)
+	last := r.Last
+
+	for {
+		names, err := collect.RepositoryNames(ctx, r.namespace, last, limit)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range names {
+			// 仅列出具有 pull 权限的仓库
+			if r.access.Authorize(ctx, name, accesspolicy.ActionPull) == nil {
+				visible = append(visible, name)
+			}
+		}
+
+		if limit <= 0 || len(names) < limit || len(visible) >= limit {
+			return visible, nil
+		}
+
+		last = names[len(names)-1]
+	}
 }
@@ -0,0 +1,54 @@
+package registry
+
+import (
+	"context"
+	"errors"
+
+	"github.com/opencontainers/go-digest"
+
+	manifestv1 "github.com/octohelm/crkit/pkg/apis/manifest/v1"
+	apiregistryv2 "github.com/octohelm/crkit/pkg/apis/registry/v2"
+	"github.com/octohelm/crkit/pkg/content"
+	"github.com/octohelm/crkit/pkg/registryhttp/accesspolicy"
+)
+
+// ensureTagMutable 写入清单前预先检查，不可变标签已指向 dgst 以外的清单时拒绝变更，重复推送相同清单不受影响
+func ensureTagMutable(ctx context.Context, access accesspolicy.AccessController, repo content.Repository, name apiregistryv2.Name, tag string, dgst digest.Digest) error {
+	if access == nil || !access.ImmutableTag(string(name), tag) {
+		return nil
+	}
+
+	tags, err := repo.Tags(ctx)
+	if err != nil {
+		return err
+	}
+
+	d, err := tags.Get(ctx, tag)
+	if err != nil {
+		if _, ok := errors.AsType[*apiregistryv2.ErrTagUnknown](err); ok {
+			return nil
+		}
+		return err
+	}
+
+	if d.Digest == dgst {
+		return nil
+	}
+
+	return &apiregistryv2.ErrTagImmutable{
+		Name:   string(name),
+		Tag:    tag,
+		Digest: d.Digest,
+	}
+}
+
+// tagManifest 不可变标签以存储层的条件写入创建，避免并发推送在检查与写入之间覆盖标签
+func tagManifest(ctx context.Context, access accesspolicy.AccessController, tags content.TagService, name apiregistryv2.Name, tag string, desc manifestv1.Descriptor) error {
+	if access != nil && access.ImmutableTag(string(name), tag) {
+		if tagger, ok := tags.(content.ImmutableTagger); ok {
+			return tagger.TagIfAbsent(ctx, tag, desc)
+		}
+	}
+
+	return tags.Tag(ctx, tag, desc)
+}
@@ -3,19 +3,31 @@ package registry
 import (
 	"context"
 
+	"github.com/opencontainers/go-digest"
+
 	apiregistryv2 "github.com/octohelm/crkit/pkg/apis/registry/v2"
 	"github.com/octohelm/crkit/pkg/content"
 	endpointregistryv2 "github.com/octohelm/crkit/pkg/endpoints/registry/v2"
+	"github.com/octohelm/crkit/pkg/registryhttp/accesspolicy"
+	"github.com/octohelm/crkit/pkg/registryhttp/audit"
+	"github.com/octohelm/crkit/pkg/registryhttp/notification"
 )
 
 // +gengo:injectable
 type DeleteManifest struct {
 	endpointregistryv2.DeleteManifest
 
-	namespace content.Namespace `inject:""`
+	namespace content.Namespace             `inject:""`
+	access    accesspolicy.AccessController `inject:",opt"`
+	notifier  notification.Notifier         `inject:",opt"`
+	auditor   audit.Auditor                 `inject:",opt"`
 }
 
 func (req *DeleteManifest) Output(ctx context.Context) (any, error) {
+	if err := authorize(ctx, req.access, apiregistryv2.Name(req.Name), accesspolicy.ActionDelete); err != nil {
+		return nil, err
+	}
+
 	repo, err := repository(ctx, req.namespace, apiregistryv2.Name(req.Name))
 	if err != nil {
 		return nil, err
@@ -32,16 +44,56 @@ func (req *DeleteManifest) Output(ctx context.Context) (any, error) {
 			return nil, err
 		}
 
+		if err := recordAudit(ctx, req.auditor, &audit.Record{
+			Action:     audit.ActionManifestDelete,
+			Repository: repo.Named().Name(),
+			Reference:  string(req.Reference),
+			OldDigest:  dgst,
+		}); err != nil {
+			return nil, err
+		}
+
+		notify(ctx, req.notifier, notification.ActionDelete, notification.Target{
+			Digest:     dgst,
+			Repository: repo.Named().Name(),
+		})
+
 		return nil, nil
 	}
 
 	tag := string(req.Reference)
+
+	if err := ensureTagMutable(ctx, req.access, repo, apiregistryv2.Name(req.Name), tag, ""); err != nil {
+		return nil, err
+	}
+
 	tags, err := repo.Tags(ctx)
 	if err != nil {
 		return nil, err
 	}
+
+	var old digest.Digest
+	if current, err := tags.Get(ctx, tag); err == nil {
+		old = current.Digest
+	}
+
 	if err := tags.Untag(ctx, tag); err != nil {
 		return nil, err
 	}
+
+	if err := recordAudit(ctx, req.auditor, &audit.Record{
+		Action:     audit.ActionTagDelete,
+		Repository: repo.Named().Name(),
+		Reference:  tag,
+		OldDigest:  old,
+	}); err != nil {
+		return nil, err
+	}
+
+	notify(ctx, req.notifier, notification.ActionDelete, notification.Target{
+		Repository: repo.Named().Name(),
+		Tag:        tag,
+	})
+
 	return nil, nil
 }
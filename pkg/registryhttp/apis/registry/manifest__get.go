@@ -2,6 +2,7 @@ package registry
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/octohelm/courier/pkg/courierhttp"
 
@@ -9,29 +10,41 @@ import (
 	apiregistryv2 "github.com/octohelm/crkit/pkg/apis/registry/v2"
 	"github.com/octohelm/crkit/pkg/content"
 	endpointregistryv2 "github.com/octohelm/crkit/pkg/endpoints/registry/v2"
+	"github.com/octohelm/crkit/pkg/registryhttp/accesspolicy"
+	"github.com/octohelm/crkit/pkg/registryhttp/notification"
 )
 
 // +gengo:injectable
 type GetManifest struct {
 	endpointregistryv2.GetManifest
 
-	namespace content.Namespace `inject:""`
+	namespace content.Namespace             `inject:""`
+	access    accesspolicy.AccessController `inject:",opt"`
+	notifier  notification.Notifier         `inject:",opt"`
 }
 
 func (req *GetManifest) Output(ctx context.Context) (any, error) {
+	if err := authorize(ctx, req.access, apiregistryv2.Name(req.Name), accesspolicy.ActionPull); err != nil {
+		return nil, err
+	}
+
 	repo, err := repository(ctx, req.namespace, apiregistryv2.Name(req.Name))
 	if err != nil {
 		return nil, err
 	}
 
+	tag := ""
+
 	dgst, err := req.Reference.Digest()
 	if err != nil {
+		tag = string(req.Reference)
+
 		tags, err := repo.Tags(ctx)
 		if err != nil {
 			return nil, err
 		}
 
-		d, err := tags.Get(ctx, string(req.Reference))
+		d, err := tags.Get(ctx, tag)
 		if err != nil {
 			return nil, err
 		}
@@ -53,6 +66,20 @@ func (req *GetManifest) Output(ctx context.Context) (any, error) {
 		return nil, err
 	}
 
+	raw, _, err := p.Payload()
+	if err != nil {
+		return nil, err
+	}
+
+	notify(ctx, req.notifier, notification.ActionPull, notification.Target{
+		MediaType:  m.Type(),
+		Digest:     dgst,
+		Size:       int64(len(raw)),
+		Repository: repo.Named().Name(),
+		URL:        fmt.Sprintf("/v2/%s/manifests/%s", repo.Named().Name(), dgst),
+		Tag:        tag,
+	})
+
 	return courierhttp.Wrap(
 		p,
 		courierhttp.WithMetadata("Docker-Content-Digest", string(dgst)),
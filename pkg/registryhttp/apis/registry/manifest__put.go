@@ -2,6 +2,9 @@ package registry
 
 import (
 	"context"
+	"fmt"
+
+	"github.com/opencontainers/go-digest"
 
 	"github.com/octohelm/courier/pkg/courierhttp"
 
@@ -9,21 +12,58 @@ import (
 	apiregistryv2 "github.com/octohelm/crkit/pkg/apis/registry/v2"
 	"github.com/octohelm/crkit/pkg/content"
 	endpointregistryv2 "github.com/octohelm/crkit/pkg/endpoints/registry/v2"
+	"github.com/octohelm/crkit/pkg/registryhttp/accesspolicy"
+	"github.com/octohelm/crkit/pkg/registryhttp/audit"
+	"github.com/octohelm/crkit/pkg/registryhttp/notification"
+	"github.com/octohelm/crkit/pkg/registryhttp/replication"
 )
 
 // +gengo:injectable
 type PutManifest struct {
 	endpointregistryv2.PutManifest
 
-	namespace content.Namespace `inject:""`
+	namespace  content.Namespace             `inject:""`
+	access     accesspolicy.AccessController `inject:",opt"`
+	notifier   notification.Notifier         `inject:",opt"`
+	auditor    audit.Auditor                 `inject:",opt"`
+	replicator replication.Replicator        `inject:",opt"`
 }
 
 func (req *PutManifest) Output(ctx context.Context) (any, error) {
+	if err := authorize(ctx, req.access, apiregistryv2.Name(req.Name), accesspolicy.ActionPush); err != nil {
+		return nil, err
+	}
+
 	repo, err := repository(ctx, req.namespace, apiregistryv2.Name(req.Name))
 	if err != nil {
 		return nil, err
 	}
 
+	// https://github.com/opencontainers/distribution-spec/blob/main/spec.md#pushing-manifests
+	if dgst, err := req.Reference.Digest(); err == nil {
+		raw, _, err := req.Manifest.Payload()
+		if err != nil {
+			return nil, err
+		}
+
+		if actual := dgst.Algorithm().FromBytes(raw); actual != dgst {
+			return nil, &apiregistryv2.ErrManifestUnverified{
+				Reason: fmt.Errorf("digest %s mismatched, got %s", dgst, actual),
+			}
+		}
+	}
+
+	if tag, err := req.Reference.Tag(); err == nil {
+		_, dgst, err := req.Manifest.Payload()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := ensureTagMutable(ctx, req.access, repo, apiregistryv2.Name(req.Name), tag, dgst); err != nil {
+			return nil, err
+		}
+	}
+
 	manifests, err := repo.Manifests(ctx)
 	if err != nil {
 		return nil, err
@@ -34,17 +74,70 @@ func (req *PutManifest) Output(ctx context.Context) (any, error) {
 		return nil, err
 	}
 
+	raw, _, err := req.Manifest.Payload()
+	if err != nil {
+		return nil, err
+	}
+
+	target := notification.Target{
+		MediaType:  req.Manifest.Type(),
+		Digest:     d,
+		Size:       int64(len(raw)),
+		Repository: repo.Named().Name(),
+		URL:        fmt.Sprintf("/v2/%s/manifests/%s", repo.Named().Name(), d),
+	}
+
+	if err := recordAudit(ctx, req.auditor, &audit.Record{
+		Action:     audit.ActionManifestPut,
+		Repository: repo.Named().Name(),
+		Reference:  string(req.Reference),
+		NewDigest:  d,
+	}); err != nil {
+		return nil, err
+	}
+
 	if tag, err := req.Reference.Tag(); err == nil {
 		tags, err := repo.Tags(ctx)
 		if err != nil {
 			return nil, err
 		}
 
-		if err := tags.Tag(ctx, tag, manifestv1.Descriptor{
+		var old digest.Digest
+		if current, err := tags.Get(ctx, tag); err == nil {
+			old = current.Digest
+		}
+
+		if err := tagManifest(ctx, req.access, tags, apiregistryv2.Name(req.Name), tag, manifestv1.Descriptor{
 			Digest: d,
 		}); err != nil {
 			return nil, err
 		}
+
+		if old != d {
+			if err := recordAudit(ctx, req.auditor, &audit.Record{
+				Action:     audit.ActionTagPut,
+				Repository: repo.Named().Name(),
+				Reference:  tag,
+				OldDigest:  old,
+				NewDigest:  d,
+			}); err != nil {
+				return nil, err
+			}
+		}
+
+		target.Tag = tag
+	}
+
+	notify(ctx, req.notifier, notification.ActionPush, target)
+	replicate(ctx, req.replicator, repo.Named().Name(), d, target.Tag)
+
+	if subject := manifestv1.SubjectOf(req.Manifest.Manifest); subject != nil {
+		return courierhttp.Wrap[any](
+			nil,
+			courierhttp.WithStatusCode(201),
+			courierhttp.WithMetadata("Docker-Content-Digest", d.String()),
+			courierhttp.WithMetadata("OCI-Subject", subject.Digest.String()),
+		), nil
 	}
 
 	return courierhttp.Wrap[any](
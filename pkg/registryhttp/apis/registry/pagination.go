@@ -0,0 +1,36 @@
+package registry
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// pageLimit 多取一条用于判断是否存在下一页
+func pageLimit(n int) int {
+	if n > 0 {
+		return n + 1
+	}
+	return 0
+}
+
+func paginate(values []string, n int) (page []string, next string) {
+	if values == nil {
+		values = make([]string, 0)
+	}
+
+	if n > 0 && len(values) > n {
+		return values[:n], values[n-1]
+	}
+
+	return values, ""
+}
+
+// https://github.com/opencontainers/distribution-spec/blob/main/spec.md#listing-tags
+func nextLink(pathname string, last string, n int) string {
+	q := url.Values{}
+	q.Set("n", strconv.Itoa(n))
+	q.Set("last", last)
+
+	return fmt.Sprintf(`<%s?%s>; rel="next"`, pathname, q.Encode())
+}
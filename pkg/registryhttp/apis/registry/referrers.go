@@ -0,0 +1,65 @@
+package registry
+
+import (
+	"context"
+
+	"github.com/opencontainers/go-digest"
+
+	"github.com/octohelm/courier/pkg/courierhttp"
+
+	manifestv1 "github.com/octohelm/crkit/pkg/apis/manifest/v1"
+	apiregistryv2 "github.com/octohelm/crkit/pkg/apis/registry/v2"
+	"github.com/octohelm/crkit/pkg/content"
+	"github.com/octohelm/crkit/pkg/content/collect"
+	endpointregistryv2 "github.com/octohelm/crkit/pkg/endpoints/registry/v2"
+	"github.com/octohelm/crkit/pkg/registryhttp/accesspolicy"
+)
+
+// +gengo:injectable
+type GetReferrers struct {
+	endpointregistryv2.GetReferrers
+
+	namespace content.Namespace             `inject:""`
+	access    accesspolicy.AccessController `inject:",opt"`
+}
+
+func (req *GetReferrers) Output(ctx context.Context) (any, error) {
+	if err := authorize(ctx, req.access, apiregistryv2.Name(req.Name), accesspolicy.ActionPull); err != nil {
+		return nil, err
+	}
+
+	repo, err := repository(ctx, req.namespace, apiregistryv2.Name(req.Name))
+	if err != nil {
+		return nil, err
+	}
+
+	manifests, err := repo.Manifests(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	referrers, err := collect.Referrers(ctx, manifests, digest.Digest(req.Digest), req.ArtifactType)
+	if err != nil {
+		return nil, err
+	}
+
+	idx := &manifestv1.OciIndex{
+		MediaType: manifestv1.MediaTypeImageIndex,
+		Manifests: make([]manifestv1.Descriptor, 0, len(referrers)),
+	}
+	idx.SchemaVersion = 2
+	idx.Manifests = append(idx.Manifests, referrers...)
+
+	if req.ArtifactType != "" {
+		return courierhttp.Wrap(
+			idx,
+			courierhttp.WithMetadata("Content-Type", manifestv1.MediaTypeImageIndex),
+			courierhttp.WithMetadata("OCI-Filters-Applied", "artifactType"),
+		), nil
+	}
+
+	return courierhttp.Wrap(
+		idx,
+		courierhttp.WithMetadata("Content-Type", manifestv1.MediaTypeImageIndex),
+	), nil
+}
@@ -2,20 +2,30 @@ package registry
 
 import (
 	"context"
+	"fmt"
+
+	"github.com/octohelm/courier/pkg/courierhttp"
 
 	apiregistryv2 "github.com/octohelm/crkit/pkg/apis/registry/v2"
 	"github.com/octohelm/crkit/pkg/content"
+	"github.com/octohelm/crkit/pkg/content/collect"
 	endpointregistryv2 "github.com/octohelm/crkit/pkg/endpoints/registry/v2"
+	"github.com/octohelm/crkit/pkg/registryhttp/accesspolicy"
 )
 
 // +gengo:injectable
 type ListTag struct {
 	endpointregistryv2.ListTag
 
-	namespace content.Namespace `inject:""`
+	namespace content.Namespace             `inject:""`
+	access    accesspolicy.AccessController `inject:",opt"`
 }
 
 func (req *ListTag) Output(ctx context.Context) (any, error) {
+	if err := authorize(ctx, req.access, apiregistryv2.Name(req.Name), accesspolicy.ActionPull); err != nil {
+		return nil, err
+	}
+
 	repo, err := repository(ctx, req.namespace, apiregistryv2.Name(req.Name))
 	if err != nil {
 		return nil, err
@@ -26,13 +36,24 @@ func (req *ListTag) Output(ctx context.Context) (any, error) {
 		return nil, err
 	}
 
-	tagList, err := tags.All(ctx)
+	tagList, err := collect.Tags(ctx, tags, req.Last, pageLimit(req.N))
 	if err != nil {
 		return nil, err
 	}
 
-	return &apiregistryv2.TagList{
+	tagList, next := paginate(tagList, req.N)
+
+	resp := &apiregistryv2.TagList{
 		Name: req.Name.Name(),
 		Tags: tagList,
-	}, nil
+	}
+
+	if next != "" {
+		return courierhttp.Wrap(
+			resp,
+			courierhttp.WithMetadata("Link", nextLink(fmt.Sprintf("/v2/%s/tags/list", req.Name.Name()), next, req.N)),
+		), nil
+	}
+
+	return resp, nil
 }
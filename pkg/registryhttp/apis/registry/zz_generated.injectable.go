@@ -6,6 +6,10 @@ import (
 	fmt "fmt"
 
 	content "github.com/octohelm/crkit/pkg/content"
+	accesspolicy "github.com/octohelm/crkit/pkg/registryhttp/accesspolicy"
+	audit "github.com/octohelm/crkit/pkg/registryhttp/audit"
+	notification "github.com/octohelm/crkit/pkg/registryhttp/notification"
+	replication "github.com/octohelm/crkit/pkg/registryhttp/replication"
 )
 
 func (v *CancelBlobUpload) Init(ctx context.Context) error {
@@ -14,6 +18,9 @@ func (v *CancelBlobUpload) Init(ctx context.Context) error {
 	} else {
 		return fmt.Errorf("missing provider %T.namespace", v)
 	}
+	if value, ok := accesspolicy.AccessControllerFromContext(ctx); ok {
+		v.access = value
+	}
 
 	return nil
 }
@@ -24,6 +31,9 @@ func (v *Catalog) Init(ctx context.Context) error {
 	} else {
 		return fmt.Errorf("missing provider %T.namespace", v)
 	}
+	if value, ok := accesspolicy.AccessControllerFromContext(ctx); ok {
+		v.access = value
+	}
 
 	return nil
 }
@@ -34,6 +44,15 @@ func (v *CreateBlobUpload) Init(ctx context.Context) error {
 	} else {
 		return fmt.Errorf("missing provider %T.namespace", v)
 	}
+	if value, ok := accesspolicy.AccessControllerFromContext(ctx); ok {
+		v.access = value
+	}
+	if value, ok := notification.NotifierFromContext(ctx); ok {
+		v.notifier = value
+	}
+	if value, ok := audit.AuditorFromContext(ctx); ok {
+		v.auditor = value
+	}
 
 	return nil
 }
@@ -44,6 +63,15 @@ func (v *DeleteBlob) Init(ctx context.Context) error {
 	} else {
 		return fmt.Errorf("missing provider %T.namespace", v)
 	}
+	if value, ok := accesspolicy.AccessControllerFromContext(ctx); ok {
+		v.access = value
+	}
+	if value, ok := notification.NotifierFromContext(ctx); ok {
+		v.notifier = value
+	}
+	if value, ok := audit.AuditorFromContext(ctx); ok {
+		v.auditor = value
+	}
 
 	return nil
 }
@@ -54,6 +82,15 @@ func (v *DeleteManifest) Init(ctx context.Context) error {
 	} else {
 		return fmt.Errorf("missing provider %T.namespace", v)
 	}
+	if value, ok := accesspolicy.AccessControllerFromContext(ctx); ok {
+		v.access = value
+	}
+	if value, ok := notification.NotifierFromContext(ctx); ok {
+		v.notifier = value
+	}
+	if value, ok := audit.AuditorFromContext(ctx); ok {
+		v.auditor = value
+	}
 
 	return nil
 }
@@ -64,6 +101,12 @@ func (v *GetBlob) Init(ctx context.Context) error {
 	} else {
 		return fmt.Errorf("missing provider %T.namespace", v)
 	}
+	if value, ok := accesspolicy.AccessControllerFromContext(ctx); ok {
+		v.access = value
+	}
+	if value, ok := notification.NotifierFromContext(ctx); ok {
+		v.notifier = value
+	}
 
 	return nil
 }
@@ -74,6 +117,9 @@ func (v *GetBlobUpload) Init(ctx context.Context) error {
 	} else {
 		return fmt.Errorf("missing provider %T.namespace", v)
 	}
+	if value, ok := accesspolicy.AccessControllerFromContext(ctx); ok {
+		v.access = value
+	}
 
 	return nil
 }
@@ -84,6 +130,25 @@ func (v *GetManifest) Init(ctx context.Context) error {
 	} else {
 		return fmt.Errorf("missing provider %T.namespace", v)
 	}
+	if value, ok := accesspolicy.AccessControllerFromContext(ctx); ok {
+		v.access = value
+	}
+	if value, ok := notification.NotifierFromContext(ctx); ok {
+		v.notifier = value
+	}
+
+	return nil
+}
+
+func (v *GetReferrers) Init(ctx context.Context) error {
+	if value, ok := content.NamespaceFromContext(ctx); ok {
+		v.namespace = value
+	} else {
+		return fmt.Errorf("missing provider %T.namespace", v)
+	}
+	if value, ok := accesspolicy.AccessControllerFromContext(ctx); ok {
+		v.access = value
+	}
 
 	return nil
 }
@@ -94,6 +159,9 @@ func (v *HeadBlob) Init(ctx context.Context) error {
 	} else {
 		return fmt.Errorf("missing provider %T.namespace", v)
 	}
+	if value, ok := accesspolicy.AccessControllerFromContext(ctx); ok {
+		v.access = value
+	}
 
 	return nil
 }
@@ -104,6 +172,9 @@ func (v *HeadManifest) Init(ctx context.Context) error {
 	} else {
 		return fmt.Errorf("missing provider %T.namespace", v)
 	}
+	if value, ok := accesspolicy.AccessControllerFromContext(ctx); ok {
+		v.access = value
+	}
 
 	return nil
 }
@@ -114,6 +185,9 @@ func (v *ListTag) Init(ctx context.Context) error {
 	} else {
 		return fmt.Errorf("missing provider %T.namespace", v)
 	}
+	if value, ok := accesspolicy.AccessControllerFromContext(ctx); ok {
+		v.access = value
+	}
 
 	return nil
 }
@@ -124,6 +198,9 @@ func (v *PatchBlobUpload) Init(ctx context.Context) error {
 	} else {
 		return fmt.Errorf("missing provider %T.namespace", v)
 	}
+	if value, ok := accesspolicy.AccessControllerFromContext(ctx); ok {
+		v.access = value
+	}
 
 	return nil
 }
@@ -134,6 +211,15 @@ func (v *PutBlobUpload) Init(ctx context.Context) error {
 	} else {
 		return fmt.Errorf("missing provider %T.namespace", v)
 	}
+	if value, ok := accesspolicy.AccessControllerFromContext(ctx); ok {
+		v.access = value
+	}
+	if value, ok := notification.NotifierFromContext(ctx); ok {
+		v.notifier = value
+	}
+	if value, ok := audit.AuditorFromContext(ctx); ok {
+		v.auditor = value
+	}
 
 	return nil
 }
@@ -144,6 +230,18 @@ func (v *PutManifest) Init(ctx context.Context) error {
 	} else {
 		return fmt.Errorf("missing provider %T.namespace", v)
 	}
+	if value, ok := accesspolicy.AccessControllerFromContext(ctx); ok {
+		v.access = value
+	}
+	if value, ok := notification.NotifierFromContext(ctx); ok {
+		v.notifier = value
+	}
+	if value, ok := audit.AuditorFromContext(ctx); ok {
+		v.auditor = value
+	}
+	if value, ok := replication.ReplicatorFromContext(ctx); ok {
+		v.replicator = value
+	}
 
 	return nil
 }
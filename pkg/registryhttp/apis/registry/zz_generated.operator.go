@@ -118,6 +118,18 @@ func (GetManifest) ResponseData() *manifestv1.Payload {
 	return new(manifestv1.Payload)
 }
 
+func init() {
+	R.Register(courier.NewRouter(&GetReferrers{}))
+}
+
+func (GetReferrers) ResponseContent() any {
+	return new(manifestv1.OciIndex)
+}
+
+func (GetReferrers) ResponseData() *manifestv1.OciIndex {
+	return new(manifestv1.OciIndex)
+}
+
 func init() {
 	R.Register(courier.NewRouter(&HeadBlob{}))
 }
@@ -0,0 +1,107 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/octohelm/x/logr"
+
+	"github.com/octohelm/crkit/pkg/driver"
+)
+
+// +gengo:injectable:provider
+type AuditProvider struct {
+	// 启用审计日志，记录写入与删除操作，逐条写入存储的 audit 目录
+	AuditLog bool `flag:",omitzero"`
+	// 审计哈希链的 HMAC 密钥文件，启用审计日志时必填；密钥应与存储分开保管
+	AuditKeyFile string `flag:",omitzero"`
+
+	driver  driver.Driver `inject:",opt"`
+	auditor Auditor       `provide:""`
+}
+
+func (p *AuditProvider) afterInit(ctx context.Context) error {
+	if !p.AuditLog {
+		return nil
+	}
+
+	if p.driver == nil {
+		return errors.New("审计日志需要本地存储，不支持 NoCache 模式")
+	}
+
+	key, err := ReadKeyFile(p.AuditKeyFile)
+	if err != nil {
+		return err
+	}
+
+	a, err := NewAuditor(ctx, p.driver, DefaultDir, key)
+	if err != nil {
+		return fmt.Errorf("初始化审计日志失败: %w", err)
+	}
+
+	p.auditor = a
+
+	return nil
+}
+
+// ReadKeyFile 读取 HMAC 密钥，忽略首尾空白
+func ReadKeyFile(file string) ([]byte, error) {
+	if file == "" {
+		return nil, errors.New("审计日志需声明 HMAC 密钥文件")
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("读取审计密钥文件失败: %w", err)
+	}
+
+	key := bytes.TrimSpace(data)
+	if len(key) == 0 {
+		return nil, errors.New("审计密钥文件为空")
+	}
+
+	return key, nil
+}
+
+// Verifier 校验审计日志的哈希链，通过后输出链尾供外部保存
+// +gengo:injectable
+type Verifier struct {
+	// 审计哈希链的 HMAC 密钥文件
+	AuditKeyFile string `flag:",omitzero"`
+	// 此前导出的链尾 {seq}:{hash}，声明时校验日志未被截断或回退
+	AuditHead string `flag:",omitzero"`
+
+	driver driver.Driver `inject:",opt"`
+}
+
+func (v *Verifier) Run(ctx context.Context) error {
+	if v.driver == nil {
+		return nil
+	}
+
+	key, err := ReadKeyFile(v.AuditKeyFile)
+	if err != nil {
+		return err
+	}
+
+	var expected *Head
+	if v.AuditHead != "" {
+		expected, err = ParseHead(v.AuditHead)
+		if err != nil {
+			return err
+		}
+	}
+
+	head, err := Verify(ctx, v.driver, DefaultDir, key, expected)
+	if err != nil {
+		return err
+	}
+
+	logr.FromContext(ctx).WithValues(slog.String("head", head.String())).Info("audit log verified")
+
+	return nil
+}
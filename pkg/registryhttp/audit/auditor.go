@@ -0,0 +1,275 @@
+package audit
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/octohelm/crkit/pkg/driver"
+	"github.com/octohelm/crkit/pkg/registryhttp/notification"
+	"github.com/octohelm/crkit/pkg/registryhttp/tokenauth"
+)
+
+// DefaultDir 审计日志在存储中的目录
+//
+// 每条记录一个对象 {seq / 10000}/{seq}.json，序号补零使字典序即写入顺序；
+// 以条件写入创建，多个副本共享同一条链，且 S3 上无需整段重写
+const DefaultDir = "audit"
+
+const shardSize = 10000
+
+func recordPath(dir string, seq int64) string {
+	return path.Join(dir, fmt.Sprintf("%012d", seq/shardSize), fmt.Sprintf("%020d.json", seq))
+}
+
+// +gengo:injectable:provider
+type Auditor interface {
+	Audit(ctx context.Context, r *Record) error
+}
+
+// NewAuditor 从已有记录中恢复链尾，之后的记录以 key 计算哈希写入
+func NewAuditor(ctx context.Context, d driver.Driver, dir string, key []byte) (Auditor, error) {
+	if len(key) == 0 {
+		return nil, errors.New("audit key is required")
+	}
+
+	a := &auditor{driver: d, dir: dir, key: key}
+
+	last, err := lastRecord(ctx, d, dir)
+	if err != nil {
+		return nil, err
+	}
+	if last != nil {
+		a.seq = last.Seq
+		a.prevHash = last.Hash
+	}
+
+	return a, nil
+}
+
+type auditor struct {
+	driver driver.Driver
+	dir    string
+	key    []byte
+
+	mu       sync.Mutex
+	seq      int64
+	prevHash string
+}
+
+// Audit 补全身份与请求信息后计算哈希并写入，进程内串行化；
+// 序号已被其他副本占用时读取该记录接续链尾后重试
+func (a *auditor) Audit(ctx context.Context, r *Record) error {
+	if claims, ok := tokenauth.ClaimsFromContext(ctx); ok && r.Actor == "" {
+		r.Actor = claims.Subject
+	}
+
+	if req, ok := notification.RequestFromContext(ctx); ok {
+		// 请求头可被伪造，仅以连接的对端地址作为 ClientIP
+		if r.ClientIP == "" {
+			r.ClientIP = req.RemoteAddr
+		}
+		if r.ForwardedFor == "" {
+			r.ForwardedFor = req.ForwardedFor
+		}
+		if r.RequestID == "" {
+			r.RequestID = req.ID
+		}
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if r.Time.IsZero() {
+		r.Time = time.Now().UTC()
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		r.Seq = a.seq + 1
+		r.PrevHash = a.prevHash
+
+		hash, err := r.sum(a.key)
+		if err != nil {
+			return err
+		}
+		r.Hash = hash
+
+		data, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+
+		filename := recordPath(a.dir, r.Seq)
+
+		err = createContent(ctx, a.driver, filename, data)
+		if err == nil {
+			a.seq = r.Seq
+			a.prevHash = r.Hash
+			return nil
+		}
+
+		if !errors.Is(err, fs.ErrExist) {
+			return fmt.Errorf("write audit record failed: %w", err)
+		}
+
+		existing, err := readRecord(ctx, a.driver, filename)
+		if err != nil {
+			return fmt.Errorf("read audit record of other replica failed: %w", err)
+		}
+
+		a.seq = existing.Seq
+		a.prevHash = existing.Hash
+	}
+}
+
+// createContent 仅在对象不存在时写入；存储不支持条件写入时退化为检查后写入，仅适用于单副本
+func createContent(ctx context.Context, d driver.Driver, filename string, data []byte) error {
+	if w, ok := d.(driver.ExclusiveWriter); ok {
+		return w.PutContentIfAbsent(ctx, filename, data)
+	}
+
+	if _, err := d.Stat(ctx, filename); err == nil {
+		return &os.PathError{Op: "put", Path: filename, Err: os.ErrExist}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	return d.PutContent(ctx, filename, data)
+}
+
+// Verify 按序号顺序校验哈希链，返回第一处不一致，校验通过时返回链尾
+//
+// 声明 expected（此前导出的链尾）时，还校验链中该位置的记录未被改写且其后未被截断
+func Verify(ctx context.Context, d driver.Driver, dir string, key []byte, expected *Head) (*Head, error) {
+	shards, err := listEntries(ctx, d, dir, true)
+	if err != nil {
+		return nil, err
+	}
+
+	seq := int64(0)
+	prevHash := ""
+
+	for _, shard := range shards {
+		names, err := listEntries(ctx, d, path.Join(dir, shard), false)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range names {
+			filename := path.Join(dir, shard, name)
+
+			r, err := readRecord(ctx, d, filename)
+			if err != nil {
+				return nil, err
+			}
+
+			if r.Seq != seq+1 {
+				return nil, fmt.Errorf("%s: seq %d, expected %d", filename, r.Seq, seq+1)
+			}
+			if r.PrevHash != prevHash {
+				return nil, fmt.Errorf("%s: broken chain at seq %d", filename, r.Seq)
+			}
+
+			hash, err := r.sum(key)
+			if err != nil {
+				return nil, err
+			}
+			if !hmac.Equal([]byte(hash), []byte(r.Hash)) {
+				return nil, fmt.Errorf("%s: tampered record at seq %d", filename, r.Seq)
+			}
+			if expected != nil && r.Seq == expected.Seq && r.Hash != expected.Hash {
+				return nil, fmt.Errorf("%s: record at seq %d not match exported head", filename, r.Seq)
+			}
+
+			seq = r.Seq
+			prevHash = r.Hash
+		}
+	}
+
+	if expected != nil && seq < expected.Seq {
+		return nil, fmt.Errorf("audit log truncated, seq %d, exported head at seq %d", seq, expected.Seq)
+	}
+
+	return &Head{Seq: seq, Hash: prevHash}, nil
+}
+
+// listEntries 按名称顺序列出目录下的分片目录或记录文件
+func listEntries(ctx context.Context, d driver.Driver, dir string, dirs bool) ([]string, error) {
+	names := make([]string, 0)
+
+	err := d.WalkDir(ctx, dir, func(pathname string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if pathname == "." {
+			return nil
+		}
+		if entry.IsDir() {
+			if dirs {
+				names = append(names, entry.Name())
+			}
+			return fs.SkipDir
+		}
+		if !dirs && strings.HasSuffix(pathname, ".json") {
+			names = append(names, entry.Name())
+		}
+		return nil
+	})
+	if err != nil {
+		if perr, ok := errors.AsType[*os.PathError](err); ok && os.IsNotExist(perr) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// 名称补零，字典序即序号顺序
+	slices.Sort(names)
+
+	return names, nil
+}
+
+func lastRecord(ctx context.Context, d driver.Driver, dir string) (*Record, error) {
+	shards, err := listEntries(ctx, d, dir, true)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, shard := range slices.Backward(shards) {
+		names, err := listEntries(ctx, d, path.Join(dir, shard), false)
+		if err != nil {
+			return nil, err
+		}
+		if len(names) > 0 {
+			return readRecord(ctx, d, path.Join(dir, shard, names[len(names)-1]))
+		}
+	}
+
+	return nil, nil
+}
+
+func readRecord(ctx context.Context, d driver.Driver, filename string) (*Record, error) {
+	data, err := d.GetContent(ctx, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Record{}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("%s: invalid record: %w", filename, err)
+	}
+
+	return r, nil
+}
@@ -0,0 +1,110 @@
+package audit_test
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+
+	"github.com/octohelm/unifs/pkg/filesystem/local"
+	. "github.com/octohelm/x/testing/v2"
+
+	driverfs "github.com/octohelm/crkit/pkg/driver/fs"
+	"github.com/octohelm/crkit/pkg/registryhttp/audit"
+)
+
+func TestAuditor(t *testing.T) {
+	tmp := t.TempDir()
+	t.Cleanup(func() {
+		_ = os.RemoveAll(tmp)
+	})
+
+	d := driverfs.FromFileSystem(local.NewFS(tmp))
+
+	write := func(a audit.Auditor, at time.Time, tag string) error {
+		return a.Audit(t.Context(), &audit.Record{
+			Time:       at,
+			Action:     audit.ActionTagPut,
+			Repository: "test/app",
+			Reference:  tag,
+			NewDigest:  digest.FromString(tag),
+		})
+	}
+
+	day := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	key := []byte("secret")
+
+	a := MustValue(t, func() (audit.Auditor, error) {
+		return audit.NewAuditor(t.Context(), d, audit.DefaultDir, key)
+	})
+
+	Must(t, func() error { return write(a, day, "v1") })
+	Must(t, func() error { return write(a, day, "v2") })
+
+	t.Run("重新打开或多个副本时接续同一条哈希链", func(t *testing.T) {
+		replica := MustValue(t, func() (audit.Auditor, error) {
+			return audit.NewAuditor(t.Context(), d, audit.DefaultDir, key)
+		})
+
+		Must(t, func() error { return write(replica, day.AddDate(0, 0, 1), "v3") })
+		// a 的链尾已过期，序号冲突后接续 replica 写入的记录
+		Must(t, func() error { return write(a, day.AddDate(0, 0, 1), "v4") })
+
+		head := MustValue(t, func() (*audit.Head, error) {
+			return audit.Verify(t.Context(), d, audit.DefaultDir, key, nil)
+		})
+
+		Then(t, "校验通过",
+			Expect(head.Seq, Equal(int64(4))),
+			ExpectMustValue(func() (*audit.Head, error) {
+				return audit.Verify(t.Context(), d, audit.DefaultDir, key, head)
+			}, Equal(head)),
+		)
+
+		Then(t, "密钥不符时校验失败",
+			ExpectDo(
+				func() error {
+					_, err := audit.Verify(t.Context(), d, audit.DefaultDir, []byte("other"), nil)
+					return err
+				},
+				ErrorMatch(regexp.MustCompile("tampered record at seq 1")),
+			),
+		)
+
+		Then(t, "截断至导出的链尾之前时校验失败",
+			ExpectDo(
+				func() error {
+					_, err := audit.Verify(t.Context(), d, audit.DefaultDir, key, &audit.Head{Seq: 5, Hash: head.Hash})
+					return err
+				},
+				ErrorMatch(regexp.MustCompile("truncated")),
+			),
+		)
+	})
+
+	t.Run("篡改记录", func(t *testing.T) {
+		filename := path.Join(audit.DefaultDir, "000000000000", "00000000000000000001.json")
+
+		Must(t, func() error {
+			data, err := d.GetContent(t.Context(), filename)
+			if err != nil {
+				return err
+			}
+			return d.PutContent(t.Context(), filename, bytes.Replace(data, []byte(`"reference":"v1"`), []byte(`"reference":"v0"`), 1))
+		})
+
+		Then(t, "校验失败",
+			ExpectDo(
+				func() error {
+					_, err := audit.Verify(t.Context(), d, audit.DefaultDir, key, nil)
+					return err
+				},
+				ErrorMatch(regexp.MustCompile("tampered record at seq 1")),
+			),
+		)
+	})
+}
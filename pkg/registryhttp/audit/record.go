@@ -0,0 +1,84 @@
+package audit
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+)
+
+const (
+	ActionManifestPut    = "manifest.put"
+	ActionManifestDelete = "manifest.delete"
+	ActionTagPut         = "tag.put"
+	ActionTagDelete      = "tag.delete"
+	ActionBlobPut        = "blob.put"
+	ActionBlobMount      = "blob.mount"
+	ActionBlobDelete     = "blob.delete"
+)
+
+// Record 审计记录，每条存储为一个 JSON 对象
+//
+// Hash 为 PrevHash 与除 Hash 外各字段以密钥计算的 HMAC-SHA256，未持有密钥无法重算哈希，
+// 任一记录被篡改、删除或插入都会使之后的链校验失败；
+// ClientIP 为连接的对端地址（经反向代理时为代理地址）；请求头声明的 X-Forwarded-For 可被伪造，单独记录于 ForwardedFor
+type Record struct {
+	Seq          int64         `json:"seq"`
+	Time         time.Time     `json:"time"`
+	Action       string        `json:"action"`
+	Actor        string        `json:"actor,omitzero"`
+	Repository   string        `json:"repository"`
+	Reference    string        `json:"reference,omitzero"`
+	From         string        `json:"from,omitzero"`
+	OldDigest    digest.Digest `json:"oldDigest,omitzero"`
+	NewDigest    digest.Digest `json:"newDigest,omitzero"`
+	ClientIP     string        `json:"clientIP,omitzero"`
+	ForwardedFor string        `json:"forwardedFor,omitzero"`
+	RequestID    string        `json:"requestID,omitzero"`
+	PrevHash     string        `json:"prevHash"`
+	Hash         string        `json:"hash"`
+}
+
+func (r Record) sum(key []byte) (string, error) {
+	r.Hash = ""
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write(data)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Head 哈希链的链尾，导出保存后用于校验日志未被截断或整体回退
+type Head struct {
+	Seq  int64
+	Hash string
+}
+
+func (h Head) String() string {
+	return fmt.Sprintf("%d:%s", h.Seq, h.Hash)
+}
+
+// ParseHead 解析 {seq}:{hash} 格式的链尾
+func ParseHead(s string) (*Head, error) {
+	seq, hash, ok := strings.Cut(s, ":")
+	if !ok || hash == "" {
+		return nil, fmt.Errorf("invalid audit head %q, expect {seq}:{hash}", s)
+	}
+
+	n, err := strconv.ParseInt(seq, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid audit head %q: %w", s, err)
+	}
+
+	return &Head{Seq: n, Hash: hash}, nil
+}
@@ -0,0 +1,138 @@
+package clientip
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Resolver 解析请求的客户端地址
+//
+// 仅当连接的对端属于受信任的代理时才采信 X-Forwarded-For / X-Real-Ip，
+// 否则请求头可被客户端任意伪造，一律以连接的对端地址为准
+type Resolver struct {
+	proxies []*net.IPNet
+}
+
+// NewResolver trustedProxies 为受信任的代理地址或 CIDR
+func NewResolver(trustedProxies []string) (*Resolver, error) {
+	r := &Resolver{}
+
+	for _, p := range trustedProxies {
+		network, err := ParseNetwork(p)
+		if err != nil {
+			return nil, err
+		}
+		r.proxies = append(r.proxies, network)
+	}
+
+	return r, nil
+}
+
+// ParseNetwork 解析 IP 或 CIDR，单个 IP 视为 /32 或 /128
+func ParseNetwork(s string) (*net.IPNet, error) {
+	if !strings.Contains(s, "/") {
+		if strings.Contains(s, ":") {
+			s += "/128"
+		} else {
+			s += "/32"
+		}
+	}
+
+	_, network, err := net.ParseCIDR(s)
+	if err != nil {
+		return nil, err
+	}
+	return network, nil
+}
+
+func (r *Resolver) trusted(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+
+	return slices.ContainsFunc(r.proxies, func(n *net.IPNet) bool {
+		return n.Contains(parsed)
+	})
+}
+
+// ClientIP 对端为受信任的代理时，自右向左跳过受信任的代理，取 X-Forwarded-For 中首个不受信任的地址
+func (r *Resolver) ClientIP(req *http.Request) string {
+	ip := RemoteIP(req)
+
+	if r == nil || !r.trusted(ip) {
+		return ip
+	}
+
+	if forwardedFor := req.Header.Get("X-Forwarded-For"); forwardedFor != "" {
+		hops := strings.Split(forwardedFor, ",")
+
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if hop == "" {
+				continue
+			}
+			ip = hop
+			if !r.trusted(hop) {
+				break
+			}
+		}
+
+		return ip
+	}
+
+	if realIP := strings.TrimSpace(req.Header.Get("X-Real-Ip")); realIP != "" {
+		return realIP
+	}
+
+	return ip
+}
+
+// Handler 解析客户端地址并注入 context，供之后的限流、事件与审计使用；
+// 同时注入对端是否为受信任的代理，供采信 X-Forwarded-Proto / X-Forwarded-Host
+func (r *Resolver) Handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		ctx := InjectContext(req.Context(), r.ClientIP(req))
+		ctx = context.WithValue(ctx, contextTrustedProxy{}, r.trusted(RemoteIP(req)))
+
+		h.ServeHTTP(rw, req.WithContext(ctx))
+	})
+}
+
+// RemoteIP 连接的对端地址
+func RemoteIP(req *http.Request) string {
+	if host, _, err := net.SplitHostPort(req.RemoteAddr); err == nil {
+		return host
+	}
+	return req.RemoteAddr
+}
+
+// FromRequest 经 Handler 解析的客户端地址，未经解析时为连接的对端地址
+func FromRequest(req *http.Request) string {
+	if ip, ok := FromContext(req.Context()); ok {
+		return ip
+	}
+	return RemoteIP(req)
+}
+
+// FromTrustedProxy 请求的对端是否为受信任的代理，未经 Handler 解析时为 false
+func FromTrustedProxy(req *http.Request) bool {
+	trusted, _ := req.Context().Value(contextTrustedProxy{}).(bool)
+	return trusted
+}
+
+type contextTrustedProxy struct{}
+
+type contextClientIP struct{}
+
+func FromContext(ctx context.Context) (string, bool) {
+	ip, ok := ctx.Value(contextClientIP{}).(string)
+	return ip, ok
+}
+
+func InjectContext(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, contextClientIP{}, ip)
+}
@@ -0,0 +1,41 @@
+package clientip_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/octohelm/x/testing/v2"
+
+	"github.com/octohelm/crkit/pkg/registryhttp/clientip"
+)
+
+func TestResolver(t *testing.T) {
+	r := MustValue(t, func() (*clientip.Resolver, error) {
+		return clientip.NewResolver([]string{"10.0.0.0/8", "192.168.0.1"})
+	})
+
+	request := func(remoteAddr string, headers map[string]string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/v2/", nil)
+		req.RemoteAddr = remoteAddr
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		return req
+	}
+
+	Then(t, "不受信任的对端伪造的请求头被忽略",
+		Expect(r.ClientIP(request("203.0.113.1:1234", map[string]string{"X-Forwarded-For": "1.1.1.1"})), Equal("203.0.113.1")),
+		Expect(r.ClientIP(request("203.0.113.1:1234", map[string]string{"X-Real-Ip": "1.1.1.1"})), Equal("203.0.113.1")),
+	)
+
+	Then(t, "受信任的代理自右向左跳过受信任的地址",
+		Expect(r.ClientIP(request("10.0.0.1:1234", map[string]string{"X-Forwarded-For": "1.1.1.1, 203.0.113.9, 192.168.0.1"})), Equal("203.0.113.9")),
+		Expect(r.ClientIP(request("10.0.0.1:1234", map[string]string{"X-Real-Ip": "203.0.113.9"})), Equal("203.0.113.9")),
+		Expect(r.ClientIP(request("10.0.0.1:1234", nil)), Equal("10.0.0.1")),
+	)
+
+	Then(t, "未配置受信任的代理时为对端地址",
+		Expect((*clientip.Resolver)(nil).ClientIP(request("10.0.0.1:1234", map[string]string{"X-Forwarded-For": "1.1.1.1"})), Equal("10.0.0.1")),
+	)
+}
@@ -0,0 +1,2 @@
+//go:generate go tool gen .
+package notification
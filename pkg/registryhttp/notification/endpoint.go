@@ -0,0 +1,214 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gobwas/glob"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+
+	"github.com/octohelm/x/logr"
+
+	"github.com/octohelm/crkit/pkg/registryhttp/accesspolicy"
+)
+
+const maxBackoff = time.Minute
+
+// Endpoint 事件投递的 HTTP 端点
+type Endpoint struct {
+	// 名称，用于日志
+	Name string `json:"name,omitzero"`
+	// 接收事件的地址，以 POST 投递
+	URL string `json:"url"`
+	// 附加请求头，如 Authorization
+	Headers map[string]string `json:"headers,omitzero"`
+	// 事件格式 distribution / cloudevents，默认 distribution
+	Format string `json:"format,omitzero"`
+	// 单次投递超时，默认 5s
+	Timeout strfmt.Duration `json:"timeout,omitzero"`
+	// 失败重试次数，默认 3
+	Retries *int `json:"retries,omitzero"`
+	// 首次重试间隔，之后指数退避，默认 1s
+	Backoff strfmt.Duration `json:"backoff,omitzero"`
+	// 仓库名规则，未声明时不过滤
+	Repositories accesspolicy.Patterns `json:"repositories,omitzero"`
+	// 动作 push / pull / mount / delete，未声明时不过滤
+	Actions []string `json:"actions,omitzero"`
+}
+
+func (e *Endpoint) SetDefaults() {
+	if e.Name == "" {
+		e.Name = e.URL
+	}
+
+	if e.Format == "" {
+		e.Format = FormatDistribution
+	}
+
+	if e.Timeout == 0 {
+		e.Timeout = strfmt.Duration(5 * time.Second)
+	}
+
+	if e.Retries == nil {
+		retries := 3
+		e.Retries = &retries
+	}
+
+	if e.Backoff == 0 {
+		e.Backoff = strfmt.Duration(time.Second)
+	}
+}
+
+func newEndpoint(c *Endpoint, queueSize int) (*endpoint, error) {
+	c.SetDefaults()
+
+	if c.URL == "" {
+		return nil, fmt.Errorf("url of notification endpoint %q is required", c.Name)
+	}
+
+	if c.Format != FormatDistribution && c.Format != FormatCloudEvents {
+		return nil, fmt.Errorf("unsupported format %q of notification endpoint %q", c.Format, c.Name)
+	}
+
+	e := &endpoint{
+		Endpoint: *c,
+		queue:    make(chan *Event, queueSize),
+		client: &http.Client{
+			Timeout: time.Duration(c.Timeout),
+		},
+	}
+
+	if len(c.Repositories) > 0 {
+		repositories, err := c.Repositories.Compile()
+		if err != nil {
+			return nil, err
+		}
+		e.repositories = repositories
+	}
+
+	return e, nil
+}
+
+type endpoint struct {
+	Endpoint
+
+	repositories glob.Glob
+	queue        chan *Event
+	client       *http.Client
+}
+
+func (e *endpoint) matches(action string, name string) bool {
+	if len(e.Actions) > 0 && !slices.Contains(e.Actions, action) {
+		return false
+	}
+
+	if e.repositories != nil && !e.repositories.Match(name) {
+		return false
+	}
+
+	return true
+}
+
+// run 投递队列中的事件；ctx 结束后不再等待新事件，在 drainTimeout 内投递剩余事件，
+// 进行中的投递不随 ctx 立即取消
+func (e *endpoint) run(ctx context.Context, drainTimeout time.Duration) {
+	deliverCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancel()
+
+	stop := context.AfterFunc(ctx, func() {
+		time.AfterFunc(drainTimeout, cancel)
+	})
+	defer stop()
+
+	for {
+		select {
+		case evt := <-e.queue:
+			e.deliverOrLog(deliverCtx, evt)
+		case <-ctx.Done():
+			e.drain(deliverCtx)
+			return
+		}
+	}
+}
+
+func (e *endpoint) drain(ctx context.Context) {
+	dropped := 0
+
+	for {
+		select {
+		case evt := <-e.queue:
+			if ctx.Err() != nil {
+				dropped++
+				continue
+			}
+			e.deliverOrLog(ctx, evt)
+		default:
+			if dropped > 0 {
+				logr.FromContext(ctx).Warn(fmt.Errorf("notification of %s stopped, %d events dropped", e.Name, dropped))
+			}
+			return
+		}
+	}
+}
+
+func (e *endpoint) deliverOrLog(ctx context.Context, evt *Event) {
+	if err := e.deliver(ctx, evt); err != nil {
+		logr.FromContext(ctx).Error(fmt.Errorf("deliver %s event %s to %s failed: %w", evt.Action, evt.ID, e.Name, err))
+	}
+}
+
+// deliver 投递事件，失败时按指数退避重试
+func (e *endpoint) deliver(ctx context.Context, evt *Event) error {
+	body, contentType, err := encode(e.Format, evt)
+	if err != nil {
+		return err
+	}
+
+	backoff := time.Duration(e.Backoff)
+
+	for attempt := 0; ; attempt++ {
+		err := e.send(ctx, body, contentType)
+		if err == nil || attempt >= *e.Retries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+func (e *endpoint) send(ctx context.Context, body []byte, contentType string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	for k, v := range e.Headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opencontainers/go-digest"
+
+	"github.com/octohelm/crkit/pkg/registryhttp/tokenauth"
+)
+
+const (
+	ActionPush   = "push"
+	ActionPull   = "pull"
+	ActionMount  = "mount"
+	ActionDelete = "delete"
+)
+
+const (
+	// FormatDistribution distribution notification envelope
+	FormatDistribution = "distribution"
+	// FormatCloudEvents CloudEvents v1.0 structured mode
+	FormatCloudEvents = "cloudevents"
+)
+
+const (
+	MediaTypeEnvelope   = "application/vnd.docker.distribution.events.v1+json"
+	MediaTypeCloudEvent = "application/cloudevents+json"
+)
+
+// Event 注册中心事件，兼容 distribution notification 的事件结构
+type Event struct {
+	ID        string    `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+	Action    string    `json:"action"`
+	Target    Target    `json:"target"`
+	Request   Request   `json:"request,omitzero"`
+	Actor     Actor     `json:"actor,omitzero"`
+	Source    Source    `json:"source,omitzero"`
+}
+
+// Target 事件的对象，Manifest 或 Blob
+type Target struct {
+	MediaType      string        `json:"mediaType,omitzero"`
+	Digest         digest.Digest `json:"digest,omitzero"`
+	Size           int64         `json:"size,omitzero"`
+	Repository     string        `json:"repository"`
+	URL            string        `json:"url,omitzero"`
+	Tag            string        `json:"tag,omitzero"`
+	FromRepository string        `json:"fromRepository,omitzero"`
+}
+
+// Request 触发事件的请求
+type Request struct {
+	ID        string `json:"id,omitzero"`
+	Addr      string `json:"addr,omitzero"`
+	Host      string `json:"host,omitzero"`
+	Method    string `json:"method,omitzero"`
+	UserAgent string `json:"useragent,omitzero"`
+
+	// RemoteAddr 连接的对端地址，不受请求头影响
+	RemoteAddr string `json:"-"`
+	// ForwardedFor 请求声明的 X-Forwarded-For（或 X-Real-Ip），可被客户端伪造
+	ForwardedFor string `json:"-"`
+}
+
+// Actor 触发事件的用户
+type Actor struct {
+	Name string `json:"name,omitzero"`
+}
+
+// Source 产生事件的注册中心实例
+type Source struct {
+	Addr       string `json:"addr,omitzero"`
+	InstanceID string `json:"instanceID,omitzero"`
+}
+
+func newEvent(ctx context.Context, action string, target Target) *Event {
+	e := &Event{
+		ID:        uuid.New().String(),
+		Timestamp: time.Now().UTC(),
+		Action:    action,
+		Target:    target,
+	}
+
+	if r, ok := RequestFromContext(ctx); ok {
+		e.Request = *r
+	}
+
+	if claims, ok := tokenauth.ClaimsFromContext(ctx); ok {
+		e.Actor.Name = claims.Subject
+	}
+
+	return e
+}
+
+// encode 按格式编码事件，返回请求体与 Content-Type
+func encode(format string, e *Event) ([]byte, string, error) {
+	if format == FormatCloudEvents {
+		data, err := json.Marshal(&cloudEvent{
+			SpecVersion:     "1.0",
+			ID:              e.ID,
+			Source:          "crkit/" + e.Source.InstanceID,
+			Type:            "crkit.registry." + e.Action,
+			Subject:         e.Target.Repository,
+			Time:            e.Timestamp,
+			DataContentType: "application/json",
+			Data:            e,
+		})
+		return data, MediaTypeCloudEvent, err
+	}
+
+	data, err := json.Marshal(&envelope{Events: []*Event{e}})
+	return data, MediaTypeEnvelope, err
+}
+
+type envelope struct {
+	Events []*Event `json:"events"`
+}
+
+type cloudEvent struct {
+	SpecVersion     string    `json:"specversion"`
+	ID              string    `json:"id"`
+	Source          string    `json:"source"`
+	Type            string    `json:"type"`
+	Subject         string    `json:"subject,omitzero"`
+	Time            time.Time `json:"time"`
+	DataContentType string    `json:"datacontenttype"`
+	Data            *Event    `json:"data"`
+}
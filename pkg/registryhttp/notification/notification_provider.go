@@ -0,0 +1,76 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/innoai-tech/infra/pkg/agent"
+)
+
+// +gengo:injectable:provider
+type NotificationProvider struct {
+	// 事件通知配置文件（JSON），声明时启用 webhook 通知
+	NotificationConfigFile string `flag:",omitzero"`
+	// 每个端点的事件队列长度，默认 1000，队列已满时丢弃事件
+	NotificationQueueSize int `flag:",omitzero"`
+
+	notifier Notifier `provide:""`
+}
+
+func (p *NotificationProvider) afterInit(ctx context.Context) error {
+	if p.NotificationConfigFile == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(p.NotificationConfigFile)
+	if err != nil {
+		return fmt.Errorf("读取事件通知配置文件失败: %w", err)
+	}
+
+	c := &Config{}
+	if err := json.Unmarshal(data, c); err != nil {
+		return fmt.Errorf("解析事件通知配置文件失败: %w", err)
+	}
+
+	queueSize := p.NotificationQueueSize
+	if queueSize <= 0 {
+		queueSize = 1000
+	}
+
+	// 投递协程由 Dispatcher 随服务启停
+	n, err := newBroadcaster(c, queueSize)
+	if err != nil {
+		return fmt.Errorf("初始化事件通知失败: %w", err)
+	}
+
+	p.notifier = n
+
+	return nil
+}
+
+// +gengo:injectable
+type Dispatcher struct {
+	agent.Agent
+
+	notifier Notifier `inject:",opt"`
+}
+
+func (a *Dispatcher) Disabled(ctx context.Context) bool {
+	_, ok := a.notifier.(*broadcaster)
+	return !ok
+}
+
+func (a *Dispatcher) afterInit(ctx context.Context) error {
+	if a.Disabled(ctx) {
+		return nil
+	}
+
+	b := a.notifier.(*broadcaster)
+
+	// 停止时不再接收新事件，在 DrainTimeout 内投递队列中剩余的事件
+	a.Host("Notification", b.Run)
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/octohelm/x/logr"
+)
+
+// +gengo:injectable:provider
+type Notifier interface {
+	Notify(ctx context.Context, action string, target Target)
+}
+
+// Config 事件通知配置
+type Config struct {
+	Endpoints []Endpoint `json:"endpoints"`
+}
+
+// DrainTimeout 停止后投递队列中剩余事件的时限
+const DrainTimeout = 10 * time.Second
+
+// newBroadcaster 每个端点一个投递队列，投递协程由 Run 运行
+func newBroadcaster(c *Config, queueSize int) (*broadcaster, error) {
+	b := &broadcaster{
+		endpoints: make([]*endpoint, 0, len(c.Endpoints)),
+	}
+	b.source.InstanceID, _ = os.Hostname()
+
+	for i := range c.Endpoints {
+		e, err := newEndpoint(&c.Endpoints[i], queueSize)
+		if err != nil {
+			return nil, err
+		}
+		b.endpoints = append(b.endpoints, e)
+	}
+
+	return b, nil
+}
+
+type broadcaster struct {
+	source    Source
+	endpoints []*endpoint
+}
+
+// Run 运行各端点的投递协程，ctx 结束且剩余事件投递完成（或超出 DrainTimeout）后返回
+func (b *broadcaster) Run(ctx context.Context) error {
+	wg := sync.WaitGroup{}
+
+	for _, e := range b.endpoints {
+		wg.Go(func() {
+			e.run(ctx, DrainTimeout)
+		})
+	}
+
+	wg.Wait()
+
+	return nil
+}
+
+// Notify 事件投递到匹配的端点队列；队列已满时丢弃事件，不阻塞请求
+func (b *broadcaster) Notify(ctx context.Context, action string, target Target) {
+	var e *Event
+
+	for _, ep := range b.endpoints {
+		if !ep.matches(action, target.Repository) {
+			continue
+		}
+
+		if e == nil {
+			e = newEvent(ctx, action, target)
+			e.Source = b.source
+		}
+
+		select {
+		case ep.queue <- e:
+		default:
+			logr.FromContext(ctx).Warn(fmt.Errorf("notification queue of %s full, %s event of %s dropped", ep.Name, action, target.Repository))
+		}
+	}
+}
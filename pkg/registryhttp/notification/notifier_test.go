@@ -0,0 +1,116 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+
+	. "github.com/octohelm/x/testing/v2"
+
+	"github.com/octohelm/crkit/pkg/registryhttp/accesspolicy"
+)
+
+func TestNotifier(t *testing.T) {
+	attempts := atomic.Int32{}
+	received := make(chan map[string]any, 10)
+
+	svc := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		// 首次投递失败，验证重试
+		if attempts.Add(1) == 1 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		body := map[string]any{}
+		_ = json.NewDecoder(req.Body).Decode(&body)
+		body["contentType"] = req.Header.Get("Content-Type")
+		received <- body
+
+		rw.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(svc.Close)
+
+	n := MustValue(t, func() (*broadcaster, error) {
+		return newBroadcaster(&Config{
+			Endpoints: []Endpoint{
+				{
+					URL:          svc.URL,
+					Format:       FormatCloudEvents,
+					Backoff:      strfmt.Duration(10 * time.Millisecond),
+					Repositories: accesspolicy.Patterns{"library/**"},
+					Actions:      []string{ActionPush},
+				},
+			},
+		}, 10)
+	})
+
+	go func() {
+		_ = n.Run(t.Context())
+	}()
+
+	n.Notify(t.Context(), ActionPull, Target{Repository: "library/app"})
+	n.Notify(t.Context(), ActionPush, Target{Repository: "test/app"})
+	n.Notify(t.Context(), ActionPush, Target{Repository: "library/app", Tag: "latest"})
+
+	select {
+	case body := <-received:
+		Then(t, "仅投递匹配的事件，失败后重试",
+			Expect(body["contentType"], Equal[any](MediaTypeCloudEvent)),
+			Expect(body["type"], Equal[any]("crkit.registry.push")),
+			Expect(body["subject"], Equal[any]("library/app")),
+			Expect(attempts.Load(), Equal(int32(2))),
+		)
+	case <-time.After(5 * time.Second):
+		t.Fatal("event not delivered")
+	}
+}
+
+func TestNotifierDrain(t *testing.T) {
+	delivered := atomic.Int32{}
+	release := make(chan struct{})
+
+	svc := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		<-release
+		delivered.Add(1)
+		rw.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(svc.Close)
+
+	ctx, cancel := context.WithCancel(t.Context())
+
+	n := MustValue(t, func() (*broadcaster, error) {
+		return newBroadcaster(&Config{
+			Endpoints: []Endpoint{{URL: svc.URL}},
+		}, 10)
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = n.Run(ctx)
+	}()
+
+	for range 3 {
+		n.Notify(t.Context(), ActionPush, Target{Repository: "library/app"})
+	}
+
+	// 投递进行中时停止
+	cancel()
+	close(release)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("run not returned")
+	}
+
+	Then(t, "停止后投递完进行中与队列中剩余的事件再返回",
+		Expect(delivered.Load(), Equal(int32(3))),
+	)
+}
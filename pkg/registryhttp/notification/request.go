@@ -0,0 +1,54 @@
+package notification
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/octohelm/crkit/pkg/registryhttp/clientip"
+)
+
+type contextRequest struct{}
+
+func RequestFromContext(ctx context.Context) (*Request, bool) {
+	if r, ok := ctx.Value(contextRequest{}).(*Request); ok {
+		return r, true
+	}
+	return nil, false
+}
+
+func RequestInjectContext(ctx context.Context, r *Request) context.Context {
+	return context.WithValue(ctx, contextRequest{}, r)
+}
+
+// RecordRequest 记录请求信息，用于填充事件的 request
+//
+// 未携带 X-Request-Id 时生成新的请求 ID；Addr 为按受信任的代理解析的客户端地址
+func RecordRequest(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		id := req.Header.Get("X-Request-Id")
+		if id == "" {
+			id = uuid.New().String()
+		}
+
+		r := &Request{
+			ID:           id,
+			Addr:         clientip.FromRequest(req),
+			Host:         req.Host,
+			Method:       req.Method,
+			UserAgent:    req.UserAgent(),
+			RemoteAddr:   clientip.RemoteIP(req),
+			ForwardedFor: forwardedFor(req),
+		}
+
+		h.ServeHTTP(rw, req.WithContext(RequestInjectContext(req.Context(), r)))
+	})
+}
+
+func forwardedFor(req *http.Request) string {
+	if forwardedFor := req.Header.Get("X-Forwarded-For"); forwardedFor != "" {
+		return forwardedFor
+	}
+	return req.Header.Get("X-Real-Ip")
+}
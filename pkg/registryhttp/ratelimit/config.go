@@ -0,0 +1,147 @@
+package ratelimit
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gobwas/glob"
+
+	"github.com/octohelm/crkit/pkg/registryhttp/accesspolicy"
+	"github.com/octohelm/crkit/pkg/registryhttp/clientip"
+	"github.com/octohelm/crkit/pkg/registryhttp/tokenauth"
+)
+
+const (
+	ClassManifestRead = "manifestRead"
+	ClassBlobRead     = "blobRead"
+	ClassUpload       = "upload"
+	ClassToken        = "token"
+)
+
+// Config 限流配置
+type Config struct {
+	// 所有客户端共享的限制
+	Global Limits `json:"global,omitzero"`
+	// 每个客户端（token 用户名，未认证时为客户端 IP）的默认限制
+	Default Limits `json:"default,omitzero"`
+	// 按客户端覆盖默认限制，首个匹配的规则生效
+	Clients []ClientLimits `json:"clients,omitzero"`
+}
+
+// Limits 按操作分类的限制，未声明的分类不限制
+type Limits struct {
+	// 清单、标签、引用与目录的读取
+	ManifestRead *Limit `json:"manifestRead,omitzero"`
+	// Blob 的下载
+	BlobRead *Limit `json:"blobRead,omitzero"`
+	// Blob 上传会话的创建、分块与提交
+	Upload *Limit `json:"upload,omitzero"`
+	// token 服务的请求，每次请求均校验密码（bcrypt），用于限制密码猜测
+	Token *Limit `json:"token,omitzero"`
+}
+
+func (l Limits) of(class string) *Limit {
+	switch class {
+	case ClassManifestRead:
+		return l.ManifestRead
+	case ClassBlobRead:
+		return l.BlobRead
+	case ClassUpload:
+		return l.Upload
+	case ClassToken:
+		return l.Token
+	}
+	return nil
+}
+
+// Limit 令牌桶与并发上限，值为 0 时不限制
+type Limit struct {
+	// 每秒请求数
+	Rate float64 `json:"rate,omitzero"`
+	// 令牌桶容量，默认同 Rate
+	Burst int `json:"burst,omitzero"`
+	// 同时处理的请求数
+	MaxInFlight int `json:"maxInFlight,omitzero"`
+}
+
+// ClientLimits 命中 Subjects 或 IPs 的客户端使用 Limits
+type ClientLimits struct {
+	// 用户名规则
+	Subjects accesspolicy.Patterns `json:"subjects,omitzero"`
+	// 客户端 IP 或 CIDR
+	IPs []string `json:"ips,omitzero"`
+
+	Limits Limits `json:"limits"`
+}
+
+type clientMatcher struct {
+	subjects glob.Glob
+	networks []*net.IPNet
+	limits   Limits
+}
+
+func (c *ClientLimits) compile() (*clientMatcher, error) {
+	m := &clientMatcher{limits: c.Limits}
+
+	if len(c.Subjects) > 0 {
+		subjects, err := c.Subjects.Compile()
+		if err != nil {
+			return nil, err
+		}
+		m.subjects = subjects
+	}
+
+	for _, ip := range c.IPs {
+		network, err := clientip.ParseNetwork(ip)
+		if err != nil {
+			return nil, err
+		}
+		m.networks = append(m.networks, network)
+	}
+
+	return m, nil
+}
+
+func (m *clientMatcher) matches(subject string, ip net.IP) bool {
+	if subject != "" && m.subjects != nil && m.subjects.Match(subject) {
+		return true
+	}
+
+	if ip != nil {
+		for _, n := range m.networks {
+			if n.Contains(ip) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// classify 返回请求的操作分类，未分类的请求不限流
+func classify(req *http.Request) string {
+	p := req.URL.Path
+
+	if p == tokenauth.TokenPath {
+		return ClassToken
+	}
+
+	if !strings.HasPrefix(p, "/v2/") {
+		return ""
+	}
+
+	if strings.Contains(p, "/blobs/uploads") {
+		return ClassUpload
+	}
+
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return ""
+	}
+
+	if strings.Contains(p, "/blobs/") {
+		return ClassBlobRead
+	}
+
+	return ClassManifestRead
+}
@@ -0,0 +1,294 @@
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/octohelm/crkit/pkg/registryhttp/clientip"
+	"github.com/octohelm/crkit/pkg/registryhttp/tokenauth"
+)
+
+// idleTimeout 客户端限流状态闲置超过该时长后回收
+const idleTimeout = 10 * time.Minute
+
+// maxBuckets 限流状态的客户端数上限，超出后新的客户端共用同一限流状态，
+// 避免大量不同来源的客户端耗尽内存
+const maxBuckets = 10000
+
+// RateLimit 按客户端与操作分类限流
+//
+// 声明 RateLimitConfigFile 后启用：超出令牌桶或并发上限的请求返回 429 与 Retry-After
+type RateLimit struct {
+	// 限流配置文件（JSON），声明时启用限流
+	RateLimitConfigFile string `flag:",omitzero"`
+
+	global  *bucket
+	def     Limits
+	clients []*clientMatcher
+
+	mu       sync.Mutex
+	buckets  map[string]*bucket
+	overflow *bucket
+	sweptAt  time.Time
+}
+
+func (r *RateLimit) Init(ctx context.Context) error {
+	if r.RateLimitConfigFile == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(r.RateLimitConfigFile)
+	if err != nil {
+		return fmt.Errorf("读取限流配置文件失败: %w", err)
+	}
+
+	c := &Config{}
+	if err := json.Unmarshal(data, c); err != nil {
+		return fmt.Errorf("解析限流配置文件失败: %w", err)
+	}
+
+	return r.apply(c)
+}
+
+func (r *RateLimit) apply(c *Config) error {
+	clients := make([]*clientMatcher, 0, len(c.Clients))
+	for i := range c.Clients {
+		m, err := c.Clients[i].compile()
+		if err != nil {
+			return fmt.Errorf("编译限流规则失败: %w", err)
+		}
+		clients = append(clients, m)
+	}
+
+	r.global = newBucket(c.Global)
+	r.def = c.Default
+	r.clients = clients
+	r.buckets = map[string]*bucket{}
+	r.overflow = newBucket(c.Default)
+
+	return nil
+}
+
+func (r *RateLimit) Handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if r.global == nil {
+			h.ServeHTTP(rw, req)
+			return
+		}
+
+		class := classify(req)
+		if class == "" {
+			h.ServeHTTP(rw, req)
+			return
+		}
+
+		release, retryAfter, ok := r.acquire(req, class)
+		if !ok {
+			rw.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			writeTooManyRequests(rw, class)
+			return
+		}
+		defer release()
+
+		h.ServeHTTP(rw, req)
+	})
+}
+
+// acquire 依次占用客户端与全局的配额，全局配额不足时归还客户端的并发配额
+func (r *RateLimit) acquire(req *http.Request, class string) (func(), time.Duration, bool) {
+	now := time.Now()
+
+	releaseClient, retryAfter, ok := r.client(req, now).acquire(class, now)
+	if !ok {
+		return nil, retryAfter, false
+	}
+
+	releaseGlobal, retryAfter, ok := r.global.acquire(class, now)
+	if !ok {
+		releaseClient()
+		return nil, retryAfter, false
+	}
+
+	return func() {
+		releaseClient()
+		releaseGlobal()
+	}, 0, true
+}
+
+func (r *RateLimit) client(req *http.Request, now time.Time) *bucket {
+	subject := ""
+	if claims, ok := tokenauth.ClaimsFromContext(req.Context()); ok {
+		subject = claims.Subject
+	}
+
+	ip := clientip.FromRequest(req)
+
+	key := "ip:" + ip
+	if subject != "" {
+		key = "subject:" + subject
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if now.Sub(r.sweptAt) > time.Minute {
+		r.sweep(now)
+	}
+
+	b, ok := r.buckets[key]
+	if !ok {
+		if len(r.buckets) >= maxBuckets {
+			r.sweep(now)
+		}
+
+		if len(r.buckets) >= maxBuckets {
+			r.overflow.seenAt = now
+			return r.overflow
+		}
+
+		limits := r.def
+
+		parsedIP := net.ParseIP(ip)
+		for _, c := range r.clients {
+			if c.matches(subject, parsedIP) {
+				limits = c.limits
+				break
+			}
+		}
+
+		b = newBucket(limits)
+		r.buckets[key] = b
+	}
+	b.seenAt = now
+
+	return b
+}
+
+func (r *RateLimit) sweep(now time.Time) {
+	for k, b := range r.buckets {
+		if b.idle(now) {
+			delete(r.buckets, k)
+		}
+	}
+	r.sweptAt = now
+}
+
+func newBucket(limits Limits) *bucket {
+	b := &bucket{classes: map[string]*classBucket{}}
+
+	for _, class := range []string{ClassManifestRead, ClassBlobRead, ClassUpload, ClassToken} {
+		l := limits.of(class)
+		if l == nil {
+			continue
+		}
+
+		cb := &classBucket{maxInFlight: l.MaxInFlight}
+		if l.Rate > 0 {
+			burst := l.Burst
+			if burst <= 0 {
+				burst = max(int(math.Ceil(l.Rate)), 1)
+			}
+			cb.limiter = rate.NewLimiter(rate.Limit(l.Rate), burst)
+		}
+		b.classes[class] = cb
+	}
+
+	return b
+}
+
+type bucket struct {
+	classes map[string]*classBucket
+	seenAt  time.Time
+}
+
+func (b *bucket) idle(now time.Time) bool {
+	if now.Sub(b.seenAt) < idleTimeout {
+		return false
+	}
+	for _, cb := range b.classes {
+		if cb.inFlight() > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *bucket) acquire(class string, now time.Time) (func(), time.Duration, bool) {
+	cb, ok := b.classes[class]
+	if !ok {
+		return func() {}, 0, true
+	}
+	return cb.acquire(now)
+}
+
+type classBucket struct {
+	limiter     *rate.Limiter
+	maxInFlight int
+
+	mu      sync.Mutex
+	current int
+}
+
+func (cb *classBucket) inFlight() int {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.current
+}
+
+func (cb *classBucket) acquire(now time.Time) (func(), time.Duration, bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.maxInFlight > 0 && cb.current >= cb.maxInFlight {
+		return nil, time.Second, false
+	}
+
+	if cb.limiter != nil {
+		reservation := cb.limiter.ReserveN(now, 1)
+		if !reservation.OK() {
+			return nil, time.Second, false
+		}
+		if delay := reservation.DelayFrom(now); delay > 0 {
+			reservation.CancelAt(now)
+			return nil, delay, false
+		}
+	}
+
+	cb.current++
+
+	released := false
+
+	return func() {
+		cb.mu.Lock()
+		defer cb.mu.Unlock()
+
+		if !released {
+			released = true
+			cb.current--
+		}
+	}, 0, true
+}
+
+func writeTooManyRequests(rw http.ResponseWriter, class string) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusTooManyRequests)
+
+	_ = json.NewEncoder(rw).Encode(map[string]any{
+		"errors": []map[string]string{
+			{
+				"code":    "TOOMANYREQUESTS",
+				"message": fmt.Sprintf("too many %s requests", class),
+			},
+		},
+	})
+}
@@ -0,0 +1,117 @@
+package ratelimit_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/octohelm/x/testing/v2"
+
+	"github.com/octohelm/crkit/pkg/registryhttp/ratelimit"
+	"github.com/octohelm/crkit/pkg/registryhttp/tokenauth"
+)
+
+func TestRateLimit(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "ratelimit.json")
+
+	Must(t, func() error {
+		return os.WriteFile(configFile, []byte(`{
+  "default": {
+    "manifestRead": { "rate": 1, "burst": 2 },
+    "upload": { "maxInFlight": 1 },
+    "token": { "rate": 1, "burst": 1 }
+  },
+  "clients": [
+    { "ips": ["10.0.0.0/8"], "limits": {} }
+  ]
+}`), 0o644)
+	})
+
+	r := &ratelimit.RateLimit{RateLimitConfigFile: configFile}
+	Must(t, func() error {
+		return r.Init(t.Context())
+	})
+
+	block := make(chan struct{})
+	entered := make(chan struct{}, 1)
+
+	h := r.Handler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPatch {
+			entered <- struct{}{}
+			<-block
+		}
+		rw.WriteHeader(http.StatusOK)
+	}))
+
+	do := func(method string, path string, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.RemoteAddr = remoteAddr
+		rw := httptest.NewRecorder()
+		h.ServeHTTP(rw, req)
+		return rw
+	}
+
+	t.Run("令牌桶耗尽后返回 429", func(t *testing.T) {
+		codes := make([]int, 0, 3)
+		for range 3 {
+			codes = append(codes, do(http.MethodGet, "/v2/library/app/manifests/latest", "192.168.0.1:1234").Code)
+		}
+
+		rw := do(http.MethodGet, "/v2/library/app/manifests/latest", "192.168.0.1:1234")
+
+		Then(t, "超出 burst 的请求被拒绝",
+			Expect(codes, Equal([]int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests})),
+			Expect(rw.Header().Get("Retry-After"), Equal("1")),
+		)
+
+		spoofed := httptest.NewRequest(http.MethodGet, "/v2/library/app/manifests/latest", nil)
+		spoofed.RemoteAddr = "192.168.0.1:1234"
+		spoofed.Header.Set("X-Forwarded-For", "192.168.0.9")
+		spoofedRw := httptest.NewRecorder()
+		h.ServeHTTP(spoofedRw, spoofed)
+
+		Then(t, "伪造 X-Forwarded-For 不能绕过限流",
+			Expect(spoofedRw.Code, Equal(http.StatusTooManyRequests)),
+		)
+
+		Then(t, "Blob 下载与其他客户端不受影响",
+			Expect(do(http.MethodGet, "/v2/library/app/blobs/sha256:x", "192.168.0.1:1234").Code, Equal(http.StatusOK)),
+			Expect(do(http.MethodGet, "/v2/library/app/manifests/latest", "192.168.0.2:1234").Code, Equal(http.StatusOK)),
+		)
+
+		Then(t, "匹配规则的客户端使用覆盖的限制",
+			Expect(do(http.MethodGet, "/v2/library/app/manifests/latest", "10.0.0.1:1234").Code, Equal(http.StatusOK)),
+			Expect(do(http.MethodGet, "/v2/library/app/manifests/latest", "10.0.0.1:1234").Code, Equal(http.StatusOK)),
+			Expect(do(http.MethodGet, "/v2/library/app/manifests/latest", "10.0.0.1:1234").Code, Equal(http.StatusOK)),
+		)
+	})
+
+	t.Run("token 服务按客户端 IP 限流", func(t *testing.T) {
+		Then(t, "超出 burst 的换取请求被拒绝",
+			Expect(do(http.MethodGet, tokenauth.TokenPath, "192.168.0.4:1234").Code, Equal(http.StatusOK)),
+			Expect(do(http.MethodGet, tokenauth.TokenPath, "192.168.0.4:1234").Code, Equal(http.StatusTooManyRequests)),
+		)
+	})
+
+	t.Run("上传并发达到上限", func(t *testing.T) {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			do(http.MethodPatch, "/v2/library/app/blobs/uploads/1", "192.168.0.3:1234")
+		}()
+		<-entered
+
+		rejected := do(http.MethodPatch, "/v2/library/app/blobs/uploads/2", "192.168.0.3:1234")
+		manifest := do(http.MethodGet, "/v2/library/app/manifests/latest", "192.168.0.3:1234")
+
+		close(block)
+		<-done
+
+		Then(t, "仅拒绝上传，清单读取不受影响",
+			Expect(rejected.Code, Equal(http.StatusTooManyRequests)),
+			Expect(manifest.Code, Equal(http.StatusOK)),
+		)
+	})
+}
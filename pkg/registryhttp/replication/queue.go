@@ -0,0 +1,105 @@
+package replication
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+
+	"github.com/octohelm/crkit/pkg/driver"
+)
+
+// task 待复制的清单
+type task struct {
+	ID         string        `json:"id"`
+	Repository string        `json:"repository"`
+	Digest     digest.Digest `json:"digest"`
+	Tag        string        `json:"tag,omitzero"`
+	CreatedAt  time.Time     `json:"createdAt"`
+
+	Attempts      int       `json:"attempts,omitzero"`
+	NextAttemptAt time.Time `json:"nextAttemptAt,omitzero"`
+	LastError     string    `json:"lastError,omitzero"`
+}
+
+// taskID 同一仓库同一清单同一标签的任务只保留一个
+func taskID(repository string, dgst digest.Digest, tag string) string {
+	return digest.FromString(repository + "@" + dgst.String() + ":" + tag).Hex()[:32]
+}
+
+// queue 任务以 JSON 文件保存于存储的 dir 下，服务重启后继续复制
+//
+// {dir}/{id}.json
+type queue struct {
+	driver driver.Driver
+	dir    string
+}
+
+func (q *queue) path(id string) string {
+	return path.Join(q.dir, id+".json")
+}
+
+func (q *queue) put(ctx context.Context, t *task) error {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	return q.driver.PutContent(ctx, q.path(t.ID), data)
+}
+
+func (q *queue) remove(ctx context.Context, id string) error {
+	if err := q.driver.Delete(ctx, q.path(id)); err != nil {
+		if perr, ok := errors.AsType[*os.PathError](err); ok && os.IsNotExist(perr) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// list 按创建时间排序返回所有任务
+func (q *queue) list(ctx context.Context) ([]*task, error) {
+	tasks := make([]*task, 0)
+
+	err := q.driver.WalkDir(ctx, q.dir, func(pathname string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if pathname == "." || d.IsDir() || !strings.HasSuffix(pathname, ".json") {
+			return nil
+		}
+
+		data, err := q.driver.GetContent(ctx, path.Join(q.dir, pathname))
+		if err != nil {
+			return err
+		}
+
+		t := &task{}
+		if err := json.Unmarshal(data, t); err != nil {
+			return err
+		}
+		tasks = append(tasks, t)
+
+		return nil
+	})
+	if err != nil {
+		if perr, ok := errors.AsType[*os.PathError](err); ok && os.IsNotExist(perr) {
+			return tasks, nil
+		}
+		return nil, err
+	}
+
+	slices.SortStableFunc(tasks, func(a, b *task) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	return tasks, nil
+}
@@ -0,0 +1,75 @@
+package replication
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/innoai-tech/infra/pkg/agent"
+
+	"github.com/octohelm/crkit/pkg/content"
+	"github.com/octohelm/crkit/pkg/driver"
+)
+
+// +gengo:injectable:provider
+type ReplicationProvider struct {
+	// 推送复制配置文件（JSON），声明时清单推送成功后复制到下游注册表
+	ReplicationConfigFile string `flag:",omitzero"`
+
+	driver     driver.Driver     `inject:",opt"`
+	namespace  content.Namespace `inject:",opt"`
+	replicator Replicator        `provide:""`
+}
+
+func (p *ReplicationProvider) afterInit(ctx context.Context) error {
+	if p.ReplicationConfigFile == "" {
+		return nil
+	}
+
+	if p.driver == nil || p.namespace == nil {
+		return errors.New("推送复制需要本地存储，不支持 NoCache 模式")
+	}
+
+	data, err := os.ReadFile(p.ReplicationConfigFile)
+	if err != nil {
+		return fmt.Errorf("读取推送复制配置文件失败: %w", err)
+	}
+
+	c := &Config{}
+	if err := json.Unmarshal(data, c); err != nil {
+		return fmt.Errorf("解析推送复制配置文件失败: %w", err)
+	}
+
+	// 复制协程由 Worker 随服务启停
+	r, err := NewReplicator(ctx, c, p.driver, DefaultDir, p.namespace)
+	if err != nil {
+		return fmt.Errorf("初始化推送复制失败: %w", err)
+	}
+
+	p.replicator = r
+
+	return nil
+}
+
+// +gengo:injectable
+type Worker struct {
+	agent.Agent
+
+	replicator Replicator `inject:",opt"`
+}
+
+func (a *Worker) Disabled(ctx context.Context) bool {
+	return a.replicator == nil
+}
+
+func (a *Worker) afterInit(ctx context.Context) error {
+	if a.Disabled(ctx) {
+		return nil
+	}
+
+	a.Host("Replication", a.replicator.Run)
+
+	return nil
+}
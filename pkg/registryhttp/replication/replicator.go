@@ -0,0 +1,140 @@
+package replication
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+
+	"github.com/octohelm/x/logr"
+
+	"github.com/octohelm/crkit/pkg/content"
+	"github.com/octohelm/crkit/pkg/driver"
+)
+
+// DefaultDir 复制队列在存储中的位置
+const DefaultDir = "replication"
+
+// +gengo:injectable:provider
+type Replicator interface {
+	// Replicate 清单推送成功后调用，任务写入各匹配目标的持久化队列，由后台协程复制
+	Replicate(ctx context.Context, repository string, dgst digest.Digest, tag string)
+	// Statuses 各目标的复制状态
+	Statuses(ctx context.Context) ([]Status, error)
+	// Run 运行各目标的复制协程，ctx 结束且进行中的复制退出后返回
+	Run(ctx context.Context) error
+}
+
+// Config 推送复制配置
+type Config struct {
+	Targets []Target `json:"targets"`
+}
+
+// Status 目标的复制状态
+type Status struct {
+	Target string `json:"target"`
+	// 队列中待复制的任务数
+	Pending int `json:"pending"`
+	// 其中复制失败等待重试的任务数
+	Retrying int `json:"retrying"`
+	// 最早的待复制任务的创建时间，用于衡量复制延迟
+	OldestPendingAt time.Time `json:"oldestPendingAt,omitzero"`
+	// 最近一次复制成功的时间
+	LastSuccessAt time.Time `json:"lastSuccessAt,omitzero"`
+	// 最近一次复制失败的时间与原因
+	LastErrorAt time.Time `json:"lastErrorAt,omitzero"`
+	LastError   string    `json:"lastError,omitzero"`
+}
+
+// NewReplicator 每个目标一个复制队列，复制协程由 Run 运行
+//
+// 任务保存于存储的 {dir}/{target}，从 source 读取清单与 Blob
+func NewReplicator(ctx context.Context, c *Config, d driver.Driver, dir string, source content.Namespace) (Replicator, error) {
+	if underlying, ok := source.(content.PersistNamespaceWrapper); ok {
+		source = underlying.UnwarpPersistNamespace()
+	}
+
+	r := &replicator{
+		targets: make([]*target, 0, len(c.Targets)),
+	}
+
+	names := map[string]bool{}
+
+	for i := range c.Targets {
+		c.Targets[i].SetDefaults()
+
+		name := c.Targets[i].Name
+		if names[name] {
+			return nil, fmt.Errorf("duplicate replication target %q", name)
+		}
+		names[name] = true
+
+		t, err := newTarget(ctx, &c.Targets[i], &queue{driver: d, dir: path.Join(dir, name)}, source)
+		if err != nil {
+			return nil, err
+		}
+		r.targets = append(r.targets, t)
+	}
+
+	return r, nil
+}
+
+type replicator struct {
+	targets []*target
+}
+
+// Run 每个目标一个复制协程，ctx 结束时等待进行中的复制退出；未完成的任务保留在队列中，重启后继续
+func (r *replicator) Run(ctx context.Context) error {
+	wg := sync.WaitGroup{}
+
+	for _, t := range r.targets {
+		wg.Go(func() {
+			t.run(ctx)
+		})
+	}
+
+	wg.Wait()
+
+	return nil
+}
+
+// Replicate 写入队列失败仅记录日志，不影响推送
+func (r *replicator) Replicate(ctx context.Context, repository string, dgst digest.Digest, tag string) {
+	for _, t := range r.targets {
+		if !t.matches(repository) {
+			continue
+		}
+
+		tk := &task{
+			ID:         taskID(repository, dgst, tag),
+			Repository: repository,
+			Digest:     dgst,
+			Tag:        tag,
+			CreatedAt:  time.Now(),
+		}
+
+		if err := t.queue.put(ctx, tk); err != nil {
+			logr.FromContext(ctx).Error(fmt.Errorf("enqueue replication of %s@%s to %s failed: %w", repository, dgst, t.Name, err))
+			continue
+		}
+
+		t.notify()
+	}
+}
+
+func (r *replicator) Statuses(ctx context.Context) ([]Status, error) {
+	statuses := make([]Status, 0, len(r.targets))
+
+	for _, t := range r.targets {
+		s, err := t.status(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("read replication status of %s failed: %w", t.Name, err)
+		}
+		statuses = append(statuses, *s)
+	}
+
+	return statuses, nil
+}
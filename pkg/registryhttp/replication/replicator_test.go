@@ -0,0 +1,176 @@
+package replication_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/distribution/reference"
+	"github.com/opencontainers/go-digest"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+
+	"github.com/octohelm/unifs/pkg/filesystem/local"
+	"github.com/octohelm/unifs/pkg/units"
+	. "github.com/octohelm/x/testing/v2"
+
+	"github.com/octohelm/crkit/pkg/content"
+	contentfs "github.com/octohelm/crkit/pkg/content/fs"
+	contentremote "github.com/octohelm/crkit/pkg/content/remote"
+	contenttestutil "github.com/octohelm/crkit/pkg/content/testutil"
+	driverfs "github.com/octohelm/crkit/pkg/driver/fs"
+	"github.com/octohelm/crkit/pkg/oci"
+	"github.com/octohelm/crkit/pkg/oci/random"
+	ociremote "github.com/octohelm/crkit/pkg/oci/remote"
+	"github.com/octohelm/crkit/pkg/registryhttp/accesspolicy"
+	"github.com/octohelm/crkit/pkg/registryhttp/replication"
+)
+
+func TestReplicator(t *testing.T) {
+	ctx := t.Context()
+
+	// 下游初始不可用，验证任务保留在队列中并重试
+	up := atomic.Bool{}
+	registry := contenttestutil.NewRegistry(t)
+
+	downstream := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if !up.Load() {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		registry.ServeHTTP(rw, req)
+	}))
+	t.Cleanup(downstream.Close)
+
+	d := driverfs.FromFileSystem(local.NewFS(t.TempDir()))
+	source := contentfs.NewNamespace(d)
+
+	sourceRepo := MustValue(t, func() (content.Repository, error) {
+		named, err := reference.WithName("team-a/app")
+		if err != nil {
+			return nil, err
+		}
+		return source.Repository(ctx, named)
+	})
+
+	img := MustValue(t, func() (oci.Image, error) {
+		return random.Image(int64(10*units.KiB), 1)
+	})
+
+	Must(t, func() error {
+		return ociremote.Push(ctx, img, sourceRepo, "v1")
+	})
+
+	dgst := MustValue(t, func() (digest.Digest, error) {
+		d, err := img.Descriptor(ctx)
+		if err != nil {
+			return "", err
+		}
+		return d.Digest, nil
+	})
+
+	r := MustValue(t, func() (replication.Replicator, error) {
+		return replication.NewReplicator(ctx, &replication.Config{
+			Targets: []replication.Target{
+				{
+					Name:         "downstream",
+					Registry:     contentremote.RegistryHost{Server: downstream.URL},
+					Repositories: accesspolicy.Patterns{"team-a/**"},
+					Rewrites: []replication.Rewrite{
+						{From: "team-a", To: "mirror/team-a"},
+					},
+					Backoff: strfmt.Duration(10 * time.Millisecond),
+				},
+			},
+		}, d, replication.DefaultDir, source)
+	})
+
+	go func() {
+		_ = r.Run(ctx)
+	}()
+
+	r.Replicate(ctx, "team-a/app", dgst, "v1")
+	r.Replicate(ctx, "team-b/app", dgst, "v1")
+
+	waitFor := func(t *testing.T, check func(s replication.Status) bool) replication.Status {
+		deadline := time.Now().Add(10 * time.Second)
+
+		for time.Now().Before(deadline) {
+			statuses := MustValue(t, func() ([]replication.Status, error) {
+				return r.Statuses(ctx)
+			})
+			if check(statuses[0]) {
+				return statuses[0]
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		t.Fatal("replication status not reached")
+		return replication.Status{}
+	}
+
+	t.Run("下游不可用时任务保留并重试", func(t *testing.T) {
+		s := waitFor(t, func(s replication.Status) bool {
+			return s.Retrying > 0
+		})
+
+		Then(t, "仅匹配的仓库入队，状态可见延迟",
+			Expect(s.Target, Equal("downstream")),
+			Expect(s.Pending, Equal(1)),
+			Expect(s.OldestPendingAt.IsZero(), Equal(false)),
+			Expect(s.LastError != "", Equal(true)),
+		)
+	})
+
+	t.Run("下游恢复后复制到映射后的仓库", func(t *testing.T) {
+		up.Store(true)
+
+		s := waitFor(t, func(s replication.Status) bool {
+			return s.Pending == 0
+		})
+
+		Then(t, "队列清空",
+			Expect(s.LastSuccessAt.IsZero(), Equal(false)),
+		)
+
+		Then(t, "标签指向同一清单",
+			ExpectMustValue(func() (digest.Digest, error) {
+				u, err := url.Parse(downstream.URL)
+				if err != nil {
+					return "", err
+				}
+
+				remote, err := contentremote.New(ctx, contentremote.RegistryHosts{
+					u.Host: {Server: downstream.URL},
+				})
+				if err != nil {
+					return "", err
+				}
+
+				named, err := reference.WithName(u.Host + "/mirror/team-a/app")
+				if err != nil {
+					return "", err
+				}
+
+				repo, err := remote.Repository(ctx, named)
+				if err != nil {
+					return "", err
+				}
+
+				tags, err := repo.Tags(ctx)
+				if err != nil {
+					return "", err
+				}
+
+				d, err := tags.Get(ctx, "v1")
+				if err != nil {
+					return "", err
+				}
+
+				return d.Digest, nil
+			}, Equal(dgst)),
+		)
+	})
+}
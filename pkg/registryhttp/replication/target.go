@@ -0,0 +1,325 @@
+package replication
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/distribution/reference"
+	"github.com/gobwas/glob"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+
+	"github.com/octohelm/x/logr"
+
+	"github.com/octohelm/crkit/pkg/apis/registry/v2"
+	"github.com/octohelm/crkit/pkg/content"
+	contentremote "github.com/octohelm/crkit/pkg/content/remote"
+	ociremote "github.com/octohelm/crkit/pkg/oci/remote"
+	"github.com/octohelm/crkit/pkg/registryhttp/accesspolicy"
+)
+
+const (
+	maxBackoff = 5 * time.Minute
+	// 无到期任务时重新扫描队列的间隔
+	idleInterval = time.Minute
+)
+
+// Target 复制的下游注册表
+type Target struct {
+	// 名称，用于状态、日志与队列目录，默认取 registry.server 的主机名
+	Name string `json:"name,omitzero"`
+	// 下游注册表的地址与凭证
+	Registry contentremote.RegistryHost `json:"registry"`
+	// 仓库名规则，未声明时复制所有仓库
+	Repositories accesspolicy.Patterns `json:"repositories,omitzero"`
+	// 仓库名映射，按顺序使用首个匹配的规则，未匹配时保持原名
+	Rewrites []Rewrite `json:"rewrites,omitzero"`
+	// 首次重试间隔，之后指数退避至 5m，默认 1s
+	Backoff strfmt.Duration `json:"backoff,omitzero"`
+}
+
+// Rewrite 将仓库名前缀 From 替换为 To
+//
+// 前缀 team-a 匹配仓库 team-a 与 team-a/*
+type Rewrite struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+func (r *Rewrite) rewrite(name string) (string, bool) {
+	if name == r.From {
+		return r.To, true
+	}
+	if rest, ok := strings.CutPrefix(name, r.From+"/"); ok {
+		return path.Join(r.To, rest), true
+	}
+	return name, false
+}
+
+func (t *Target) SetDefaults() {
+	if t.Name == "" {
+		t.Name = t.Registry.Server
+		if i := strings.Index(t.Name, "://"); i >= 0 {
+			t.Name = t.Name[i+3:]
+		}
+	}
+
+	if t.Backoff == 0 {
+		t.Backoff = strfmt.Duration(time.Second)
+	}
+}
+
+func newTarget(ctx context.Context, c *Target, q *queue, source content.Namespace) (*target, error) {
+	c.SetDefaults()
+
+	if c.Registry.Server == "" {
+		return nil, fmt.Errorf("registry.server of replication target %q is required", c.Name)
+	}
+
+	remote, err := contentremote.New(ctx, &hostResolver{host: c.Registry})
+	if err != nil {
+		return nil, err
+	}
+
+	t := &target{
+		Target: *c,
+		queue:  q,
+		source: source,
+		remote: remote,
+		wake:   make(chan struct{}, 1),
+	}
+
+	if len(c.Repositories) > 0 {
+		repositories, err := c.Repositories.Compile()
+		if err != nil {
+			return nil, err
+		}
+		t.repositories = repositories
+	}
+
+	return t, nil
+}
+
+type target struct {
+	Target
+
+	repositories glob.Glob
+	queue        *queue
+	source       content.Namespace
+	remote       content.Namespace
+	wake         chan struct{}
+
+	mu            sync.Mutex
+	lastSuccessAt time.Time
+	lastErrorAt   time.Time
+	lastError     string
+}
+
+func (t *target) matches(name string) bool {
+	return t.repositories == nil || t.repositories.Match(name)
+}
+
+func (t *target) rewrite(name string) string {
+	for _, r := range t.Rewrites {
+		if rewritten, ok := r.rewrite(name); ok {
+			return rewritten
+		}
+	}
+	return name
+}
+
+func (t *target) notify() {
+	select {
+	case t.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (t *target) run(ctx context.Context) {
+	for {
+		wait := t.process(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.wake:
+		case <-time.After(wait):
+		}
+	}
+}
+
+// process 依次复制到期的任务，返回距下一个任务到期的时间
+func (t *target) process(ctx context.Context) time.Duration {
+	l := logr.FromContext(ctx).WithValues(slog.String("target", t.Name))
+
+	tasks, err := t.queue.list(ctx)
+	if err != nil {
+		l.Error(fmt.Errorf("list replication queue failed: %w", err))
+		return time.Duration(t.Backoff)
+	}
+
+	wait := idleInterval
+
+	for _, tk := range tasks {
+		if ctx.Err() != nil {
+			return wait
+		}
+
+		now := time.Now()
+
+		if tk.NextAttemptAt.After(now) {
+			wait = min(wait, tk.NextAttemptAt.Sub(now))
+			continue
+		}
+
+		err := t.replicate(ctx, tk)
+		if err == nil {
+			if err := t.queue.remove(ctx, tk.ID); err != nil {
+				l.Error(fmt.Errorf("remove replication task failed: %w", err))
+			}
+
+			t.succeeded(time.Now())
+
+			l.WithValues(
+				slog.String("repository", tk.Repository),
+				slog.String("digest", string(tk.Digest)),
+				slog.String("tag", tk.Tag),
+			).Info("replicated")
+			continue
+		}
+
+		// 清单已被删除时无需再复制
+		if _, ok := errors.AsType[*v2.ErrManifestUnknownRevision](err); ok {
+			l.Warn(fmt.Errorf("replication of %s@%s dropped: %w", tk.Repository, tk.Digest, err))
+			if err := t.queue.remove(ctx, tk.ID); err != nil {
+				l.Error(fmt.Errorf("remove replication task failed: %w", err))
+			}
+			continue
+		}
+
+		t.failed(now, err)
+
+		backoff := time.Duration(t.Backoff)
+		for range min(tk.Attempts, 16) {
+			backoff = min(backoff*2, maxBackoff)
+		}
+
+		tk.Attempts++
+		tk.LastError = err.Error()
+		tk.NextAttemptAt = now.Add(backoff)
+
+		if err := t.queue.put(ctx, tk); err != nil {
+			l.Error(fmt.Errorf("update replication task failed: %w", err))
+		}
+
+		l.WithValues(
+			slog.String("repository", tk.Repository),
+			slog.String("digest", string(tk.Digest)),
+			slog.Int("attempts", tk.Attempts),
+		).Warn(fmt.Errorf("replicate failed, retry in %s: %w", backoff, err))
+
+		wait = min(wait, backoff)
+	}
+
+	return wait
+}
+
+// replicate 复制清单及其引用的全部子清单与 Blob；标签已指向其他清单时仅复制内容
+func (t *target) replicate(ctx context.Context, tk *task) error {
+	named, err := reference.WithName(tk.Repository)
+	if err != nil {
+		return err
+	}
+
+	sourceRepo, err := t.source.Repository(ctx, named)
+	if err != nil {
+		return err
+	}
+
+	m, err := ociremote.Manifest(ctx, sourceRepo, tk.Digest.String())
+	if err != nil {
+		return err
+	}
+
+	tag := tk.Tag
+	if tag != "" {
+		tags, err := sourceRepo.Tags(ctx)
+		if err != nil {
+			return err
+		}
+		if current, err := tags.Get(ctx, tag); err != nil || current.Digest != tk.Digest {
+			tag = ""
+		}
+	}
+
+	targetNamed, err := reference.WithName(t.rewrite(tk.Repository))
+	if err != nil {
+		return err
+	}
+
+	targetRepo, err := t.remote.Repository(ctx, targetNamed)
+	if err != nil {
+		return err
+	}
+
+	return ociremote.Push(ctx, m, targetRepo, tag)
+}
+
+func (t *target) succeeded(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.lastSuccessAt = now
+}
+
+func (t *target) failed(now time.Time, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.lastErrorAt = now
+	t.lastError = err.Error()
+}
+
+func (t *target) status(ctx context.Context) (*Status, error) {
+	tasks, err := t.queue.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := &Status{
+		Target:        t.Name,
+		Pending:       len(tasks),
+		LastSuccessAt: t.lastSuccessAt,
+		LastErrorAt:   t.lastErrorAt,
+		LastError:     t.lastError,
+	}
+
+	for _, tk := range tasks {
+		if tk.Attempts > 0 {
+			s.Retrying++
+		}
+	}
+
+	if len(tasks) > 0 {
+		s.OldestPendingAt = tasks[0].CreatedAt
+	}
+
+	return s, nil
+}
+
+// hostResolver 所有仓库均解析到同一下游注册表
+type hostResolver struct {
+	host contentremote.RegistryHost
+}
+
+func (r *hostResolver) Resolve(ctx context.Context, named reference.Named) (reference.Named, *contentremote.RegistryHost, error) {
+	return named, &r.host, nil
+}
@@ -2,17 +2,33 @@ package registryhttp
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"strings"
 
 	infrahttp "github.com/innoai-tech/infra/pkg/http"
 
+	"github.com/octohelm/crkit/pkg/registryhttp/accesspolicy"
 	"github.com/octohelm/crkit/pkg/registryhttp/apis"
+	"github.com/octohelm/crkit/pkg/registryhttp/clientip"
+	"github.com/octohelm/crkit/pkg/registryhttp/notification"
+	"github.com/octohelm/crkit/pkg/registryhttp/ratelimit"
+	"github.com/octohelm/crkit/pkg/registryhttp/tokenauth"
 )
 
 // +gengo:injectable
 type Server struct {
 	infrahttp.Server
+
+	// 受信任的反向代理地址或 CIDR，逗号分隔；仅来自这些地址的请求采信 X-Forwarded-For / X-Real-Ip
+	TrustedProxies string `flag:",omitzero"`
+
+	// 内置 token 认证，声明 HtpasswdFile 时启用
+	Auth tokenauth.TokenAuth
+	// 请求限流，声明 RateLimitConfigFile 时启用
+	RateLimit ratelimit.RateLimit
+
+	access accesspolicy.AccessController `inject:",opt"`
 }
 
 func (s *Server) SetDefaults() {
@@ -38,5 +54,31 @@ func (s *Server) beforeInit(ctx context.Context) error {
 		})
 	})
 
+	// 签发的 token 仅包含访问策略允许的动作
+	if s.access != nil {
+		s.Auth.SetAuthorizer(s.access)
+	}
+
+	resolver, err := clientip.NewResolver(splitList(s.TrustedProxies))
+	if err != nil {
+		return fmt.Errorf("解析受信任的代理失败: %w", err)
+	}
+
+	// 限流在认证之前，未认证或认证失败的请求同样受限；
+	// Identify 仅识别 token 中的用户而不拒绝请求，以便限流按用户区分客户端
+	s.ApplyGlobalHandlers(func(h http.Handler) http.Handler {
+		return resolver.Handler(s.Auth.Identify(s.RateLimit.Handler(s.Auth.Handler(notification.RecordRequest(h)))))
+	})
+
 	return nil
 }
+
+func splitList(s string) []string {
+	list := make([]string, 0)
+	for item := range strings.SplitSeq(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,60 @@
+package tokenauth
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Htpasswd htpasswd 风格的用户凭证，仅支持 bcrypt 哈希
+type Htpasswd map[string][]byte
+
+// ParseHtpasswd 解析 htpasswd 内容，每行 `user:hash`，忽略空行和 `#` 注释
+func ParseHtpasswd(r io.Reader) (Htpasswd, error) {
+	users := Htpasswd{}
+
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		username, hash, ok := strings.Cut(line, ":")
+		if !ok || username == "" {
+			return nil, fmt.Errorf("invalid htpasswd entry at line %d", n)
+		}
+
+		if !isBcrypt(hash) {
+			return nil, fmt.Errorf("unsupported htpasswd hash of %s at line %d, only bcrypt supported", username, n)
+		}
+
+		users[username] = []byte(hash)
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (users Htpasswd) Authenticate(username string, password string) bool {
+	hash, ok := users[username]
+	if !ok {
+		return false
+	}
+	return bcrypt.CompareHashAndPassword(hash, []byte(password)) == nil
+}
+
+func isBcrypt(hash string) bool {
+	for _, prefix := range []string{"$2a$", "$2b$", "$2y$"} {
+		if strings.HasPrefix(hash, prefix) {
+			return true
+		}
+	}
+	return false
+}
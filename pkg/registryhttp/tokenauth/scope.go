@@ -0,0 +1,116 @@
+package tokenauth
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+const (
+	ActionPull   = "pull"
+	ActionPush   = "push"
+	ActionDelete = "delete"
+	ActionAdmin  = "admin"
+	ActionAll    = "*"
+)
+
+// ResourceActions token 中声明的资源访问范围，如 `repository:library/alpine:pull,push`
+type ResourceActions struct {
+	Type    string   `json:"type"`
+	Name    string   `json:"name"`
+	Actions []string `json:"actions"`
+}
+
+func (ra ResourceActions) String() string {
+	return ra.Type + ":" + ra.Name + ":" + strings.Join(ra.Actions, ",")
+}
+
+func (ra ResourceActions) Allows(action string) bool {
+	return slices.Contains(ra.Actions, action) || slices.Contains(ra.Actions, ActionAll)
+}
+
+// ParseScopes 解析 token 请求中的 scope 参数
+//
+// 仓库名可能包含 host:port，因此以首个和最后一个 `:` 切分
+func ParseScopes(scopes ...string) []ResourceActions {
+	list := make([]ResourceActions, 0, len(scopes))
+
+	for _, s := range scopes {
+		for scope := range strings.FieldsSeq(s) {
+			i := strings.Index(scope, ":")
+			j := strings.LastIndex(scope, ":")
+			if i < 0 || i == j {
+				continue
+			}
+
+			ra := ResourceActions{
+				Type: scope[0:i],
+				Name: scope[i+1 : j],
+			}
+
+			if ra.Type == "" || ra.Name == "" {
+				continue
+			}
+
+			if actions := scope[j+1:]; actions != "" {
+				ra.Actions = strings.Split(actions, ",")
+			}
+
+			list = append(list, ra)
+		}
+	}
+
+	return list
+}
+
+// requiredAccess 解析 /v2 请求所需的仓库访问范围
+//
+// `/v2`、`/v2/_catalog` 与管理接口仅要求认证通过，返回 nil；管理接口的权限由访问策略按 admin 动作校验
+func requiredAccess(req *http.Request) *ResourceActions {
+	name, ok := repositoryName(req.URL.Path)
+	if !ok {
+		return nil
+	}
+
+	ra := &ResourceActions{
+		Type: "repository",
+		Name: name,
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+		ra.Actions = []string{ActionPull}
+	case http.MethodDelete:
+		ra.Actions = []string{ActionDelete}
+	default:
+		ra.Actions = []string{ActionPush}
+	}
+
+	return ra
+}
+
+func repositoryName(p string) (string, bool) {
+	p, ok := strings.CutPrefix(p, "/v2/")
+	if !ok {
+		return "", false
+	}
+
+	end := -1
+
+	for _, v := range []string{
+		"/manifests/",
+		"/blobs/",
+		"/tags/",
+		"/referrers/",
+	} {
+		if i := strings.Index(p, v); i > 0 && (end < 0 || i < end) {
+			end = i
+		}
+	}
+
+	if end < 0 {
+		return "", false
+	}
+
+	return p[0:end], true
+}
@@ -0,0 +1,118 @@
+package tokenauth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Claims token 的 JWT 声明
+type Claims struct {
+	Issuer    string            `json:"iss"`
+	Subject   string            `json:"sub"`
+	Audience  string            `json:"aud"`
+	ExpiresAt int64             `json:"exp"`
+	NotBefore int64             `json:"nbf"`
+	IssuedAt  int64             `json:"iat"`
+	ID        string            `json:"jti"`
+	Access    []ResourceActions `json:"access"`
+}
+
+// Allows 判断是否具有资源的指定动作
+func (c *Claims) Allows(resource ResourceActions, action string) bool {
+	for _, ra := range c.Access {
+		if ra.Type == resource.Type && ra.Name == resource.Name && ra.Allows(action) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrTokenExpired = errors.New("token expired")
+)
+
+// leeway 校验时间类声明时允许的时钟偏差
+const leeway = time.Minute
+
+var jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// signer 以 HS256 签发和校验 JWT
+type signer struct {
+	key []byte
+}
+
+func (s *signer) Sign(c *Claims) (string, error) {
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(s.sum(signingInput)), nil
+}
+
+func (s *signer) Verify(token string, issuer string, audience string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != jwtHeader {
+		return nil, ErrInvalidToken
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if !hmac.Equal(sig, s.sum(parts[0]+"."+parts[1])) {
+		return nil, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	c := &Claims{}
+	if err := json.Unmarshal(payload, c); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if c.Issuer != issuer || c.Audience != audience {
+		return nil, ErrInvalidToken
+	}
+
+	now := time.Now()
+
+	if now.Add(leeway).Before(time.Unix(c.NotBefore, 0)) || now.Add(-leeway).After(time.Unix(c.ExpiresAt, 0)) {
+		return nil, ErrTokenExpired
+	}
+
+	return c, nil
+}
+
+func (s *signer) sum(signingInput string) []byte {
+	h := hmac.New(sha256.New, s.key)
+	h.Write([]byte(signingInput))
+	return h.Sum(nil)
+}
+
+type contextClaims struct{}
+
+// ClaimsFromContext 返回当前请求通过校验的 token 声明
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	if c, ok := ctx.Value(contextClaims{}).(*Claims); ok {
+		return c, true
+	}
+	return nil, false
+}
+
+func ClaimsInjectContext(ctx context.Context, c *Claims) context.Context {
+	return context.WithValue(ctx, contextClaims{}, c)
+}
@@ -0,0 +1,349 @@
+package tokenauth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+
+	"github.com/octohelm/crkit/pkg/content/remote/authn"
+	"github.com/octohelm/crkit/pkg/registryhttp/clientip"
+)
+
+// TokenPath token 服务的请求路径
+const TokenPath = "/auth/token"
+
+// AdminPath 管理接口的路径前缀，与 /v2 同样需要认证
+const AdminPath = "/api/crkit/admin"
+
+// TokenAuth 内置的 Docker/OCI token 认证服务
+//
+// 声明 HtpasswdFile 后启用：/v2 与 AdminPath 下的请求需携带由 TokenPath 签发的 Bearer token
+type TokenAuth struct {
+	// htpasswd 风格的用户凭证文件（bcrypt），声明时启用 token 认证
+	HtpasswdFile string `flag:",omitzero"`
+	// token 服务的外部访问地址，未声明时按请求推断
+	Realm string `flag:",omitzero"`
+	// 服务名，同时作为 token 的 iss 与 aud
+	Service string `flag:",omitzero"`
+	// token 签名密钥，未声明时启动时随机生成
+	Secret string `flag:",omitzero,secret"`
+	// token 有效期
+	ExpiresIn strfmt.Duration `flag:",omitzero"`
+
+	users      Htpasswd
+	signer     *signer
+	authorizer Authorizer
+}
+
+// Authorizer 签发 token 时校验用户对仓库可执行的动作，ctx 中携带用户的 claims
+type Authorizer interface {
+	Authorize(ctx context.Context, name string, action string) error
+}
+
+// SetAuthorizer 签发的 token 仅包含 authorizer 允许的动作，未设置时包含请求的全部动作
+func (a *TokenAuth) SetAuthorizer(authorizer Authorizer) {
+	a.authorizer = authorizer
+}
+
+func (a *TokenAuth) SetDefaults() {
+	if a.Service == "" {
+		a.Service = "crkit"
+	}
+
+	if a.ExpiresIn == 0 {
+		a.ExpiresIn = strfmt.Duration(5 * time.Minute)
+	}
+}
+
+func (a *TokenAuth) Init(ctx context.Context) error {
+	if a.HtpasswdFile == "" {
+		return nil
+	}
+
+	f, err := os.Open(a.HtpasswdFile)
+	if err != nil {
+		return fmt.Errorf("读取用户凭证文件失败: %w", err)
+	}
+	defer f.Close()
+
+	users, err := ParseHtpasswd(f)
+	if err != nil {
+		return fmt.Errorf("解析用户凭证文件失败: %w", err)
+	}
+
+	key := []byte(a.Secret)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return err
+		}
+	}
+
+	a.users = users
+	a.signer = &signer{key: key}
+
+	return nil
+}
+
+func (a *TokenAuth) Enabled() bool {
+	return a.signer != nil
+}
+
+// Handler 为 /v2 与 AdminPath 下的请求校验 Bearer token，并在 TokenPath 上签发 token
+func (a *TokenAuth) Handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if !a.Enabled() {
+			h.ServeHTTP(rw, req)
+			return
+		}
+
+		if req.URL.Path == TokenPath {
+			a.serveToken(rw, req)
+			return
+		}
+
+		if !protected(req.URL.Path) {
+			h.ServeHTTP(rw, req)
+			return
+		}
+
+		required := requiredAccess(req)
+
+		claims, ok := ClaimsFromContext(req.Context())
+		if !ok {
+			tok, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+			if !ok {
+				a.challenge(rw, req, required, "", "UNAUTHORIZED", "authentication required")
+				return
+			}
+
+			c, err := a.signer.Verify(tok, a.Service, a.Service)
+			if err != nil {
+				a.challenge(rw, req, required, "invalid_token", "UNAUTHORIZED", err.Error())
+				return
+			}
+			claims = c
+		}
+
+		if required != nil && !claims.Allows(*required, required.Actions[0]) {
+			a.challenge(rw, req, required, "insufficient_scope", "DENIED", fmt.Sprintf("requested access to %s is denied", required))
+			return
+		}
+
+		if req.Method == http.MethodPost {
+			// 跨仓库挂载时缺少源仓库的 pull 权限，则退化为普通上传
+			if q := req.URL.Query(); q.Has("from") {
+				if !claims.Allows(ResourceActions{Type: "repository", Name: q.Get("from")}, ActionPull) {
+					q.Del("mount")
+					q.Del("from")
+					req.URL.RawQuery = q.Encode()
+					req.RequestURI = req.URL.RequestURI()
+				}
+			}
+		}
+
+		h.ServeHTTP(rw, req.WithContext(ClaimsInjectContext(req.Context(), claims)))
+	})
+}
+
+// Identify 识别请求携带的有效 Bearer token 并注入 claims，不拒绝任何请求
+//
+// 用于在 Handler 之前按用户区分客户端（如限流），校验与拒绝仍由 Handler 完成
+func (a *TokenAuth) Identify(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if !a.Enabled() {
+			h.ServeHTTP(rw, req)
+			return
+		}
+
+		if tok, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer "); ok {
+			if claims, err := a.signer.Verify(tok, a.Service, a.Service); err == nil {
+				req = req.WithContext(ClaimsInjectContext(req.Context(), claims))
+			}
+		}
+
+		h.ServeHTTP(rw, req)
+	})
+}
+
+func protected(p string) bool {
+	for _, prefix := range []string{"/v2", AdminPath} {
+		if p == prefix || strings.HasPrefix(p, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *TokenAuth) serveToken(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		writeErrors(rw, http.StatusMethodNotAllowed, "UNSUPPORTED", "method not allowed")
+		return
+	}
+
+	q := req.URL.Query()
+
+	if service := q.Get("service"); service != "" && service != a.Service {
+		writeErrors(rw, http.StatusBadRequest, "UNSUPPORTED", fmt.Sprintf("unknown service %q", service))
+		return
+	}
+
+	username, password, ok := req.BasicAuth()
+	if !ok || !a.users.Authenticate(username, password) {
+		rw.Header().Set("WWW-Authenticate", fmt.Sprintf("Basic realm=%q", a.Service))
+		writeErrors(rw, http.StatusUnauthorized, "UNAUTHORIZED", "invalid username or password")
+		return
+	}
+
+	now := time.Now()
+	expiresIn := time.Duration(a.ExpiresIn)
+
+	claims := &Claims{
+		Issuer:    a.Service,
+		Subject:   username,
+		Audience:  a.Service,
+		IssuedAt:  now.Unix(),
+		NotBefore: now.Unix(),
+		ExpiresAt: now.Add(expiresIn).Unix(),
+		ID:        rand.Text(),
+	}
+	claims.Access = a.grant(ClaimsInjectContext(req.Context(), claims), ParseScopes(q["scope"]...))
+
+	tok, err := a.signer.Sign(claims)
+	if err != nil {
+		writeErrors(rw, http.StatusInternalServerError, "UNKNOWN", err.Error())
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+
+	_ = json.NewEncoder(rw).Encode(&tokenResponse{
+		Token:       tok,
+		AccessToken: tok,
+		ExpiresIn:   int(expiresIn / time.Second),
+		IssuedAt:    now.UTC().Format(time.RFC3339),
+	})
+}
+
+type tokenResponse struct {
+	Token       string `json:"token"`
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+	IssuedAt    string `json:"issued_at"`
+}
+
+// grant 计算认证用户可获得的访问范围
+//
+// 仅授予请求中且 authorizer 允许的动作；客户端权限不足时由 Handler 的
+// insufficient_scope 挑战按所需的 scope 重新申请
+func (a *TokenAuth) grant(ctx context.Context, requested []ResourceActions) []ResourceActions {
+	granted := make([]ResourceActions, 0, len(requested))
+
+	for _, ra := range requested {
+		switch ra.Type {
+		case "repository":
+			actions := make([]string, 0, len(ra.Actions))
+			for _, action := range ra.Actions {
+				if a.authorizer != nil && a.authorizer.Authorize(ctx, ra.Name, action) != nil {
+					continue
+				}
+				actions = append(actions, action)
+			}
+
+			if len(actions) == 0 {
+				continue
+			}
+
+			granted = append(granted, ResourceActions{
+				Type:    ra.Type,
+				Name:    ra.Name,
+				Actions: actions,
+			})
+		case "registry":
+			if ra.Name == "catalog" {
+				granted = append(granted, ResourceActions{
+					Type:    ra.Type,
+					Name:    ra.Name,
+					Actions: []string{ActionAll},
+				})
+			}
+		}
+	}
+
+	return granted
+}
+
+func (a *TokenAuth) challenge(rw http.ResponseWriter, req *http.Request, required *ResourceActions, errCode string, code string, message string) {
+	wwwAuth := authn.WwwAuthenticate{
+		AuthType: "Bearer",
+		Params: map[string]string{
+			"realm":   a.realm(req),
+			"service": a.Service,
+		},
+	}
+
+	if required != nil {
+		wwwAuth.Params["scope"] = required.String()
+	}
+
+	if errCode != "" {
+		wwwAuth.Params["error"] = errCode
+	}
+
+	rw.Header().Set("WWW-Authenticate", wwwAuth.String())
+	writeErrors(rw, http.StatusUnauthorized, code, message)
+}
+
+func (a *TokenAuth) realm(req *http.Request) string {
+	if a.Realm != "" {
+		return a.Realm
+	}
+
+	u := &url.URL{
+		Scheme: "http",
+		Host:   req.Host,
+		Path:   TokenPath,
+	}
+
+	if req.TLS != nil {
+		u.Scheme = "https"
+	}
+
+	// 仅采信受信任的代理转发的请求头，避免客户端伪造 token 服务的地址
+	if !clientip.FromTrustedProxy(req) {
+		return u.String()
+	}
+
+	if proto := req.Header.Get("X-Forwarded-Proto"); proto != "" {
+		u.Scheme = proto
+	}
+
+	if host := req.Header.Get("X-Forwarded-Host"); host != "" {
+		u.Host = host
+	}
+
+	return u.String()
+}
+
+func writeErrors(rw http.ResponseWriter, statusCode int, code string, message string) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(statusCode)
+
+	_ = json.NewEncoder(rw).Encode(map[string]any{
+		"errors": []map[string]string{
+			{
+				"code":    code,
+				"message": message,
+			},
+		},
+	})
+}
@@ -0,0 +1,264 @@
+package tokenauth_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/distribution/reference"
+	"golang.org/x/crypto/bcrypt"
+
+	. "github.com/octohelm/x/testing/v2"
+
+	contentremote "github.com/octohelm/crkit/pkg/content/remote"
+	"github.com/octohelm/crkit/pkg/content/remote/authn"
+	"github.com/octohelm/crkit/pkg/registryhttp/clientip"
+	"github.com/octohelm/crkit/pkg/registryhttp/tokenauth"
+)
+
+func TestTokenAuth(t *testing.T) {
+	ctx := context.Background()
+
+	htpasswdFile := filepath.Join(t.TempDir(), "htpasswd")
+
+	hash := MustValue(t, func() ([]byte, error) {
+		return bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	})
+
+	Must(t, func() error {
+		return os.WriteFile(htpasswdFile, fmt.Appendf(nil, "# users\nadmin:%s\n", hash), 0o600)
+	})
+
+	a := &tokenauth.TokenAuth{
+		HtpasswdFile: htpasswdFile,
+	}
+	a.SetDefaults()
+
+	Must(t, func() error {
+		return a.Init(ctx)
+	})
+
+	s := httptest.NewServer(a.Handler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		claims, _ := tokenauth.ClaimsFromContext(req.Context())
+
+		rw.Header().Set("Content-Type", "application/json")
+		rw.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(rw).Encode(map[string]any{
+			"name": "test/app",
+			"tags": []string{claims.Subject},
+		})
+	})))
+	t.Cleanup(s.Close)
+
+	named := MustValue(t, func() (reference.Named, error) {
+		return reference.WithName("test/app")
+	})
+
+	t.Run("未携带 token 时返回 Bearer 挑战", func(t *testing.T) {
+		resp := MustValue(t, func() (*http.Response, error) {
+			return http.Get(s.URL + "/v2/test/app/tags/list")
+		})
+		_ = resp.Body.Close()
+
+		wwwAuth := MustValue(t, func() (*authn.WwwAuthenticate, error) {
+			return authn.ParseWwwAuthenticate(resp.Header.Get("WWW-Authenticate"))
+		})
+
+		Then(t, "挑战应声明 realm、service 和 scope",
+			Expect(resp.StatusCode, Equal(http.StatusUnauthorized)),
+			Expect(wwwAuth.AuthType, Equal("Bearer")),
+			Expect(wwwAuth.Params["realm"], Equal(s.URL+tokenauth.TokenPath)),
+			Expect(wwwAuth.Params["service"], Equal("crkit")),
+			Expect(wwwAuth.Params["scope"], Equal("repository:test/app:pull")),
+		)
+	})
+
+	t.Run("authn 客户端可直接获取 token 并访问", func(t *testing.T) {
+		tags := MustValue(t, func() ([]string, error) {
+			n, err := contentremote.New(ctx, contentremote.Registry{
+				Endpoint: s.URL,
+				Username: "admin",
+				Password: "secret",
+			})
+			if err != nil {
+				return nil, err
+			}
+			repo, err := n.Repository(ctx, named)
+			if err != nil {
+				return nil, err
+			}
+			ts, err := repo.Tags(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return ts.All(ctx)
+		})
+
+		Then(t, "请求应以认证用户身份通过",
+			Expect(tags, Equal([]string{"admin"})),
+		)
+	})
+
+	t.Run("密码错误时无法获取 token", func(t *testing.T) {
+		req := MustValue(t, func() (*http.Request, error) {
+			return http.NewRequest(http.MethodGet, s.URL+tokenauth.TokenPath+"?service=crkit&scope=repository:test/app:pull", nil)
+		})
+		req.SetBasicAuth("admin", "wrong")
+
+		resp := MustValue(t, func() (*http.Response, error) {
+			return http.DefaultClient.Do(req)
+		})
+		_ = resp.Body.Close()
+
+		Then(t, "应返回 401",
+			Expect(resp.StatusCode, Equal(http.StatusUnauthorized)),
+		)
+	})
+
+	t.Run("伪造的 token 被拒绝", func(t *testing.T) {
+		req := MustValue(t, func() (*http.Request, error) {
+			return http.NewRequest(http.MethodGet, s.URL+"/v2/test/app/tags/list", nil)
+		})
+		req.Header.Set("Authorization", "Bearer e30.e30.e30")
+
+		resp := MustValue(t, func() (*http.Response, error) {
+			return http.DefaultClient.Do(req)
+		})
+		_ = resp.Body.Close()
+
+		Then(t, "应返回 401",
+			Expect(resp.StatusCode, Equal(http.StatusUnauthorized)),
+		)
+	})
+	requestToken := func(t *testing.T, tokenURL string, scope string) string {
+		req := MustValue(t, func() (*http.Request, error) {
+			return http.NewRequest(http.MethodGet, tokenURL+"?service=crkit&scope="+url.QueryEscape(scope), nil)
+		})
+		req.SetBasicAuth("admin", "secret")
+
+		return MustValue(t, func() (string, error) {
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				return "", err
+			}
+			defer resp.Body.Close()
+
+			tok := &struct {
+				Token string `json:"token"`
+			}{}
+			if err := json.NewDecoder(resp.Body).Decode(tok); err != nil {
+				return "", err
+			}
+			return tok.Token, nil
+		})
+	}
+
+	deleteManifest := func(t *testing.T, serverURL string, tok string) *authn.WwwAuthenticate {
+		req := MustValue(t, func() (*http.Request, error) {
+			return http.NewRequest(http.MethodDelete, serverURL+"/v2/test/app/manifests/latest", nil)
+		})
+		req.Header.Set("Authorization", "Bearer "+tok)
+
+		resp := MustValue(t, func() (*http.Response, error) {
+			return http.DefaultClient.Do(req)
+		})
+		_ = resp.Body.Close()
+
+		if resp.StatusCode != http.StatusUnauthorized {
+			return &authn.WwwAuthenticate{}
+		}
+
+		return MustValue(t, func() (*authn.WwwAuthenticate, error) {
+			return authn.ParseWwwAuthenticate(resp.Header.Get("WWW-Authenticate"))
+		})
+	}
+
+	t.Run("token 仅包含请求的动作", func(t *testing.T) {
+		wwwAuth := deleteManifest(t, s.URL, requestToken(t, s.URL+tokenauth.TokenPath, "repository:test/app:pull"))
+
+		Then(t, "按 pull 申请的 token 不可删除，挑战声明所需的 scope",
+			Expect(wwwAuth.Params["error"], Equal("insufficient_scope")),
+			Expect(wwwAuth.Params["scope"], Equal("repository:test/app:delete")),
+		)
+	})
+
+	t.Run("token 仅包含 authorizer 允许的动作", func(t *testing.T) {
+		limited := &tokenauth.TokenAuth{HtpasswdFile: htpasswdFile}
+		limited.SetDefaults()
+		limited.SetAuthorizer(pullOnly{})
+
+		Must(t, func() error {
+			return limited.Init(ctx)
+		})
+
+		ls := httptest.NewServer(limited.Handler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rw.WriteHeader(http.StatusAccepted)
+		})))
+		t.Cleanup(ls.Close)
+
+		wwwAuth := deleteManifest(t, ls.URL, requestToken(t, ls.URL+tokenauth.TokenPath, "repository:test/app:pull,delete"))
+
+		Then(t, "未被允许的 delete 不会签发",
+			Expect(wwwAuth.Params["error"], Equal("insufficient_scope")),
+		)
+	})
+
+	t.Run("仅采信受信任的代理转发的 X-Forwarded-Host", func(t *testing.T) {
+		realm := func(t *testing.T, serverURL string) string {
+			req := MustValue(t, func() (*http.Request, error) {
+				return http.NewRequest(http.MethodGet, serverURL+"/v2/test/app/tags/list", nil)
+			})
+			req.Header.Set("X-Forwarded-Proto", "https")
+			req.Header.Set("X-Forwarded-Host", "registry.example.com")
+
+			resp := MustValue(t, func() (*http.Response, error) {
+				return http.DefaultClient.Do(req)
+			})
+			_ = resp.Body.Close()
+
+			wwwAuth := MustValue(t, func() (*authn.WwwAuthenticate, error) {
+				return authn.ParseWwwAuthenticate(resp.Header.Get("WWW-Authenticate"))
+			})
+			return wwwAuth.Params["realm"]
+		}
+
+		resolver := MustValue(t, func() (*clientip.Resolver, error) {
+			return clientip.NewResolver([]string{"127.0.0.1", "::1"})
+		})
+
+		ts := httptest.NewServer(resolver.Handler(a.Handler(http.NotFoundHandler())))
+		t.Cleanup(ts.Close)
+
+		Then(t, "不受信任的对端忽略转发头，受信任的代理按转发头推断",
+			Expect(realm(t, s.URL), Equal(s.URL+tokenauth.TokenPath)),
+			Expect(realm(t, ts.URL), Equal("https://registry.example.com"+tokenauth.TokenPath)),
+		)
+	})
+}
+
+// pullOnly 仅允许 pull
+type pullOnly struct{}
+
+func (pullOnly) Authorize(ctx context.Context, name string, action string) error {
+	if action != tokenauth.ActionPull {
+		return fmt.Errorf("%s of %s is denied", action, name)
+	}
+	return nil
+}
+
+func TestParseScopes(t *testing.T) {
+	scopes := tokenauth.ParseScopes("repository:localhost:5000/app:pull,push registry:catalog:*", "repository::")
+
+	Then(t, "仓库名可包含端口，无效 scope 被忽略",
+		Expect(scopes, Equal([]tokenauth.ResourceActions{
+			{Type: "repository", Name: "localhost:5000/app", Actions: []string{"pull", "push"}},
+			{Type: "registry", Name: "catalog", Actions: []string{"*"}},
+		})),
+	)
+}
@@ -3,15 +3,26 @@ package registryhttp
 
 import (
 	context "context"
+
+	accesspolicy "github.com/octohelm/crkit/pkg/registryhttp/accesspolicy"
 )
 
 func (v *Server) Init(ctx context.Context) error {
+	if value, ok := accesspolicy.AccessControllerFromContext(ctx); ok {
+		v.access = value
+	}
 	if err := v.beforeInit(ctx); err != nil {
 		return err
 	}
 	if err := v.Server.Init(ctx); err != nil {
 		return err
 	}
+	if err := v.Auth.Init(ctx); err != nil {
+		return err
+	}
+	if err := v.RateLimit.Init(ctx); err != nil {
+		return err
+	}
 
 	return nil
 }